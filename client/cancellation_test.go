@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// cancellationServer is a server with a tool that blocks until it is cancelled
+type cancellationServer struct {
+	*server.MCPServer
+	requestIDs chan any
+	causes     chan error
+}
+
+func newCancellationServer() *cancellationServer {
+	s := &cancellationServer{
+		requestIDs: make(chan any, 1),
+		causes:     make(chan error, 1),
+	}
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
+		s.requestIDs <- id
+	})
+	s.MCPServer = server.NewMCPServer("test-server", "1.0.0", server.WithHooks(hooks))
+	s.AddTool(mcp.NewTool("block"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case <-ctx.Done():
+			s.causes <- context.Cause(ctx)
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			s.causes <- nil
+			return mcp.NewToolResultText("done"), nil
+		}
+	})
+	return s
+}
+
+func testClientCancellation(t *testing.T, client *Client, s *cancellationServer) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, client.Start(ctx))
+	_, err := client.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+
+	callCtx, cancelCall := context.WithTimeout(ctx, 2*time.Second)
+	defer cancelCall()
+	go func() {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "block"
+		_, _ = client.CallTool(callCtx, request)
+	}()
+
+	var requestID any
+	select {
+	case requestID = <-s.requestIDs:
+	case <-ctx.Done():
+		t.Fatal("tool was not called")
+	}
+
+	err = client.GetTransport().SendNotification(ctx, mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationCancelled,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"requestId": requestID,
+					"reason":    "no longer needed",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	select {
+	case cause := <-s.causes:
+		assert.ErrorIs(t, cause, server.ErrRequestCancelled)
+		assert.ErrorContains(t, cause, "no longer needed")
+	case <-callCtx.Done():
+		t.Fatal("tool was not cancelled")
+	}
+}
+
+func TestClientCancellation(t *testing.T) {
+	t.Run("stdio", func(t *testing.T) {
+		serverReader, clientWriter := io.Pipe()
+		clientReader, serverWriter := io.Pipe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		s := newCancellationServer()
+		go func() {
+			_ = server.NewStdioServer(s.MCPServer).Listen(ctx, serverReader, serverWriter)
+		}()
+
+		client := NewClient(transport.NewIO(clientReader, clientWriter, io.NopCloser(strings.NewReader(""))))
+		defer client.Close()
+
+		testClientCancellation(t, client, s)
+	})
+
+	t.Run("sse", func(t *testing.T) {
+		s := newCancellationServer()
+		testServer := server.NewTestServer(s.MCPServer)
+		defer testServer.Close()
+
+		trans, err := transport.NewSSE(testServer.URL + "/sse")
+		require.NoError(t, err)
+		client := NewClient(trans)
+		defer client.Close()
+
+		testClientCancellation(t, client, s)
+	})
+
+	t.Run("streamable http", func(t *testing.T) {
+		s := newCancellationServer()
+		testServer := server.NewTestStreamableHTTPServer(s.MCPServer)
+		defer testServer.Close()
+
+		trans, err := transport.NewStreamableHTTP(testServer.URL)
+		require.NoError(t, err)
+		client := NewClient(trans)
+		defer client.Close()
+
+		testClientCancellation(t, client, s)
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/tracing"
 )
 
 // Client implements the MCP client.
@@ -22,6 +23,10 @@ type Client struct {
 	requestID          atomic.Int64
 	clientCapabilities mcp.ClientCapabilities
 	serverCapabilities mcp.ServerCapabilities
+	samplingHandler    SamplingHandlerFunc
+	rootsHandler       RootsHandlerFunc
+	elicitationHandler ElicitationHandlerFunc
+	tracer             tracing.Tracer
 }
 
 type ClientOption func(*Client)
@@ -50,9 +55,64 @@ func NewClient(transport transport.Interface, options ...ClientOption) *Client {
 		opt(client)
 	}
 
+	client.setRequestHandler()
 	return client
 }
 
+// setRequestHandler lets the transport route requests sent by the server to
+// the client, if the transport supports them.
+func (c *Client) setRequestHandler() {
+	if t, ok := c.transport.(transport.BidirectionalInterface); ok {
+		t.SetRequestHandler(c.handleServerRequest)
+	}
+}
+
+// handleServerRequest handles a request sent by the server to the client.
+func (c *Client) handleServerRequest(
+	ctx context.Context,
+	request transport.JSONRPCRequest,
+) (*transport.JSONRPCResponse, error) {
+	var result any
+	var err error
+	switch mcp.MCPMethod(request.Method) {
+	case mcp.MethodSamplingCreateMessage:
+		if c.samplingHandler == nil {
+			break
+		}
+		result, err = c.handleSamplingRequest(ctx, request)
+	case mcp.MethodListRoots:
+		if c.rootsHandler == nil {
+			break
+		}
+		result, err = c.handleListRootsRequest(ctx)
+	case mcp.MethodElicitationCreate:
+		if c.elicitationHandler == nil {
+			break
+		}
+		result, err = c.handleElicitationRequest(ctx, request)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return transport.NewJSONRPCErrorResponse(
+			request.ID,
+			mcp.METHOD_NOT_FOUND,
+			fmt.Sprintf("Method %s not found", request.Method),
+		), nil
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &transport.JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      request.ID,
+		Result:  resultBytes,
+	}, nil
+}
+
 // Start initiates the connection to the server.
 // Must be called before using the client.
 func (c *Client) Start(ctx context.Context) error {
@@ -102,6 +162,14 @@ func (c *Client) sendRequest(
 
 	id := c.requestID.Add(1)
 
+	ctx, span := c.startSpan(ctx, method)
+	defer span.End()
+	params, err := injectTraceContext(ctx, params)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	request := transport.JSONRPCRequest{
 		JSONRPC: mcp.JSONRPC_VERSION,
 		ID:      mcp.NewRequestId(id),
@@ -111,11 +179,14 @@ func (c *Client) sendRequest(
 
 	response, err := c.transport.SendRequest(ctx, request)
 	if err != nil {
+		span.RecordError(err)
 		return nil, fmt.Errorf("transport error: %w", err)
 	}
 
 	if response.Error != nil {
-		return nil, errors.New(response.Error.Message)
+		err := errors.New(response.Error.Message)
+		span.RecordError(err)
+		return nil, err
 	}
 
 	return &response.Result, nil
@@ -127,6 +198,19 @@ func (c *Client) Initialize(
 	ctx context.Context,
 	request mcp.InitializeRequest,
 ) (*mcp.InitializeResult, error) {
+	capabilities := request.Params.Capabilities // Will be empty struct if not set
+	if c.samplingHandler != nil && capabilities.Sampling == nil {
+		capabilities.Sampling = &struct{}{}
+	}
+	if c.rootsHandler != nil && capabilities.Roots == nil {
+		capabilities.Roots = &struct {
+			ListChanged bool `json:"listChanged,omitempty"`
+		}{ListChanged: true}
+	}
+	if c.elicitationHandler != nil && capabilities.Elicitation == nil {
+		capabilities.Elicitation = &struct{}{}
+	}
+
 	// Ensure we send a params object with all required fields
 	params := struct {
 		ProtocolVersion string                 `json:"protocolVersion"`
@@ -135,7 +219,7 @@ func (c *Client) Initialize(
 	}{
 		ProtocolVersion: request.Params.ProtocolVersion,
 		ClientInfo:      request.Params.ClientInfo,
-		Capabilities:    request.Params.Capabilities, // Will be empty struct if not set
+		Capabilities:    capabilities,
 	}
 
 	response, err := c.sendRequest(ctx, "initialize", params)
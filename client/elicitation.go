@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ElicitationHandlerFunc handles elicitation/create requests sent by the server.
+// It should present the message and a form for the requested schema to the user,
+// and return the action they took, with the submitted content if they accepted.
+type ElicitationHandlerFunc func(ctx context.Context, request mcp.ElicitRequest) (*mcp.ElicitResult, error)
+
+// WithElicitationHandler sets the handler for elicitation requests sent by the server.
+// Setting a handler advertises the elicitation capability during initialization.
+// It requires a transport that supports server requests (stdio, SSE or streamable HTTP).
+func WithElicitationHandler(handler ElicitationHandlerFunc) ClientOption {
+	return func(c *Client) {
+		c.elicitationHandler = handler
+	}
+}
+
+// handleElicitationRequest parses an elicitation/create request and passes it to the elicitation handler.
+func (c *Client) handleElicitationRequest(ctx context.Context, request transport.JSONRPCRequest) (any, error) {
+	paramsBytes, err := json.Marshal(request.Params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	var params mcp.ElicitationParams
+	if err := json.Unmarshal(paramsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal params: %w", err)
+	}
+
+	result, err := c.elicitationHandler(ctx, mcp.ElicitRequest{
+		Request: mcp.Request{
+			Method: request.Method,
+		},
+		Params: params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("elicitation handler returned no result")
+	}
+	return result, nil
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newElicitationServer creates a server with a tool that asks the user for their name
+func newElicitationServer() *server.MCPServer {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("greet"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := server.ServerFromContext(ctx).RequestElicitation(ctx, mcp.ElicitRequest{
+			Params: mcp.ElicitationParams{
+				Message: "What is your name?",
+				RequestedSchema: mcp.NewElicitationSchema(map[string]mcp.PrimitiveSchema{
+					"name": {Type: "string", Title: "Name"},
+				}, "name"),
+			},
+		})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("elicitation failed", err), nil
+		}
+		if result.Action != mcp.ElicitationResponseActionAccept {
+			return mcp.NewToolResultText("no name: " + string(result.Action)), nil
+		}
+		var content struct {
+			Name string `json:"name"`
+		}
+		if err := result.BindContent(&content); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("hello " + content.Name), nil
+	})
+	return mcpServer
+}
+
+func elicitationHandler(ctx context.Context, request mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+	if request.Params.Message != "What is your name?" {
+		return nil, errors.New("unexpected message")
+	}
+	if request.Params.RequestedSchema.Properties["name"].Title != "Name" {
+		return nil, errors.New("unexpected schema")
+	}
+	return &mcp.ElicitResult{
+		Action:  mcp.ElicitationResponseActionAccept,
+		Content: map[string]any{"name": "Ann"},
+	}, nil
+}
+
+func callElicitationTool(t *testing.T, client *Client) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, client.Start(ctx))
+	_, err := client.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "greet"
+	result, err := client.CallTool(ctx, request)
+	require.NoError(t, err)
+	text, _ := mcp.AsTextContent(result.Content[0])
+	require.False(t, result.IsError, text.Text)
+	return text.Text
+}
+
+func TestClientElicitation(t *testing.T) {
+	t.Run("stdio", func(t *testing.T) {
+		serverReader, clientWriter := io.Pipe()
+		clientReader, serverWriter := io.Pipe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = server.NewStdioServer(newElicitationServer()).Listen(ctx, serverReader, serverWriter)
+		}()
+
+		client := NewClient(
+			transport.NewIO(clientReader, clientWriter, io.NopCloser(strings.NewReader(""))),
+			WithElicitationHandler(elicitationHandler),
+		)
+		defer client.Close()
+
+		assert.Equal(t, "hello Ann", callElicitationTool(t, client))
+	})
+
+	t.Run("streamable http", func(t *testing.T) {
+		testServer := server.NewTestStreamableHTTPServer(newElicitationServer())
+		defer testServer.Close()
+
+		trans, err := transport.NewStreamableHTTP(testServer.URL)
+		require.NoError(t, err)
+		client := NewClient(trans, WithElicitationHandler(elicitationHandler))
+		defer client.Close()
+
+		assert.Equal(t, "hello Ann", callElicitationTool(t, client))
+	})
+
+	t.Run("user declines", func(t *testing.T) {
+		testServer := server.NewTestServer(newElicitationServer())
+		defer testServer.Close()
+
+		trans, err := transport.NewSSE(testServer.URL + "/sse")
+		require.NoError(t, err)
+		client := NewClient(trans, WithElicitationHandler(
+			func(ctx context.Context, request mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+				return &mcp.ElicitResult{Action: mcp.ElicitationResponseActionDecline}, nil
+			},
+		))
+		defer client.Close()
+
+		assert.Equal(t, "no name: decline", callElicitationTool(t, client))
+	})
+
+	t.Run("client without elicitation handler", func(t *testing.T) {
+		testServer := server.NewTestServer(newElicitationServer())
+		defer testServer.Close()
+
+		trans, err := transport.NewSSE(testServer.URL + "/sse")
+		require.NoError(t, err)
+		client := NewClient(trans)
+		defer client.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, client.Start(ctx))
+		_, err = client.Initialize(ctx, mcp.InitializeRequest{})
+		require.NoError(t, err)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "greet"
+		result, err := client.CallTool(ctx, request)
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		text, _ := mcp.AsTextContent(result.Content[0])
+		assert.Contains(t, text.Text, server.ErrElicitationNotSupported.Error())
+	})
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RootsHandlerFunc returns the roots the server can operate on.
+// It is called when the server sends a roots/list request.
+type RootsHandlerFunc func(ctx context.Context) ([]mcp.Root, error)
+
+// WithRootsHandler sets the handler for roots/list requests sent by the server.
+// Setting a handler advertises the roots capability during initialization.
+// Call NotifyRootsListChanged when the roots change.
+func WithRootsHandler(handler RootsHandlerFunc) ClientOption {
+	return func(c *Client) {
+		c.rootsHandler = handler
+	}
+}
+
+// NotifyRootsListChanged notifies the server that the roots of the client have
+// changed, so that the server requests them again.
+func (c *Client) NotifyRootsListChanged(ctx context.Context) error {
+	if !c.initialized {
+		return fmt.Errorf("client not initialized")
+	}
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationRootsListChanged,
+		},
+	}
+	return c.transport.SendNotification(ctx, notification)
+}
+
+// handleListRootsRequest answers a roots/list request with the roots from the roots handler.
+func (c *Client) handleListRootsRequest(ctx context.Context) (any, error) {
+	roots, err := c.rootsHandler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if roots == nil {
+		roots = []mcp.Root{}
+	}
+	return &mcp.ListRootsResult{Roots: roots}, nil
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newRootsServer creates a server with a tool that lists the roots of the client
+func newRootsServer() *server.MCPServer {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("list-roots"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		roots, err := server.RootsFromContext(ctx)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("listing roots failed", err), nil
+		}
+		uris := make([]string, 0, len(roots))
+		for _, root := range roots {
+			uris = append(uris, root.URI)
+		}
+		return mcp.NewToolResultText(strings.Join(uris, ",")), nil
+	})
+	return mcpServer
+}
+
+// testRoots is a roots handler whose roots can be changed by the test
+type testRoots struct {
+	mu    sync.Mutex
+	roots []mcp.Root
+	calls int
+}
+
+func (r *testRoots) set(uris ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roots = nil
+	for _, uri := range uris {
+		r.roots = append(r.roots, mcp.Root{URI: uri})
+	}
+}
+
+func (r *testRoots) handler(ctx context.Context) ([]mcp.Root, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	return r.roots, nil
+}
+
+func testClientRoots(t *testing.T, client *Client, roots *testRoots) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, client.Start(ctx))
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	_, err := client.Initialize(ctx, initRequest)
+	require.NoError(t, err)
+
+	listRoots := func() string {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "list-roots"
+		result, err := client.CallTool(ctx, request)
+		require.NoError(t, err)
+		text, _ := mcp.AsTextContent(result.Content[0])
+		require.False(t, result.IsError, text.Text)
+		return text.Text
+	}
+
+	assert.Equal(t, "file:///a", listRoots())
+	assert.Equal(t, "file:///a", listRoots())
+	roots.mu.Lock()
+	assert.Equal(t, 1, roots.calls, "roots should be cached by the server")
+	roots.mu.Unlock()
+
+	roots.set("file:///a", "file:///b")
+	require.NoError(t, client.NotifyRootsListChanged(ctx))
+	assert.Eventually(t, func() bool {
+		return listRoots() == "file:///a,file:///b"
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestClientRoots(t *testing.T) {
+	t.Run("stdio", func(t *testing.T) {
+		serverReader, clientWriter := io.Pipe()
+		clientReader, serverWriter := io.Pipe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = server.NewStdioServer(newRootsServer()).Listen(ctx, serverReader, serverWriter)
+		}()
+
+		roots := &testRoots{}
+		roots.set("file:///a")
+		client := NewClient(
+			transport.NewIO(clientReader, clientWriter, io.NopCloser(strings.NewReader(""))),
+			WithRootsHandler(roots.handler),
+		)
+		defer client.Close()
+
+		testClientRoots(t, client, roots)
+	})
+
+	t.Run("sse", func(t *testing.T) {
+		testServer := server.NewTestServer(newRootsServer())
+		defer testServer.Close()
+
+		roots := &testRoots{}
+		roots.set("file:///a")
+		trans, err := transport.NewSSE(testServer.URL + "/sse")
+		require.NoError(t, err)
+		client := NewClient(trans, WithRootsHandler(roots.handler))
+		defer client.Close()
+
+		testClientRoots(t, client, roots)
+	})
+
+	t.Run("streamable http", func(t *testing.T) {
+		testServer := server.NewTestStreamableHTTPServer(newRootsServer())
+		defer testServer.Close()
+
+		roots := &testRoots{}
+		roots.set("file:///a")
+		trans, err := transport.NewStreamableHTTP(testServer.URL)
+		require.NoError(t, err)
+		client := NewClient(trans, WithRootsHandler(roots.handler))
+		defer client.Close()
+
+		testClientRoots(t, client, roots)
+	})
+
+	t.Run("client without roots", func(t *testing.T) {
+		testServer := server.NewTestServer(newRootsServer())
+		defer testServer.Close()
+
+		trans, err := transport.NewSSE(testServer.URL + "/sse")
+		require.NoError(t, err)
+		client := NewClient(trans)
+		defer client.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, client.Start(ctx))
+		_, err = client.Initialize(ctx, mcp.InitializeRequest{})
+		require.NoError(t, err)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "list-roots"
+		result, err := client.CallTool(ctx, request)
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		text, _ := mcp.AsTextContent(result.Content[0])
+		assert.Contains(t, text.Text, server.ErrRootsNotSupported.Error())
+	})
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SamplingHandlerFunc handles sampling/createMessage requests sent by the server.
+// It should sample an LLM with the given messages and return the generated message.
+type SamplingHandlerFunc func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
+// WithSamplingHandler sets the handler for sampling requests sent by the server.
+// Setting a handler advertises the sampling capability during initialization.
+// It requires a transport that supports server requests (stdio, SSE or streamable HTTP).
+func WithSamplingHandler(handler SamplingHandlerFunc) ClientOption {
+	return func(c *Client) {
+		c.samplingHandler = handler
+	}
+}
+
+// handleSamplingRequest parses a sampling/createMessage request and passes it to the sampling handler.
+func (c *Client) handleSamplingRequest(ctx context.Context, request transport.JSONRPCRequest) (any, error) {
+	paramsBytes, err := json.Marshal(request.Params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	var params mcp.CreateMessageParams
+	if err := json.Unmarshal(paramsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal params: %w", err)
+	}
+
+	// turn the content of the messages into the typed content
+	for i, message := range params.Messages {
+		contentMap, ok := message.Content.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid content of message %d", i)
+		}
+		content, err := mcp.ParseContent(contentMap)
+		if err != nil {
+			return nil, err
+		}
+		params.Messages[i].Content = content
+	}
+
+	result, err := c.samplingHandler(ctx, mcp.CreateMessageRequest{
+		Request: mcp.Request{
+			Method: request.Method,
+		},
+		CreateMessageParams: params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("sampling handler returned no result")
+	}
+	return result, nil
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newSamplingServer creates a server with a tool that asks the client to sample a message
+func newSamplingServer() *server.MCPServer {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("ask-llm"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := server.ServerFromContext(ctx).RequestSampling(ctx, mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages: []mcp.SamplingMessage{
+					{Role: mcp.RoleUser, Content: mcp.NewTextContent("What is the capital of France?")},
+				},
+				MaxTokens: 100,
+			},
+		})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("sampling failed", err), nil
+		}
+		text, _ := mcp.AsTextContent(result.Content)
+		return mcp.NewToolResultText(result.Model + ": " + text.Text), nil
+	})
+	return mcpServer
+}
+
+func samplingHandler(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	text, ok := mcp.AsTextContent(request.Messages[0].Content)
+	if !ok {
+		return nil, errors.New("expected text content")
+	}
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{
+			Role:    mcp.RoleAssistant,
+			Content: mcp.NewTextContent("Paris, you asked: " + text.Text),
+		},
+		Model:      "test-model",
+		StopReason: "endTurn",
+	}, nil
+}
+
+func callSamplingTool(t *testing.T, client *Client) *mcp.CallToolResult {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, client.Start(ctx))
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	_, err := client.Initialize(ctx, initRequest)
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "ask-llm"
+	result, err := client.CallTool(ctx, request)
+	require.NoError(t, err)
+	return result
+}
+
+func TestClientSampling(t *testing.T) {
+	t.Run("stdio", func(t *testing.T) {
+		serverReader, clientWriter := io.Pipe()
+		clientReader, serverWriter := io.Pipe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = server.NewStdioServer(newSamplingServer()).Listen(ctx, serverReader, serverWriter)
+		}()
+
+		client := NewClient(
+			transport.NewIO(clientReader, clientWriter, io.NopCloser(strings.NewReader(""))),
+			WithSamplingHandler(samplingHandler),
+		)
+		defer client.Close()
+
+		result := callSamplingTool(t, client)
+		require.False(t, result.IsError, "%+v", result.Content)
+		text, _ := mcp.AsTextContent(result.Content[0])
+		assert.Equal(t, "test-model: Paris, you asked: What is the capital of France?", text.Text)
+	})
+
+	t.Run("sse", func(t *testing.T) {
+		testServer := server.NewTestServer(newSamplingServer())
+		defer testServer.Close()
+
+		trans, err := transport.NewSSE(testServer.URL + "/sse")
+		require.NoError(t, err)
+		client := NewClient(trans, WithSamplingHandler(samplingHandler))
+		defer client.Close()
+
+		result := callSamplingTool(t, client)
+		require.False(t, result.IsError, "%+v", result.Content)
+		text, _ := mcp.AsTextContent(result.Content[0])
+		assert.Equal(t, "test-model: Paris, you asked: What is the capital of France?", text.Text)
+	})
+
+	t.Run("streamable http", func(t *testing.T) {
+		testServer := server.NewTestStreamableHTTPServer(newSamplingServer())
+		defer testServer.Close()
+
+		trans, err := transport.NewStreamableHTTP(testServer.URL)
+		require.NoError(t, err)
+		client := NewClient(trans, WithSamplingHandler(samplingHandler))
+		defer client.Close()
+
+		result := callSamplingTool(t, client)
+		require.False(t, result.IsError, "%+v", result.Content)
+		text, _ := mcp.AsTextContent(result.Content[0])
+		assert.Equal(t, "test-model: Paris, you asked: What is the capital of France?", text.Text)
+	})
+
+	t.Run("client without sampling handler", func(t *testing.T) {
+		testServer := server.NewTestStreamableHTTPServer(newSamplingServer())
+		defer testServer.Close()
+
+		trans, err := transport.NewStreamableHTTP(testServer.URL)
+		require.NoError(t, err)
+		client := NewClient(trans)
+		defer client.Close()
+
+		result := callSamplingTool(t, client)
+		require.True(t, result.IsError)
+		text, _ := mcp.AsTextContent(result.Content[0])
+		assert.Contains(t, text.Text, server.ErrSamplingNotSupported.Error())
+	})
+
+	t.Run("sampling handler error is returned to the server", func(t *testing.T) {
+		testServer := server.NewTestServer(newSamplingServer())
+		defer testServer.Close()
+
+		trans, err := transport.NewSSE(testServer.URL + "/sse")
+		require.NoError(t, err)
+		client := NewClient(trans, WithSamplingHandler(
+			func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+				return nil, errors.New("user rejected the request")
+			},
+		))
+		defer client.Close()
+
+		result := callSamplingTool(t, client)
+		require.True(t, result.IsError)
+		text, _ := mcp.AsTextContent(result.Content[0])
+		assert.Contains(t, text.Text, "user rejected the request")
+	})
+}
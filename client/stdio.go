@@ -29,6 +29,24 @@ func NewStdioMCPClient(
 	return NewClient(stdioTransport), nil
 }
 
+// NewStdioMCPClientWithOptions creates a new stdio-based MCP client like
+// NewStdioMCPClient, with its transport configured with the given options.
+//
+// NOTICE: NewStdioMCPClientWithOptions will start the connection automatically. Don't call the Start method manually.
+func NewStdioMCPClientWithOptions(
+	command string,
+	env []string,
+	args []string,
+	opts ...transport.StdioOption,
+) (*Client, error) {
+	stdioTransport := transport.NewStdioWithOptions(command, env, args, opts...)
+	if err := stdioTransport.Start(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to start stdio transport: %w", err)
+	}
+
+	return NewClient(stdioTransport), nil
+}
+
 // GetStderr returns a reader for the stderr output of the subprocess.
 // This can be used to capture error messages or logs from the subprocess.
 func GetStderr(c *Client) (io.Reader, bool) {
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/tracing"
+)
+
+// WithTracer sets the tracer starting a client span around every request sent
+// to the server. The trace context of the span is propagated to the server in
+// the _meta of the request.
+//
+// Without a tracer, the trace context in the context of requests, if any (see
+// tracing.ContextWithSpan and tracing.ContextWithRemoteSpanContext), is
+// propagated as is.
+func WithTracer(tracer tracing.Tracer) ClientOption {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+// startSpan starts the span of a request sent to the server
+func (c *Client) startSpan(ctx context.Context, method string) (context.Context, tracing.Span) {
+	tracer := c.tracer
+	if tracer == nil {
+		tracer = tracing.NoopTracer()
+	}
+	ctx, span := tracer.Start(ctx, method, tracing.SpanKindClient)
+	span.SetAttribute("mcp.method.name", method)
+	return ctx, span
+}
+
+// injectTraceContext returns the params of a request with the trace context of
+// ctx added to their _meta. The params are returned as is if ctx has no trace
+// context.
+func injectTraceContext(ctx context.Context, params any) (any, error) {
+	if !tracing.SpanContextFromContext(ctx).IsValid() {
+		return params, nil
+	}
+
+	fields := make(map[string]any)
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("failed to add trace context to params: %w", err)
+		}
+		if fields == nil {
+			fields = make(map[string]any)
+		}
+	}
+	meta, _ := fields["_meta"].(map[string]any)
+	if meta == nil {
+		meta = make(map[string]any, 2)
+	}
+	tracing.Inject(ctx, meta)
+	fields["_meta"] = meta
+	return fields, nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcp-go/tracing"
+)
+
+func TestClient_Tracing(t *testing.T) {
+	serverExporter := tracing.NewInMemoryExporter()
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithTracer(tracing.NewTracer(serverExporter)),
+	)
+	var meta *mcp.Meta
+	mcpServer.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		meta = request.Params.Meta
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	clientExporter := tracing.NewInMemoryExporter()
+	client := NewClient(
+		transport.NewInProcessTransport(mcpServer),
+		WithTracer(tracing.NewTracer(clientExporter)),
+	)
+	ctx := context.Background()
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	_, err := client.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "work"
+	request.Params.Meta = &mcp.Meta{ProgressToken: "token"}
+	_, err = client.CallTool(ctx, request)
+	require.NoError(t, err)
+
+	clientSpans := clientExporter.SpansByName("tools/call")
+	require.Len(t, clientSpans, 1)
+	assert.Equal(t, tracing.SpanKindClient, clientSpans[0].Kind)
+
+	serverSpans := serverExporter.SpansByName("tools/call")
+	require.Len(t, serverSpans, 1)
+	assert.Equal(t, clientSpans[0].SpanContext.TraceID, serverSpans[0].SpanContext.TraceID)
+	assert.Equal(t, clientSpans[0].SpanContext.SpanID, serverSpans[0].Parent.SpanID)
+	assert.True(t, serverSpans[0].Parent.Remote)
+
+	// the trace context is added to the _meta sent by the caller
+	require.NotNil(t, meta)
+	assert.Equal(t, "token", meta.ProgressToken)
+	assert.Equal(t, clientSpans[0].SpanContext.Traceparent(), meta.AdditionalFields["traceparent"])
+}
+
+func TestClient_TraceContextWithoutTracer(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0")
+	var sc tracing.SpanContext
+	mcpServer.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sc = tracing.SpanContextFromContext(ctx)
+		return mcp.NewToolResultText("done"), nil
+	})
+	client := NewClient(transport.NewInProcessTransport(mcpServer))
+	ctx := context.Background()
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	_, err := client.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+
+	ctx = tracing.Extract(ctx, map[string]any{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "work"
+	_, err = client.CallTool(ctx, request)
+	require.NoError(t, err)
+
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+}
@@ -48,3 +48,33 @@ type JSONRPCResponse struct {
 		Data    json.RawMessage `json:"data"`
 	} `json:"error"`
 }
+
+// RequestHandler handles a request sent from the server to the client (e.g.
+// sampling/createMessage) and returns the response to send back.
+type RequestHandler func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error)
+
+// BidirectionalInterface is a transport that can also receive requests from the server.
+type BidirectionalInterface interface {
+	Interface
+
+	// SetRequestHandler sets the handler for requests sent by the server.
+	// Requests received while no handler is set are answered with a method not found error.
+	SetRequestHandler(handler RequestHandler)
+}
+
+// NewJSONRPCErrorResponse creates a JSON-RPC error response for the given request id.
+func NewJSONRPCErrorResponse(id mcp.RequestId, code int, message string) *JSONRPCResponse {
+	response := &JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+	}
+	response.Error = &struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}{
+		Code:    code,
+		Message: message,
+	}
+	return response
+}
@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// parseServerRequest checks whether the message is a request sent by the server,
+// that is, a message carrying both an id and a method.
+// The params of the request are kept as json.RawMessage.
+func parseServerRequest(data []byte) (JSONRPCRequest, bool) {
+	var message struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      mcp.RequestId   `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params,omitempty"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return JSONRPCRequest{}, false
+	}
+	if message.ID.IsNil() || message.Method == "" {
+		return JSONRPCRequest{}, false
+	}
+
+	request := JSONRPCRequest{
+		JSONRPC: message.JSONRPC,
+		ID:      message.ID,
+		Method:  message.Method,
+	}
+	if len(message.Params) > 0 {
+		request.Params = message.Params
+	}
+	return request, true
+}
+
+// handleServerRequest runs the handler for the request and returns the marshaled response
+// to send back to the server. Pings are answered without calling the handler.
+func handleServerRequest(ctx context.Context, handler RequestHandler, request JSONRPCRequest) ([]byte, error) {
+	var response *JSONRPCResponse
+	if request.Method == string(mcp.MethodPing) {
+		// pings are answered by the transport itself, e.g. keep-alive pings
+		response = &JSONRPCResponse{Result: json.RawMessage("{}")}
+	} else if handler == nil {
+		response = NewJSONRPCErrorResponse(request.ID, mcp.METHOD_NOT_FOUND, "no handler for server requests")
+	} else if result, err := handler(ctx, request); err != nil {
+		response = NewJSONRPCErrorResponse(request.ID, mcp.INTERNAL_ERROR, err.Error())
+	} else if result == nil {
+		response = NewJSONRPCErrorResponse(request.ID, mcp.INTERNAL_ERROR, "empty response")
+	} else {
+		response = result
+	}
+
+	// a response carries either a result or an error, never both
+	message := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      mcp.RequestId   `json:"id"`
+		Result  json.RawMessage `json:"result,omitempty"`
+		Error   any             `json:"error,omitempty"`
+	}{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      request.ID,
+	}
+	if response.Error != nil {
+		message.Error = response.Error
+	} else {
+		message.Result = response.Result
+		if len(message.Result) == 0 {
+			message.Result = json.RawMessage("{}")
+		}
+	}
+	return json.Marshal(message)
+}
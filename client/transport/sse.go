@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // SSE implements the transport layer of the MCP protocol using Server-Sent Events (SSE).
@@ -30,9 +31,12 @@ type SSE struct {
 	mu             sync.RWMutex
 	onNotification func(mcp.JSONRPCNotification)
 	notifyMu       sync.RWMutex
+	onRequest      RequestHandler
+	requestMu      sync.RWMutex
 	endpointChan   chan struct{}
 	headers        map[string]string
 	headerFunc     HTTPHeaderFunc
+	logger         util.Logger
 
 	started         atomic.Bool
 	closed          atomic.Bool
@@ -68,6 +72,15 @@ func WithOAuth(config OAuthConfig) ClientOption {
 	}
 }
 
+// WithSSELogger sets the logger the transport reports its errors to
+func WithSSELogger(logger util.Logger) ClientOption {
+	return func(sc *SSE) {
+		sc.logger = logger
+	}
+}
+
+var _ BidirectionalInterface = (*SSE)(nil)
+
 // NewSSE creates a new SSE-based MCP client with the given base URL.
 // Returns an error if the URL is invalid.
 func NewSSE(baseURL string, options ...ClientOption) (*SSE, error) {
@@ -82,6 +95,7 @@ func NewSSE(baseURL string, options ...ClientOption) (*SSE, error) {
 		responses:    make(map[string]chan *JSONRPCResponse),
 		endpointChan: make(chan struct{}),
 		headers:      make(map[string]string),
+		logger:       util.DefaultLogger(),
 	}
 
 	for _, opt := range options {
@@ -200,7 +214,7 @@ func (c *SSE) readSSE(reader io.ReadCloser) {
 				break
 			}
 			if !c.closed.Load() {
-				fmt.Printf("SSE stream error: %v\n", err)
+				c.logger.Error("SSE stream error", "err", err)
 			}
 			return
 		}
@@ -232,20 +246,26 @@ func (c *SSE) handleSSEEvent(event, data string) {
 	case "endpoint":
 		endpoint, err := c.baseURL.Parse(data)
 		if err != nil {
-			fmt.Printf("Error parsing endpoint URL: %v\n", err)
+			c.logger.Error("failed to parse endpoint URL", "endpoint", data, "err", err)
 			return
 		}
 		if endpoint.Host != c.baseURL.Host {
-			fmt.Printf("Endpoint origin does not match connection origin\n")
+			c.logger.Error("endpoint origin does not match connection origin", "endpoint", endpoint.String())
 			return
 		}
 		c.endpoint = endpoint
 		close(c.endpointChan)
 
 	case "message":
+		// Handle request from the server, without blocking the stream
+		if request, ok := parseServerRequest([]byte(data)); ok {
+			go c.handleServerRequest(request)
+			return
+		}
+
 		var baseMessage JSONRPCResponse
 		if err := json.Unmarshal([]byte(data), &baseMessage); err != nil {
-			fmt.Printf("Error unmarshaling message: %v\n", err)
+			c.logger.Error("failed to unmarshal message", "err", err)
 			return
 		}
 
@@ -285,6 +305,31 @@ func (c *SSE) SetNotificationHandler(handler func(notification mcp.JSONRPCNotifi
 	c.onNotification = handler
 }
 
+// SetRequestHandler sets the handler function to be called when a request is received from the server.
+func (c *SSE) SetRequestHandler(handler RequestHandler) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	c.onRequest = handler
+}
+
+// handleServerRequest answers a request received from the server by posting
+// the response to the message endpoint.
+func (c *SSE) handleServerRequest(request JSONRPCRequest) {
+	c.requestMu.RLock()
+	handler := c.onRequest
+	c.requestMu.RUnlock()
+
+	ctx := context.Background()
+	responseBytes, err := handleServerRequest(ctx, handler, request)
+	if err != nil {
+		c.logger.Error("failed to marshal response", "err", err)
+		return
+	}
+	if err := c.postMessage(ctx, responseBytes); err != nil {
+		c.logger.Error("failed to send response", "err", err)
+	}
+}
+
 // SendRequest sends a JSON-RPC request to the server and waits for a response.
 // Returns the raw JSON response message or an error if the request fails.
 func (c *SSE) SendRequest(
@@ -431,11 +476,21 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 
+	return c.postMessage(ctx, notificationBytes)
+}
+
+// postMessage posts a message that expects no response, like a notification or
+// a response to a server request, to the message endpoint.
+func (c *SSE) postMessage(ctx context.Context, message []byte) error {
+	if c.endpoint == nil {
+		return fmt.Errorf("endpoint not received")
+	}
+
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
 		c.endpoint.String(),
-		bytes.NewReader(notificationBytes),
+		bytes.NewReader(message),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create notification request: %w", err)
@@ -11,12 +11,13 @@ import (
 	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // Stdio implements the transport layer of the MCP protocol using stdio communication.
 // It launches a subprocess and communicates with it via standard input/output streams
 // using JSON-RPC messages. The client handles message routing between requests and
-// responses, and supports asynchronous notifications.
+// responses, and supports asynchronous notifications and requests from the server.
 type Stdio struct {
 	command string
 	args    []string
@@ -31,20 +32,39 @@ type Stdio struct {
 	done           chan struct{}
 	onNotification func(mcp.JSONRPCNotification)
 	notifyMu       sync.RWMutex
+	onRequest      RequestHandler
+	requestMu      sync.RWMutex
+	writeMu        sync.Mutex // serializes writes to stdin
+	logger         util.Logger
+}
+
+// StdioOption defines a function type for configuring the Stdio transport
+type StdioOption func(*Stdio)
+
+// WithStdioLogger sets the logger the transport reports its errors to
+func WithStdioLogger(logger util.Logger) StdioOption {
+	return func(s *Stdio) {
+		s.logger = logger
+	}
 }
 
 // NewIO returns a new stdio-based transport using existing input, output, and
 // logging streams instead of spawning a subprocess.
 // This is useful for testing and simulating client behavior.
-func NewIO(input io.Reader, output io.WriteCloser, logging io.ReadCloser) *Stdio {
-	return &Stdio{
+func NewIO(input io.Reader, output io.WriteCloser, logging io.ReadCloser, opts ...StdioOption) *Stdio {
+	client := &Stdio{
 		stdin:  output,
 		stdout: bufio.NewReader(input),
 		stderr: logging,
 
 		responses: make(map[string]chan *JSONRPCResponse),
 		done:      make(chan struct{}),
+		logger:    util.DefaultLogger(),
+	}
+	for _, opt := range opts {
+		opt(client)
 	}
+	return client
 }
 
 // NewStdio creates a new stdio transport to communicate with a subprocess.
@@ -55,7 +75,17 @@ func NewStdio(
 	env []string,
 	args ...string,
 ) *Stdio {
+	return NewStdioWithOptions(command, env, args)
+}
 
+// NewStdioWithOptions creates a new stdio transport like NewStdio, configured
+// with the given options.
+func NewStdioWithOptions(
+	command string,
+	env []string,
+	args []string,
+	opts ...StdioOption,
+) *Stdio {
 	client := &Stdio{
 		command: command,
 		args:    args,
@@ -63,11 +93,17 @@ func NewStdio(
 
 		responses: make(map[string]chan *JSONRPCResponse),
 		done:      make(chan struct{}),
+		logger:    util.DefaultLogger(),
+	}
+	for _, opt := range opts {
+		opt(client)
 	}
 
 	return client
 }
 
+var _ BidirectionalInterface = (*Stdio)(nil)
+
 func (c *Stdio) Start(ctx context.Context) error {
 	if err := c.spawnCommand(ctx); err != nil {
 		return err
@@ -158,6 +194,37 @@ func (c *Stdio) SetNotificationHandler(
 	c.onNotification = handler
 }
 
+// SetRequestHandler sets the handler function to be called when a request is received from the server.
+func (c *Stdio) SetRequestHandler(handler RequestHandler) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	c.onRequest = handler
+}
+
+// handleServerRequest answers a request received from the server.
+func (c *Stdio) handleServerRequest(request JSONRPCRequest) {
+	c.requestMu.RLock()
+	handler := c.onRequest
+	c.requestMu.RUnlock()
+
+	responseBytes, err := handleServerRequest(context.Background(), handler, request)
+	if err != nil {
+		c.logger.Error("failed to marshal response", "err", err)
+		return
+	}
+	if err := c.write(append(responseBytes, '\n')); err != nil {
+		c.logger.Error("failed to write response", "err", err)
+	}
+}
+
+// write writes a message to the server's stdin.
+func (c *Stdio) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.stdin.Write(data)
+	return err
+}
+
 // readResponses continuously reads and processes responses from the server's stdout.
 // It handles both responses to requests and notifications, routing them appropriately.
 // Runs until the done channel is closed or an error occurs reading from stdout.
@@ -170,11 +237,17 @@ func (c *Stdio) readResponses() {
 			line, err := c.stdout.ReadString('\n')
 			if err != nil {
 				if err != io.EOF {
-					fmt.Printf("Error reading response: %v\n", err)
+					c.logger.Error("failed to read response", "err", err)
 				}
 				return
 			}
 
+			// Handle request from the server, without blocking the read loop
+			if request, ok := parseServerRequest([]byte(line)); ok {
+				go c.handleServerRequest(request)
+				continue
+			}
+
 			var baseMessage JSONRPCResponse
 			if err := json.Unmarshal([]byte(line), &baseMessage); err != nil {
 				continue
@@ -245,7 +318,7 @@ func (c *Stdio) SendRequest(
 	}
 
 	// Send request
-	if _, err := c.stdin.Write(requestBytes); err != nil {
+	if err := c.write(requestBytes); err != nil {
 		deleteResponseChan()
 		return nil, fmt.Errorf("failed to write request: %w", err)
 	}
@@ -274,7 +347,7 @@ func (c *Stdio) SendNotification(
 	}
 	notificationBytes = append(notificationBytes, '\n')
 
-	if _, err := c.stdin.Write(notificationBytes); err != nil {
+	if err := c.write(notificationBytes); err != nil {
 		return fmt.Errorf("failed to write notification: %w", err)
 	}
 
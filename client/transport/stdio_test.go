@@ -3,12 +3,16 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -484,4 +488,50 @@ func TestStdioErrors(t *testing.T) {
 		}
 	})
 
+	t.Run("ReadErrorIsLogged", func(t *testing.T) {
+		logger := &recordingLogger{messages: make(chan string, 10)}
+		stdio := NewIO(
+			iotest.ErrReader(errors.New("broken pipe")),
+			nopWriteCloser{io.Discard},
+			io.NopCloser(strings.NewReader("")),
+			WithStdioLogger(logger),
+		)
+		if err := stdio.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start Stdio transport: %v", err)
+		}
+		defer stdio.Close()
+
+		select {
+		case message := <-logger.messages:
+			if message != "ERROR failed to read response err=broken pipe" {
+				t.Errorf("Unexpected log message: %q", message)
+			}
+		case <-time.After(time.Second):
+			t.Error("Expected the read error to be logged")
+		}
+	})
+}
+
+// recordingLogger is a util.Logger recording the messages logged to it
+type recordingLogger struct {
+	messages chan string
+}
+
+func (l *recordingLogger) log(level, msg string, args ...any) {
+	message := level + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		message += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+	l.messages <- message
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) { l.log("DEBUG", msg, args...) }
+func (l *recordingLogger) Info(msg string, args ...any)  { l.log("INFO", msg, args...) }
+func (l *recordingLogger) Warn(msg string, args ...any)  { l.log("WARN", msg, args...) }
+func (l *recordingLogger) Error(msg string, args ...any) { l.log("ERROR", msg, args...) }
+
+type nopWriteCloser struct {
+	io.Writer
 }
+
+func (nopWriteCloser) Close() error { return nil }
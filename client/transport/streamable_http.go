@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 type StreamableHTTPCOption func(*StreamableHTTP)
@@ -54,6 +55,13 @@ func WithHTTPOAuth(config OAuthConfig) StreamableHTTPCOption {
 	}
 }
 
+// WithHTTPLogger sets the logger the transport reports its errors to
+func WithHTTPLogger(logger util.Logger) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.logger = logger
+	}
+}
+
 // StreamableHTTP implements Streamable HTTP transport.
 //
 // It transmits JSON-RPC messages over individual HTTP requests. One message per request.
@@ -68,18 +76,24 @@ func WithHTTPOAuth(config OAuthConfig) StreamableHTTPCOption {
 //     (https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#listening-for-messages-from-the-server)
 //   - resuming stream
 //     (https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#resumability-and-redelivery)
-//   - server -> client request
+//
+// Server -> client requests are only received on the SSE stream of an in-flight request.
 type StreamableHTTP struct {
 	serverURL  *url.URL
 	httpClient *http.Client
 	headers    map[string]string
 	headerFunc HTTPHeaderFunc
+	logger     util.Logger
 
-	sessionID atomic.Value // string
+	sessionID       atomic.Value // string
+	protocolVersion atomic.Value // string, negotiated during initialization
 
 	notificationHandler func(mcp.JSONRPCNotification)
 	notifyMu            sync.RWMutex
 
+	requestHandler RequestHandler
+	requestMu      sync.RWMutex
+
 	closed chan struct{}
 
 	// OAuth support
@@ -99,8 +113,10 @@ func NewStreamableHTTP(serverURL string, options ...StreamableHTTPCOption) (*Str
 		httpClient: &http.Client{},
 		headers:    make(map[string]string),
 		closed:     make(chan struct{}),
+		logger:     util.DefaultLogger(),
 	}
 	smc.sessionID.Store("") // set initial value to simplify later usage
+	smc.protocolVersion.Store("")
 
 	for _, opt := range options {
 		opt(smc)
@@ -142,13 +158,14 @@ func (c *StreamableHTTP) Close() error {
 			defer cancel()
 			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.serverURL.String(), nil)
 			if err != nil {
-				fmt.Printf("failed to create close request\n: %v", err)
+				c.logger.Error("failed to create close request", "err", err)
 				return
 			}
 			req.Header.Set(headerKeySessionID, sessionId)
+			c.setProtocolVersionHeader(req)
 			res, err := c.httpClient.Do(req)
 			if err != nil {
-				fmt.Printf("failed to send close request\n: %v", err)
+				c.logger.Error("failed to send close request", "err", err)
 				return
 			}
 			res.Body.Close()
@@ -159,9 +176,31 @@ func (c *StreamableHTTP) Close() error {
 }
 
 const (
-	headerKeySessionID = "Mcp-Session-Id"
+	headerKeySessionID       = "Mcp-Session-Id"
+	headerKeyProtocolVersion = "Mcp-Protocol-Version"
 )
 
+// setProtocolVersionHeader sets the protocol version negotiated during
+// initialization on requests sent after it, as required since 2025-06-18
+func (c *StreamableHTTP) setProtocolVersionHeader(req *http.Request) {
+	if version := c.protocolVersion.Load().(string); version != "" {
+		req.Header.Set(headerKeyProtocolVersion, version)
+	}
+}
+
+// storeProtocolVersion saves the protocol version of an initialize response
+func (c *StreamableHTTP) storeProtocolVersion(response *JSONRPCResponse) {
+	if response == nil || response.Error != nil {
+		return
+	}
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(response.Result, &result); err == nil {
+		c.protocolVersion.Store(result.ProtocolVersion)
+	}
+}
+
 // ErrOAuthAuthorizationRequired is a sentinel error for OAuth authorization required
 var ErrOAuthAuthorizationRequired = errors.New("no valid token available, authorization required")
 
@@ -217,6 +256,7 @@ func (c *StreamableHTTP) SendRequest(
 	if sessionID != "" {
 		req.Header.Set(headerKeySessionID, sessionID.(string))
 	}
+	c.setProtocolVersionHeader(req)
 	for k, v := range c.headers {
 		req.Header.Set(k, v)
 	}
@@ -282,29 +322,34 @@ func (c *StreamableHTTP) SendRequest(
 	}
 
 	// Handle different response types
+	var response *JSONRPCResponse
 	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
 	switch mediaType {
 	case "application/json":
 		// Single response
-		var response JSONRPCResponse
 		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 			return nil, fmt.Errorf("failed to decode response: %w", err)
 		}
 
 		// should not be a notification
-		if response.ID.IsNil() {
+		if response == nil || response.ID.IsNil() {
 			return nil, fmt.Errorf("response should contain RPC id: %v", response)
 		}
 
-		return &response, nil
-
 	case "text/event-stream":
 		// Server is using SSE for streaming responses
-		return c.handleSSEResponse(ctx, resp.Body)
+		if response, err = c.handleSSEResponse(ctx, resp.Body); err != nil {
+			return nil, err
+		}
 
 	default:
 		return nil, fmt.Errorf("unexpected content type: %s", resp.Header.Get("Content-Type"))
 	}
+
+	if request.Method == string(mcp.MethodInitialize) {
+		c.storeProtocolVersion(response)
+	}
+	return response, nil
 }
 
 // handleSSEResponse processes an SSE stream for a specific request.
@@ -326,9 +371,15 @@ func (c *StreamableHTTP) handleSSEResponse(ctx context.Context, reader io.ReadCl
 
 			// (unsupported: batching)
 
+			// Handle request from the server, the response is posted back separately
+			if request, ok := parseServerRequest([]byte(data)); ok {
+				go c.handleServerRequest(request)
+				return
+			}
+
 			var message JSONRPCResponse
 			if err := json.Unmarshal([]byte(data), &message); err != nil {
-				fmt.Printf("failed to unmarshal message: %v\n", err)
+				c.logger.Error("failed to unmarshal message", "err", err)
 				return
 			}
 
@@ -336,7 +387,7 @@ func (c *StreamableHTTP) handleSSEResponse(ctx context.Context, reader io.ReadCl
 			if message.ID.IsNil() {
 				var notification mcp.JSONRPCNotification
 				if err := json.Unmarshal([]byte(data), &notification); err != nil {
-					fmt.Printf("failed to unmarshal notification: %v\n", err)
+					c.logger.Error("failed to unmarshal notification", "err", err)
 					return
 				}
 				c.notifyMu.RLock()
@@ -389,7 +440,7 @@ func (c *StreamableHTTP) readSSE(ctx context.Context, reader io.ReadCloser, hand
 				case <-ctx.Done():
 					return
 				default:
-					fmt.Printf("SSE stream error: %v\n", err)
+					c.logger.Error("SSE stream error", "err", err)
 					return
 				}
 			}
@@ -423,6 +474,30 @@ func (c *StreamableHTTP) SendNotification(ctx context.Context, notification mcp.
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 
+	return c.postMessage(ctx, requestBody)
+}
+
+// handleServerRequest answers a request received from the server by posting
+// the response in a new HTTP request.
+func (c *StreamableHTTP) handleServerRequest(request JSONRPCRequest) {
+	c.requestMu.RLock()
+	handler := c.requestHandler
+	c.requestMu.RUnlock()
+
+	ctx := context.Background()
+	responseBody, err := handleServerRequest(ctx, handler, request)
+	if err != nil {
+		c.logger.Error("failed to marshal response", "err", err)
+		return
+	}
+	if err := c.postMessage(ctx, responseBody); err != nil {
+		c.logger.Error("failed to send response", "err", err)
+	}
+}
+
+// postMessage posts a message that expects no response, like a notification or
+// a response to a server request.
+func (c *StreamableHTTP) postMessage(ctx context.Context, requestBody []byte) error {
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.serverURL.String(), bytes.NewReader(requestBody))
 	if err != nil {
@@ -435,6 +510,7 @@ func (c *StreamableHTTP) SendNotification(ctx context.Context, notification mcp.
 	if sessionID := c.sessionID.Load(); sessionID != "" {
 		req.Header.Set(headerKeySessionID, sessionID.(string))
 	}
+	c.setProtocolVersionHeader(req)
 	for k, v := range c.headers {
 		req.Header.Set(k, v)
 	}
@@ -492,6 +568,15 @@ func (c *StreamableHTTP) SetNotificationHandler(handler func(mcp.JSONRPCNotifica
 	c.notificationHandler = handler
 }
 
+// SetRequestHandler sets the handler for requests sent by the server.
+func (c *StreamableHTTP) SetRequestHandler(handler RequestHandler) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	c.requestHandler = handler
+}
+
+var _ BidirectionalInterface = (*StreamableHTTP)(nil)
+
 func (c *StreamableHTTP) GetSessionId() string {
 	return c.sessionID.Load().(string)
 }
@@ -448,3 +448,70 @@ func TestStreamableHTTPErrors(t *testing.T) {
 	})
 
 }
+
+func TestStreamableHTTP_ProtocolVersionHeader(t *testing.T) {
+	var mu sync.Mutex
+	headers := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		method, _ := request["method"].(string)
+		mu.Lock()
+		headers[method] = r.Header.Get("Mcp-Protocol-Version")
+		mu.Unlock()
+
+		if _, ok := request["id"]; !ok {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      request["id"],
+			"result":  map[string]any{"protocolVersion": "2025-06-18"},
+		})
+	}))
+	defer server.Close()
+
+	trans, err := NewStreamableHTTP(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+	}
+	defer trans.Close()
+
+	ctx := context.Background()
+	for i, method := range []string{"initialize", "ping"} {
+		_, err := trans.SendRequest(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      mcp.NewRequestId(int64(i)),
+			Method:  method,
+		})
+		if err != nil {
+			t.Fatalf("Failed to send %s request: %v", method, err)
+		}
+	}
+	err = trans.SendNotification(ctx, mcp.JSONRPCNotification{
+		JSONRPC:      "2.0",
+		Notification: mcp.Notification{Method: "notifications/initialized"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to send notification: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := map[string]string{
+		"initialize":                "",
+		"ping":                      "2025-06-18",
+		"notifications/initialized": "2025-06-18",
+	}
+	for method, version := range expected {
+		if headers[method] != version {
+			t.Errorf("Expected protocol version header %q for %s, got %q", version, method, headers[method])
+		}
+	}
+}
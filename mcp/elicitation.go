@@ -0,0 +1,213 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"unicode/utf8"
+)
+
+/* Elicitation */
+
+// ElicitRequest is sent from the server to the client to request additional
+// information from the user. The requested schema is restricted to a flat
+// object with properties of primitive types, so that clients can render a
+// simple form for it.
+type ElicitRequest struct {
+	Request
+	Params ElicitationParams `json:"params"`
+}
+
+type ElicitationParams struct {
+	// The message to present to the user.
+	Message string `json:"message"`
+	// A restricted subset of JSON Schema describing the requested information.
+	RequestedSchema ElicitationSchema `json:"requestedSchema"`
+}
+
+// ElicitationSchema is the schema of the information requested with an
+// ElicitRequest. Only top-level properties of primitive types are allowed.
+type ElicitationSchema struct {
+	Type       string                     `json:"type"` // Always "object"
+	Properties map[string]PrimitiveSchema `json:"properties"`
+	Required   []string                   `json:"required,omitempty"`
+}
+
+// PrimitiveSchema is the schema of a property of an ElicitationSchema.
+// It's a string, number, integer or boolean schema, or a string schema with
+// an enumeration of allowed values.
+type PrimitiveSchema struct {
+	// One of "string", "number", "integer" or "boolean".
+	Type        string `json:"type"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// String constraints. Format is one of "email", "uri", "date" or "date-time".
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Format    string `json:"format,omitempty"`
+
+	// Enumeration of the allowed values of a string, with optional display names.
+	Enum      []string `json:"enum,omitempty"`
+	EnumNames []string `json:"enumNames,omitempty"`
+
+	// Number and integer constraints.
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// Default value of a boolean.
+	Default *bool `json:"default,omitempty"`
+}
+
+// ElicitationResponseAction is the action taken by the user in response to an
+// elicitation request.
+type ElicitationResponseAction string
+
+const (
+	// ElicitationResponseActionAccept means the user submitted the requested information.
+	ElicitationResponseActionAccept ElicitationResponseAction = "accept"
+	// ElicitationResponseActionDecline means the user explicitly declined the request.
+	ElicitationResponseActionDecline ElicitationResponseAction = "decline"
+	// ElicitationResponseActionCancel means the user dismissed the request without choosing.
+	ElicitationResponseActionCancel ElicitationResponseAction = "cancel"
+)
+
+// ElicitResult is the client's response to an elicitation/create request.
+type ElicitResult struct {
+	Result
+	// The action taken by the user.
+	Action ElicitationResponseAction `json:"action"`
+	// The submitted information, only present when the action is "accept".
+	// Values are strings, numbers or booleans, as described by the requested schema.
+	Content map[string]any `json:"content,omitempty"`
+}
+
+// NewElicitationSchema creates an object schema with the given properties,
+// and marks the properties named in required as required.
+func NewElicitationSchema(properties map[string]PrimitiveSchema, required ...string) ElicitationSchema {
+	return ElicitationSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   required,
+	}
+}
+
+// BindContent unmarshals the submitted content into the provided struct.
+func (r ElicitResult) BindContent(target any) error {
+	if target == nil || reflect.ValueOf(target).Kind() != reflect.Ptr {
+		return fmt.Errorf("target must be a non-nil pointer")
+	}
+
+	data, err := json.Marshal(r.Content)
+	if err != nil {
+		return fmt.Errorf("failed to marshal content: %w", err)
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+var elicitationFormats = []string{"email", "uri", "date", "date-time"}
+
+// Validate checks that the schema only uses the subset of JSON Schema allowed
+// for elicitation.
+func (s ElicitationSchema) Validate() error {
+	if s.Type != "object" {
+		return fmt.Errorf("elicitation schema must be of type object, got %q", s.Type)
+	}
+	for name, property := range s.Properties {
+		if err := property.validate(); err != nil {
+			return fmt.Errorf("property %q: %w", name, err)
+		}
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok {
+			return fmt.Errorf("required property %q is not defined", name)
+		}
+	}
+	return nil
+}
+
+func (p PrimitiveSchema) validate() error {
+	isString := p.Type == "string"
+	isNumber := p.Type == "number" || p.Type == "integer"
+	if !isString && !isNumber && p.Type != "boolean" {
+		return fmt.Errorf("unsupported type %q", p.Type)
+	}
+	if !isString && (p.MinLength != nil || p.MaxLength != nil || p.Format != "" || len(p.Enum) > 0) {
+		return fmt.Errorf("string constraints are not allowed for type %q", p.Type)
+	}
+	if !isNumber && (p.Minimum != nil || p.Maximum != nil) {
+		return fmt.Errorf("number constraints are not allowed for type %q", p.Type)
+	}
+	if p.Type != "boolean" && p.Default != nil {
+		return fmt.Errorf("default values are only allowed for booleans")
+	}
+	if p.Format != "" && !slices.Contains(elicitationFormats, p.Format) {
+		return fmt.Errorf("unsupported format %q", p.Format)
+	}
+	if len(p.EnumNames) > 0 && len(p.EnumNames) != len(p.Enum) {
+		return fmt.Errorf("enumNames must have as many entries as enum")
+	}
+	return nil
+}
+
+// ValidateContent checks that the content submitted by the user, as decoded
+// from JSON, matches the schema.
+func (s ElicitationSchema) ValidateContent(content map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := content[name]; !ok {
+			return fmt.Errorf("missing required property %q", name)
+		}
+	}
+	for name, value := range content {
+		property, ok := s.Properties[name]
+		if !ok {
+			return fmt.Errorf("unexpected property %q", name)
+		}
+		if err := property.validateValue(value); err != nil {
+			return fmt.Errorf("property %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (p PrimitiveSchema) validateValue(value any) error {
+	switch p.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %T", value)
+		}
+		length := utf8.RuneCountInString(s)
+		if p.MinLength != nil && length < *p.MinLength {
+			return fmt.Errorf("must be at least %d characters long", *p.MinLength)
+		}
+		if p.MaxLength != nil && length > *p.MaxLength {
+			return fmt.Errorf("must be at most %d characters long", *p.MaxLength)
+		}
+		if len(p.Enum) > 0 && !slices.Contains(p.Enum, s) {
+			return fmt.Errorf("must be one of %v", p.Enum)
+		}
+	case "number", "integer":
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("expected a number, got %T", value)
+		}
+		if p.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("expected an integer, got %v", n)
+		}
+		if p.Minimum != nil && n < *p.Minimum {
+			return fmt.Errorf("must be at least %v", *p.Minimum)
+		}
+		if p.Maximum != nil && n > *p.Maximum {
+			return fmt.Errorf("must be at most %v", *p.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a boolean, got %T", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElicitationSchemaValidate(t *testing.T) {
+	minLength, maximum := 2, 10.0
+	tests := []struct {
+		name    string
+		schema  ElicitationSchema
+		wantErr string
+	}{
+		{
+			name: "valid schema",
+			schema: NewElicitationSchema(map[string]PrimitiveSchema{
+				"name":    {Type: "string", MinLength: &minLength, Title: "Name"},
+				"email":   {Type: "string", Format: "email"},
+				"age":     {Type: "integer", Maximum: &maximum},
+				"agree":   {Type: "boolean", Default: ToBoolPtr(false)},
+				"color":   {Type: "string", Enum: []string{"red", "green"}, EnumNames: []string{"Red", "Green"}},
+				"ratio":   {Type: "number"},
+				"comment": {Type: "string"},
+			}, "name", "agree"),
+		},
+		{
+			name:    "not an object",
+			schema:  ElicitationSchema{Type: "array"},
+			wantErr: "must be of type object",
+		},
+		{
+			name: "nested object",
+			schema: NewElicitationSchema(map[string]PrimitiveSchema{
+				"address": {Type: "object"},
+			}),
+			wantErr: `property "address": unsupported type "object"`,
+		},
+		{
+			name: "string constraint on a number",
+			schema: NewElicitationSchema(map[string]PrimitiveSchema{
+				"age": {Type: "number", MinLength: &minLength},
+			}),
+			wantErr: "string constraints are not allowed",
+		},
+		{
+			name: "number constraint on a string",
+			schema: NewElicitationSchema(map[string]PrimitiveSchema{
+				"name": {Type: "string", Maximum: &maximum},
+			}),
+			wantErr: "number constraints are not allowed",
+		},
+		{
+			name: "unsupported format",
+			schema: NewElicitationSchema(map[string]PrimitiveSchema{
+				"ip": {Type: "string", Format: "ipv4"},
+			}),
+			wantErr: `unsupported format "ipv4"`,
+		},
+		{
+			name: "mismatched enum names",
+			schema: NewElicitationSchema(map[string]PrimitiveSchema{
+				"color": {Type: "string", Enum: []string{"red", "green"}, EnumNames: []string{"Red"}},
+			}),
+			wantErr: "enumNames",
+		},
+		{
+			name: "undefined required property",
+			schema: NewElicitationSchema(map[string]PrimitiveSchema{
+				"name": {Type: "string"},
+			}, "email"),
+			wantErr: `required property "email" is not defined`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestElicitationSchemaValidateContent(t *testing.T) {
+	minLength, minimum, maximum := 2, 0.0, 150.0
+	schema := NewElicitationSchema(map[string]PrimitiveSchema{
+		"name":  {Type: "string", MinLength: &minLength},
+		"age":   {Type: "integer", Minimum: &minimum, Maximum: &maximum},
+		"agree": {Type: "boolean"},
+		"color": {Type: "string", Enum: []string{"red", "green"}},
+	}, "name")
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid content", content: `{"name": "Ann", "age": 30, "agree": true, "color": "red"}`},
+		{name: "only required properties", content: `{"name": "Ann"}`},
+		{name: "missing required property", content: `{"age": 30}`, wantErr: `missing required property "name"`},
+		{name: "unexpected property", content: `{"name": "Ann", "extra": 1}`, wantErr: `unexpected property "extra"`},
+		{name: "string too short", content: `{"name": "A"}`, wantErr: "at least 2 characters"},
+		{name: "wrong type", content: `{"name": "Ann", "agree": "yes"}`, wantErr: "expected a boolean"},
+		{name: "not an integer", content: `{"name": "Ann", "age": 30.5}`, wantErr: "expected an integer"},
+		{name: "number out of range", content: `{"name": "Ann", "age": 200}`, wantErr: "at most 150"},
+		{name: "value not in enum", content: `{"name": "Ann", "color": "blue"}`, wantErr: "must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var content map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.content), &content))
+			err := schema.ValidateContent(content)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestElicitResultBindContent(t *testing.T) {
+	var result ElicitResult
+	require.NoError(t, json.Unmarshal([]byte(`{"action": "accept", "content": {"name": "Ann", "age": 30}}`), &result))
+	assert.Equal(t, ElicitationResponseActionAccept, result.Action)
+
+	var content struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	require.NoError(t, result.BindContent(&content))
+	assert.Equal(t, "Ann", content.Name)
+	assert.Equal(t, 30, content.Age)
+
+	assert.Error(t, result.BindContent(content))
+}
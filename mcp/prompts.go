@@ -45,11 +45,16 @@ type GetPromptResult struct {
 type Prompt struct {
 	// The name of the prompt or prompt template.
 	Name string `json:"name"`
+	// A human-readable title for the prompt, intended for display.
+	// Added in 2025-06-18.
+	Title string `json:"title,omitempty"`
 	// An optional description of what this prompt provides
 	Description string `json:"description,omitempty"`
 	// A list of arguments to use for templating the prompt.
 	// The presence of arguments indicates this is a template prompt.
 	Arguments []PromptArgument `json:"arguments,omitempty"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 // GetName returns the name of the prompt.
@@ -63,6 +68,9 @@ func (p Prompt) GetName() string {
 type PromptArgument struct {
 	// The name of the argument.
 	Name string `json:"name"`
+	// A human-readable title for the argument, intended for display.
+	// Added in 2025-06-18.
+	Title string `json:"title,omitempty"`
 	// A human-readable description of the argument.
 	Description string `json:"description,omitempty"`
 	// Whether this argument must be provided.
@@ -85,7 +93,7 @@ const (
 // resources from the MCP server.
 type PromptMessage struct {
 	Role    Role    `json:"role"`
-	Content Content `json:"content"` // Can be TextContent, ImageContent, AudioContent or EmbeddedResource
+	Content Content `json:"content"` // Can be TextContent, ImageContent, AudioContent, EmbeddedResource or ResourceLink
 }
 
 // PromptListChangedNotification is an optional notification from the server
@@ -130,6 +138,13 @@ func WithPromptDescription(description string) PromptOption {
 	}
 }
 
+// WithPromptTitle adds a human-readable title to the Prompt, intended for display.
+func WithPromptTitle(title string) PromptOption {
+	return func(p *Prompt) {
+		p.Title = title
+	}
+}
+
 // WithArgument adds an argument to the prompt's argument list.
 // The argument will be configured based on the provided options.
 func WithArgument(name string, opts ...ArgumentOption) PromptOption {
@@ -161,6 +176,13 @@ func ArgumentDescription(desc string) ArgumentOption {
 	}
 }
 
+// ArgumentTitle adds a human-readable title to a prompt argument, intended for display.
+func ArgumentTitle(title string) ArgumentOption {
+	return func(arg *PromptArgument) {
+		arg.Title = title
+	}
+}
+
 // RequiredArgument marks an argument as required in the prompt.
 // Required arguments must be provided when getting the prompt.
 func RequiredArgument() ArgumentOption {
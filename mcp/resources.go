@@ -30,6 +30,13 @@ func WithResourceDescription(description string) ResourceOption {
 	}
 }
 
+// WithResourceTitle adds a human-readable title to the Resource, intended for display.
+func WithResourceTitle(title string) ResourceOption {
+	return func(r *Resource) {
+		r.Title = title
+	}
+}
+
 // WithMIMEType sets the MIME type for the Resource.
 // This should indicate the format of the resource's contents.
 func WithMIMEType(mimeType string) ResourceOption {
@@ -78,6 +85,13 @@ func WithTemplateDescription(description string) ResourceTemplateOption {
 	}
 }
 
+// WithTemplateTitle adds a human-readable title to the ResourceTemplate, intended for display.
+func WithTemplateTitle(title string) ResourceTemplateOption {
+	return func(t *ResourceTemplate) {
+		t.Title = title
+	}
+}
+
 // WithTemplateMIMEType sets the MIME type for the ResourceTemplate.
 // This should only be set if all resources matching this template will have the same type.
 func WithTemplateMIMEType(mimeType string) ResourceTemplateOption {
@@ -8,7 +8,10 @@ import (
 	"strconv"
 )
 
-var errToolSchemaConflict = errors.New("provide either InputSchema or RawInputSchema, not both")
+var (
+	errToolSchemaConflict       = errors.New("provide either InputSchema or RawInputSchema, not both")
+	errToolOutputSchemaConflict = errors.New("provide either OutputSchema or RawOutputSchema, not both")
+)
 
 // ListToolsRequest is sent from the client to request a list of tools the
 // server has.
@@ -40,6 +43,9 @@ type CallToolResult struct {
 	//
 	// If not set, this is assumed to be false (the call was successful).
 	IsError bool `json:"isError,omitempty"`
+	// An optional JSON object that represents the structured result of the
+	// tool call. It must conform to the output schema of the tool, if any.
+	StructuredContent any `json:"structuredContent,omitempty"`
 }
 
 // CallToolRequest is used by the client to invoke a tool provided by the server.
@@ -472,14 +478,24 @@ type ToolListChangedNotification struct {
 type Tool struct {
 	// The name of the tool.
 	Name string `json:"name"`
+	// A human-readable title for the tool, intended for display.
+	// It takes precedence over the title annotation. Added in 2025-06-18.
+	Title string `json:"title,omitempty"`
 	// A human-readable description of the tool.
 	Description string `json:"description,omitempty"`
 	// A JSON Schema object defining the expected parameters for the tool.
 	InputSchema ToolInputSchema `json:"inputSchema"`
 	// Alternative to InputSchema - allows arbitrary JSON Schema to be provided
 	RawInputSchema json.RawMessage `json:"-"` // Hide this from JSON marshaling
+	// An optional JSON Schema object defining the structure of the tool's
+	// output returned in the StructuredContent field of a CallToolResult.
+	OutputSchema ToolOutputSchema `json:"outputSchema"`
+	// Alternative to OutputSchema - allows arbitrary JSON Schema to be provided
+	RawOutputSchema json.RawMessage `json:"-"` // Hide this from JSON marshaling
 	// Optional properties describing tool behavior
 	Annotations ToolAnnotation `json:"annotations"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 // GetName returns the name of the tool.
@@ -493,8 +509,11 @@ func (t Tool) MarshalJSON() ([]byte, error) {
 	// Create a map to build the JSON structure
 	m := make(map[string]any, 3)
 
-	// Add the name and description
+	// Add the name, title and description
 	m["name"] = t.Name
+	if t.Title != "" {
+		m["title"] = t.Title
+	}
 	if t.Description != "" {
 		m["description"] = t.Description
 	}
@@ -510,8 +529,22 @@ func (t Tool) MarshalJSON() ([]byte, error) {
 		m["inputSchema"] = t.InputSchema
 	}
 
+	// The output schema is optional
+	if t.RawOutputSchema != nil {
+		if t.OutputSchema.Type != "" {
+			return nil, fmt.Errorf("tool %s has both OutputSchema and RawOutputSchema set: %w", t.Name, errToolOutputSchemaConflict)
+		}
+		m["outputSchema"] = t.RawOutputSchema
+	} else if t.OutputSchema.Type != "" {
+		m["outputSchema"] = t.OutputSchema
+	}
+
 	m["annotations"] = t.Annotations
 
+	if t.Meta != nil {
+		m["_meta"] = t.Meta
+	}
+
 	return json.Marshal(m)
 }
 
@@ -538,6 +571,19 @@ func (tis ToolInputSchema) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+// ToolOutputSchema is the JSON Schema of the structured content returned by a tool.
+// It is always an object schema.
+type ToolOutputSchema struct {
+	Type       string         `json:"type"`
+	Properties map[string]any `json:"properties,omitempty"`
+	Required   []string       `json:"required,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for ToolOutputSchema.
+func (tos ToolOutputSchema) MarshalJSON() ([]byte, error) {
+	return ToolInputSchema(tos).MarshalJSON()
+}
+
 type ToolAnnotation struct {
 	// Human-readable title for the tool
 	Title string `json:"title,omitempty"`
@@ -615,6 +661,34 @@ func WithDescription(description string) ToolOption {
 	}
 }
 
+// WithTitle adds a human-readable title to the Tool, intended for display.
+func WithTitle(title string) ToolOption {
+	return func(t *Tool) {
+		t.Title = title
+	}
+}
+
+// WithOutputSchema sets the JSON Schema of the structured content returned by the Tool.
+// The schema is always an object schema with the given properties, and the
+// properties named in required marked as required.
+func WithOutputSchema(properties map[string]any, required ...string) ToolOption {
+	return func(t *Tool) {
+		t.OutputSchema = ToolOutputSchema{
+			Type:       "object",
+			Properties: properties,
+			Required:   required,
+		}
+	}
+}
+
+// WithRawOutputSchema sets an arbitrary JSON Schema for the structured content
+// returned by the Tool. It's an alternative to WithOutputSchema.
+func WithRawOutputSchema(schema json.RawMessage) ToolOption {
+	return func(t *Tool) {
+		t.RawOutputSchema = schema
+	}
+}
+
 // WithToolAnnotation adds optional hints about the Tool.
 func WithToolAnnotation(annotation ToolAnnotation) ToolOption {
 	return func(t *Tool) {
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestToolWithBothSchemasError verifies that there will be feedback if the
@@ -528,3 +529,99 @@ func TestFlexibleArgumentsJSONMarshalUnmarshal(t *testing.T) {
 	assert.Equal(t, "value1", args["key1"])
 	assert.Equal(t, float64(123), args["key2"]) // JSON numbers are unmarshaled as float64
 }
+
+func TestToolWithOutputSchema(t *testing.T) {
+	t.Run("structured output schema", func(t *testing.T) {
+		tool := NewTool("weather",
+			WithOutputSchema(map[string]any{
+				"temperature": map[string]any{"type": "number"},
+			}, "temperature"),
+		)
+
+		data, err := json.Marshal(tool)
+		require.NoError(t, err)
+		var result map[string]any
+		require.NoError(t, json.Unmarshal(data, &result))
+
+		schema, ok := result["outputSchema"].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, "object", schema["type"])
+		assert.Contains(t, schema["properties"], "temperature")
+		assert.Equal(t, []any{"temperature"}, schema["required"])
+
+		// the schema survives a round trip
+		var unmarshalled Tool
+		require.NoError(t, json.Unmarshal(data, &unmarshalled))
+		assert.Equal(t, tool.OutputSchema, unmarshalled.OutputSchema)
+	})
+
+	t.Run("raw output schema", func(t *testing.T) {
+		tool := NewTool("weather", WithRawOutputSchema(json.RawMessage(`{"type": "object", "additionalProperties": false}`)))
+
+		data, err := json.Marshal(tool)
+		require.NoError(t, err)
+		var result map[string]any
+		require.NoError(t, json.Unmarshal(data, &result))
+		assert.Equal(t, map[string]any{"type": "object", "additionalProperties": false}, result["outputSchema"])
+	})
+
+	t.Run("no output schema", func(t *testing.T) {
+		data, err := json.Marshal(NewTool("weather"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "outputSchema")
+	})
+
+	t.Run("both schemas", func(t *testing.T) {
+		tool := NewTool("weather",
+			WithOutputSchema(map[string]any{}),
+			WithRawOutputSchema(json.RawMessage(`{"type": "object"}`)),
+		)
+		_, err := json.Marshal(tool)
+		assert.ErrorIs(t, err, errToolOutputSchemaConflict)
+	})
+}
+
+func TestCallToolResultStructuredContent(t *testing.T) {
+	type weather struct {
+		Temperature float64 `json:"temperature"`
+	}
+
+	result := NewToolResultStructuredOnly(weather{Temperature: 21.5})
+	assert.Equal(t, weather{Temperature: 21.5}, result.StructuredContent)
+	text, ok := AsTextContent(result.Content[0])
+	require.True(t, ok)
+	assert.JSONEq(t, `{"temperature": 21.5}`, text.Text)
+
+	result = NewToolResultStructured(weather{Temperature: 21.5}, "21.5°C")
+	text, _ = AsTextContent(result.Content[0])
+	assert.Equal(t, "21.5°C", text.Text)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	raw := json.RawMessage(data)
+	parsed, err := ParseCallToolResult(&raw)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"temperature": 21.5}, parsed.StructuredContent)
+	require.Len(t, parsed.Content, 1)
+
+	data, err = json.Marshal(NewToolResultText("plain"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "structuredContent")
+}
+
+func TestToolTitleAndMeta(t *testing.T) {
+	tool := NewTool("weather", WithTitle("Weather"))
+	tool.Meta = map[string]any{"vendor": "acme"}
+
+	data, err := json.Marshal(tool)
+	require.NoError(t, err)
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "Weather", result["title"])
+	assert.Equal(t, map[string]any{"vendor": "acme"}, result["_meta"])
+
+	data, err = json.Marshal(NewTool("weather"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "title")
+	assert.NotContains(t, string(data), "_meta")
+}
@@ -18,3 +18,25 @@ func NewTypedToolHandler[T any](handler TypedToolHandlerFunc[T]) func(ctx contex
 		return handler(ctx, request, args)
 	}
 }
+
+// StructuredToolHandlerFunc is a function that handles a tool call with typed
+// arguments and returns a typed result
+type StructuredToolHandlerFunc[TArgs any, TResult any] func(ctx context.Context, request CallToolRequest, args TArgs) (TResult, error)
+
+// NewStructuredToolHandler creates a ToolHandlerFunc that automatically binds
+// arguments to a typed struct, and returns the result of the handler as
+// structured content with its JSON serialization as text fallback.
+// Errors returned by the handler are reported as tool errors.
+func NewStructuredToolHandler[TArgs any, TResult any](handler StructuredToolHandlerFunc[TArgs, TResult]) func(ctx context.Context, request CallToolRequest) (*CallToolResult, error) {
+	return func(ctx context.Context, request CallToolRequest) (*CallToolResult, error) {
+		var args TArgs
+		if err := request.BindArguments(&args); err != nil {
+			return NewToolResultError(fmt.Sprintf("failed to bind arguments: %v", err)), nil
+		}
+		result, err := handler(ctx, request, args)
+		if err != nil {
+			return NewToolResultErrorFromErr("tool execution failed", err), nil
+		}
+		return NewToolResultStructuredOnly(result), nil
+	}
+}
@@ -302,3 +302,40 @@ func TestTypedToolHandlerWithComplexObjects(t *testing.T) {
 	assert.Contains(t, result.Content[0].(TextContent).Text, "Theme: system")
 	assert.Contains(t, result.Content[0].(TextContent).Text, "Subscribed to 1 newsletters")
 }
+
+func TestStructuredToolHandler(t *testing.T) {
+	type SumArgs struct {
+		Numbers []float64 `json:"numbers"`
+	}
+	type SumResult struct {
+		Sum   float64 `json:"sum"`
+		Count int     `json:"count"`
+	}
+
+	handler := NewStructuredToolHandler(func(ctx context.Context, request CallToolRequest, args SumArgs) (SumResult, error) {
+		if len(args.Numbers) == 0 {
+			return SumResult{}, fmt.Errorf("no numbers")
+		}
+		result := SumResult{Count: len(args.Numbers)}
+		for _, n := range args.Numbers {
+			result.Sum += n
+		}
+		return result, nil
+	})
+
+	req := CallToolRequest{}
+	req.Params.Arguments = map[string]any{"numbers": []any{1, 2, 3.5}}
+	result, err := handler(context.Background(), req)
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, SumResult{Sum: 6.5, Count: 3}, result.StructuredContent)
+	assert.JSONEq(t, `{"sum": 6.5, "count": 3}`, result.Content[0].(TextContent).Text)
+
+	// errors of the handler are tool errors
+	req.Params.Arguments = map[string]any{"numbers": []any{}}
+	result, err = handler(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Nil(t, result.StructuredContent)
+	assert.Contains(t, result.Content[0].(TextContent).Text, "no numbers")
+}
@@ -34,6 +34,14 @@ const (
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/resources/
 	MethodResourcesRead MCPMethod = "resources/read"
 
+	// MethodResourcesSubscribe subscribes to updates of a specific resource.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#subscriptions
+	MethodResourcesSubscribe MCPMethod = "resources/subscribe"
+
+	// MethodResourcesUnsubscribe cancels a previous subscription to a resource.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#subscriptions
+	MethodResourcesUnsubscribe MCPMethod = "resources/unsubscribe"
+
 	// MethodPromptsList lists all available prompt templates.
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/prompts/
 	MethodPromptsList MCPMethod = "prompts/list"
@@ -54,10 +62,28 @@ const (
 	// https://modelcontextprotocol.io/specification/2025-03-26/server/utilities/logging
 	MethodSetLogLevel MCPMethod = "logging/setLevel"
 
+	// MethodCompletionComplete asks the server for completion options of a prompt or resource template argument.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/utilities/completion
+	MethodCompletionComplete MCPMethod = "completion/complete"
+
+	// MethodSamplingCreateMessage asks the client to sample an LLM on behalf of the server.
+	// https://modelcontextprotocol.io/specification/2025-03-26/client/sampling
+	MethodSamplingCreateMessage MCPMethod = "sampling/createMessage"
+
+	// MethodListRoots asks the client for the list of roots the server can operate on.
+	// https://modelcontextprotocol.io/specification/2025-03-26/client/roots
+	MethodListRoots MCPMethod = "roots/list"
+
+	// MethodElicitationCreate asks the client to request additional information from the user.
+	// https://modelcontextprotocol.io/specification/2025-06-18/client/elicitation
+	MethodElicitationCreate MCPMethod = "elicitation/create"
+
 	// MethodNotificationResourcesListChanged notifies when the list of available resources changes.
 	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#list-changed-notification
 	MethodNotificationResourcesListChanged = "notifications/resources/list_changed"
 
+	// MethodNotificationResourceUpdated notifies subscribers that a resource has changed.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#subscriptions
 	MethodNotificationResourceUpdated = "notifications/resources/updated"
 
 	// MethodNotificationPromptsListChanged notifies when the list of available prompt templates changes.
@@ -67,6 +93,22 @@ const (
 	// MethodNotificationToolsListChanged notifies when the list of available tools changes.
 	// https://spec.modelcontextprotocol.io/specification/2024-11-05/server/tools/list_changed/
 	MethodNotificationToolsListChanged = "notifications/tools/list_changed"
+
+	// MethodNotificationRootsListChanged notifies when the list of roots of the client changes.
+	// https://modelcontextprotocol.io/specification/2025-03-26/client/roots#root-list-changes
+	MethodNotificationRootsListChanged = "notifications/roots/list_changed"
+
+	// MethodNotificationMessage sends a log message to the client.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/utilities/logging#log-message-notifications
+	MethodNotificationMessage = "notifications/message"
+
+	// MethodNotificationCancelled notifies that a previously-issued request is cancelled.
+	// https://modelcontextprotocol.io/specification/2025-03-26/basic/utilities/cancellation
+	MethodNotificationCancelled = "notifications/cancelled"
+
+	// MethodNotificationProgress reports the progress of a long-running request.
+	// https://modelcontextprotocol.io/specification/2025-03-26/basic/utilities/progress
+	MethodNotificationProgress = "notifications/progress"
 )
 
 type URITemplate struct {
@@ -92,15 +134,17 @@ func (t *URITemplate) UnmarshalJSON(data []byte) error {
 
 /* JSON-RPC types */
 
-// JSONRPCMessage represents either a JSONRPCRequest, JSONRPCNotification, JSONRPCResponse, or JSONRPCError
+// JSONRPCMessage represents either a JSONRPCRequest, JSONRPCNotification, JSONRPCResponse, JSONRPCError
+// or JSONRPCBatchResponse
 type JSONRPCMessage any
 
 // LATEST_PROTOCOL_VERSION is the most recent version of the MCP protocol.
-const LATEST_PROTOCOL_VERSION = "2025-03-26"
+const LATEST_PROTOCOL_VERSION = "2025-06-18"
 
 // ValidProtocolVersions lists all known valid MCP protocol versions.
 var ValidProtocolVersions = []string{
 	"2024-11-05",
+	"2025-03-26",
 	LATEST_PROTOCOL_VERSION,
 }
 
@@ -343,6 +387,11 @@ type JSONRPCError struct {
 	} `json:"error"`
 }
 
+// JSONRPCBatchResponse is the response to a JSON-RPC batch. It holds a
+// JSONRPCResponse or JSONRPCError for each request of the batch, in the order
+// of the requests. Notifications have no response.
+type JSONRPCBatchResponse []JSONRPCMessage
+
 // Standard JSON-RPC error codes
 const (
 	PARSE_ERROR      = -32700
@@ -355,6 +404,12 @@ const (
 // MCP error codes
 const (
 	RESOURCE_NOT_FOUND = -32002
+	// RATE_LIMITED is returned when a request exceeds a rate limit or quota of
+	// the server. It's not defined by the specification.
+	RATE_LIMITED = -32029
+	// FORBIDDEN is returned when the caller lacks the scopes required by a
+	// tool, prompt or resource. It's not defined by the specification.
+	FORBIDDEN = -32030
 )
 
 /* Empty result */
@@ -446,6 +501,8 @@ type ClientCapabilities struct {
 	} `json:"roots,omitempty"`
 	// Present if the client supports sampling from an LLM.
 	Sampling *struct{} `json:"sampling,omitempty"`
+	// Present if the client supports elicitation requests from the server.
+	Elicitation *struct{} `json:"elicitation,omitempty"`
 }
 
 // ServerCapabilities represents capabilities that a server may support. Known
@@ -474,11 +531,15 @@ type ServerCapabilities struct {
 		// Whether this server supports notifications for changes to the tool list.
 		ListChanged bool `json:"listChanged,omitempty"`
 	} `json:"tools,omitempty"`
+	// Present if the server supports argument autocompletion suggestions.
+	Completions *struct{} `json:"completions,omitempty"`
 }
 
 // Implementation describes the name and version of an MCP implementation.
 type Implementation struct {
-	Name    string `json:"name"`
+	Name string `json:"name"`
+	// A human-readable name, intended for display. Added in 2025-06-18.
+	Title   string `json:"title,omitempty"`
 	Version string `json:"version"`
 }
 
@@ -641,6 +702,9 @@ type Resource struct {
 	//
 	// This can be used by clients to populate UI elements.
 	Name string `json:"name"`
+	// A human-readable title for this resource, intended for display.
+	// Added in 2025-06-18.
+	Title string `json:"title,omitempty"`
 	// A description of what this resource represents.
 	//
 	// This can be used by clients to improve the LLM's understanding of
@@ -648,6 +712,8 @@ type Resource struct {
 	Description string `json:"description,omitempty"`
 	// The MIME type of this resource, if known.
 	MIMEType string `json:"mimeType,omitempty"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 // GetName returns the name of the resource.
@@ -666,6 +732,9 @@ type ResourceTemplate struct {
 	//
 	// This can be used by clients to populate UI elements.
 	Name string `json:"name"`
+	// A human-readable title for this template, intended for display.
+	// Added in 2025-06-18.
+	Title string `json:"title,omitempty"`
 	// A description of what this template is for.
 	//
 	// This can be used by clients to improve the LLM's understanding of
@@ -674,6 +743,8 @@ type ResourceTemplate struct {
 	// The MIME type for all resources that match this template. This should only
 	// be included if all resources matching this template have the same type.
 	MIMEType string `json:"mimeType,omitempty"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 // GetName returns the name of the resourceTemplate.
@@ -695,6 +766,8 @@ type TextResourceContents struct {
 	// The text of the item. This must only be set if the item can actually be
 	// represented as text (not binary data).
 	Text string `json:"text"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 func (TextResourceContents) isResourceContents() {}
@@ -706,6 +779,8 @@ type BlobResourceContents struct {
 	MIMEType string `json:"mimeType,omitempty"`
 	// A base64-encoded string representing the binary data of the item.
 	Blob string `json:"blob"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 func (BlobResourceContents) isResourceContents() {}
@@ -761,6 +836,28 @@ const (
 	LoggingLevelEmergency LoggingLevel = "emergency"
 )
 
+var loggingLevelSeverity = map[LoggingLevel]int{
+	LoggingLevelDebug:     0,
+	LoggingLevelInfo:      1,
+	LoggingLevelNotice:    2,
+	LoggingLevelWarning:   3,
+	LoggingLevelError:     4,
+	LoggingLevelCritical:  5,
+	LoggingLevelAlert:     6,
+	LoggingLevelEmergency: 7,
+}
+
+// ShouldSendTo reports whether a message of this level should be sent to a client
+// that set the given minimum level with logging/setLevel, i.e. whether this level
+// is at least as severe. Messages of unknown levels are always sent.
+func (l LoggingLevel) ShouldSendTo(minLevel LoggingLevel) bool {
+	severity, ok := loggingLevelSeverity[l]
+	if !ok {
+		return true
+	}
+	return severity >= loggingLevelSeverity[minLevel]
+}
+
 /* Sampling */
 
 // CreateMessageRequest is a request from the server to sample an LLM via the
@@ -835,6 +932,8 @@ type TextContent struct {
 	Type string `json:"type"` // Must be "text"
 	// The text content of the message.
 	Text string `json:"text"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 func (TextContent) isContent() {}
@@ -848,6 +947,8 @@ type ImageContent struct {
 	Data string `json:"data"`
 	// The MIME type of the image. Different providers may support different image types.
 	MIMEType string `json:"mimeType"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 func (ImageContent) isContent() {}
@@ -861,6 +962,8 @@ type AudioContent struct {
 	Data string `json:"data"`
 	// The MIME type of the audio. Different providers may support different audio types.
 	MIMEType string `json:"mimeType"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 func (AudioContent) isContent() {}
@@ -873,10 +976,35 @@ type EmbeddedResource struct {
 	Annotated
 	Type     string           `json:"type"`
 	Resource ResourceContents `json:"resource"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 func (EmbeddedResource) isContent() {}
 
+// ResourceLink is a link to a resource that the server is capable of reading,
+// included in a prompt or tool call result. Unlike EmbeddedResource, the
+// contents are not included: the client reads the resource if needed.
+// It must have Type set to "resource_link". Added in 2025-06-18.
+type ResourceLink struct {
+	Annotated
+	Type string `json:"type"` // Must be "resource_link"
+	// The URI of the linked resource.
+	URI string `json:"uri"`
+	// A human-readable name for the linked resource.
+	Name string `json:"name"`
+	// A human-readable title for the linked resource, intended for display.
+	Title string `json:"title,omitempty"`
+	// A description of what the linked resource represents.
+	Description string `json:"description,omitempty"`
+	// The MIME type of the linked resource, if known.
+	MIMEType string `json:"mimeType,omitempty"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
+}
+
+func (ResourceLink) isContent() {}
+
 // ModelPreferences represents the server's preferences for model selection,
 // requested of the client during sampling.
 //
@@ -966,6 +1094,13 @@ type CompleteResult struct {
 	} `json:"completion"`
 }
 
+const (
+	// RefTypePrompt is the type of a PromptReference
+	RefTypePrompt = "ref/prompt"
+	// RefTypeResource is the type of a ResourceReference
+	RefTypeResource = "ref/resource"
+)
+
 // ResourceReference is a reference to a resource or resource template definition.
 type ResourceReference struct {
 	Type string `json:"type"`
@@ -1011,6 +1146,8 @@ type Root struct {
 	// identifier for the root, which may be useful for display purposes or for
 	// referencing the root in other parts of the application.
 	Name string `json:"name,omitempty"`
+	// Reserved by the protocol to attach additional metadata. Added in 2025-06-18.
+	Meta map[string]any `json:"_meta,omitempty"`
 }
 
 // RootsListChangedNotification is a notification from the client to the
@@ -68,3 +68,43 @@ func TestMetaMarshalling(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceLink(t *testing.T) {
+	link := NewResourceLink("file:///project/README.md", "README", "Project readme", "text/markdown")
+	link.Title = "Read me"
+	link.Meta = map[string]any{"source": "git"}
+	link.Annotations = &Annotations{Audience: []Role{RoleUser}, Priority: 0.5}
+	data, err := json.Marshal(link)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "resource_link",
+		"uri": "file:///project/README.md",
+		"name": "README",
+		"title": "Read me",
+		"description": "Project readme",
+		"mimeType": "text/markdown",
+		"_meta": {"source": "git"},
+		"annotations": {"audience": ["user"], "priority": 0.5}
+	}`, string(data))
+
+	var contentMap map[string]any
+	require.NoError(t, json.Unmarshal(data, &contentMap))
+	content, err := ParseContent(contentMap)
+	require.NoError(t, err)
+	parsed, ok := AsResourceLink(content)
+	require.True(t, ok)
+	assert.Equal(t, link, *parsed)
+
+	_, err = ParseContent(map[string]any{"type": "resource_link", "name": "README"})
+	assert.Error(t, err)
+	_, err = ParseContent(map[string]any{"type": "resource_link", "uri": "file:///a", "name": "a", "annotations": map[string]any{"priority": "high"}})
+	assert.Error(t, err)
+}
+
+func TestLoggingLevel_ShouldSendTo(t *testing.T) {
+	assert.True(t, LoggingLevelError.ShouldSendTo(LoggingLevelWarning))
+	assert.True(t, LoggingLevelWarning.ShouldSendTo(LoggingLevelWarning))
+	assert.False(t, LoggingLevelInfo.ShouldSendTo(LoggingLevelWarning))
+	assert.True(t, LoggingLevelEmergency.ShouldSendTo(LoggingLevelDebug))
+	assert.True(t, LoggingLevel("custom").ShouldSendTo(LoggingLevelEmergency))
+}
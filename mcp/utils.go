@@ -88,6 +88,11 @@ func AsEmbeddedResource(content any) (*EmbeddedResource, bool) {
 	return asType[EmbeddedResource](content)
 }
 
+// AsResourceLink attempts to cast the given interface to ResourceLink
+func AsResourceLink(content any) (*ResourceLink, bool) {
+	return asType[ResourceLink](content)
+}
+
 // AsTextResourceContents attempts to cast the given interface to TextResourceContents
 func AsTextResourceContents(content any) (*TextResourceContents, bool) {
 	return asType[TextResourceContents](content)
@@ -171,7 +176,7 @@ func NewLoggingMessageNotification(
 ) LoggingMessageNotification {
 	return LoggingMessageNotification{
 		Notification: Notification{
-			Method: "notifications/message",
+			Method: MethodNotificationMessage,
 		},
 		Params: struct {
 			Level  LoggingLevel `json:"level"`
@@ -230,6 +235,17 @@ func NewEmbeddedResource(resource ResourceContents) EmbeddedResource {
 	}
 }
 
+// NewResourceLink creates a new ResourceLink pointing to the resource with the given URI
+func NewResourceLink(uri, name, description, mimeType string) ResourceLink {
+	return ResourceLink{
+		Type:        "resource_link",
+		URI:         uri,
+		Name:        name,
+		Description: description,
+		MIMEType:    mimeType,
+	}
+}
+
 // NewToolResultText creates a new CallToolResult with a text content
 func NewToolResultText(text string) *CallToolResult {
 	return &CallToolResult{
@@ -242,6 +258,31 @@ func NewToolResultText(text string) *CallToolResult {
 	}
 }
 
+// NewToolResultStructured creates a new CallToolResult with structured content,
+// and the given text as fallback for clients that don't support structured content.
+func NewToolResultStructured(structured any, fallbackText string) *CallToolResult {
+	return &CallToolResult{
+		Content: []Content{
+			TextContent{
+				Type: "text",
+				Text: fallbackText,
+			},
+		},
+		StructuredContent: structured,
+	}
+}
+
+// NewToolResultStructuredOnly creates a new CallToolResult with structured content,
+// and its JSON serialization as text fallback for clients that don't support
+// structured content.
+func NewToolResultStructuredOnly(structured any) *CallToolResult {
+	data, err := json.Marshal(structured)
+	if err != nil {
+		return NewToolResultErrorFromErr("failed to marshal structured content", err)
+	}
+	return NewToolResultStructured(structured, string(data))
+}
+
 // NewToolResultImage creates a new CallToolResult with both text and image content
 func NewToolResultImage(text, imageData, mimeType string) *CallToolResult {
 	return &CallToolResult{
@@ -488,11 +529,44 @@ func ParseContent(contentMap map[string]any) (Content, error) {
 		}
 
 		return NewEmbeddedResource(resourceContents), nil
+
+	case "resource_link":
+		uri := ExtractString(contentMap, "uri")
+		name := ExtractString(contentMap, "name")
+		if uri == "" || name == "" {
+			return nil, fmt.Errorf("resource link uri or name is missing")
+		}
+		link := NewResourceLink(uri, name, ExtractString(contentMap, "description"), ExtractString(contentMap, "mimeType"))
+		link.Title = ExtractString(contentMap, "title")
+		link.Meta = ExtractMap(contentMap, "_meta")
+		annotations, err := parseAnnotations(contentMap)
+		if err != nil {
+			return nil, err
+		}
+		link.Annotations = annotations
+		return link, nil
 	}
 
 	return nil, fmt.Errorf("unsupported content type: %s", contentType)
 }
 
+// parseAnnotations parses the annotations of a content, if any
+func parseAnnotations(contentMap map[string]any) (*Annotations, error) {
+	annotationsMap := ExtractMap(contentMap, "annotations")
+	if annotationsMap == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(annotationsMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal annotations: %w", err)
+	}
+	var annotations Annotations
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return nil, fmt.Errorf("invalid annotations: %w", err)
+	}
+	return &annotations, nil
+}
+
 func ParseGetPromptResult(rawMessage *json.RawMessage) (*GetPromptResult, error) {
 	if rawMessage == nil {
 		return nil, fmt.Errorf("response is nil")
@@ -585,6 +659,10 @@ func ParseCallToolResult(rawMessage *json.RawMessage) (*CallToolResult, error) {
 		}
 	}
 
+	if structuredContent, ok := jsonContent["structuredContent"]; ok {
+		result.StructuredContent = structuredContent
+	}
+
 	contents, ok := jsonContent["content"]
 	if !ok {
 		return nil, fmt.Errorf("content is missing")
@@ -614,6 +692,50 @@ func ParseCallToolResult(rawMessage *json.RawMessage) (*CallToolResult, error) {
 	return &result, nil
 }
 
+func ParseCreateMessageResult(rawMessage *json.RawMessage) (*CreateMessageResult, error) {
+	if rawMessage == nil {
+		return nil, fmt.Errorf("response is nil")
+	}
+
+	var jsonContent map[string]any
+	if err := json.Unmarshal(*rawMessage, &jsonContent); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	var result CreateMessageResult
+
+	meta, ok := jsonContent["_meta"]
+	if ok {
+		if metaMap, ok := meta.(map[string]any); ok {
+			result.Meta = metaMap
+		}
+	}
+
+	result.Model = ExtractString(jsonContent, "model")
+	result.StopReason = ExtractString(jsonContent, "stopReason")
+
+	// Extract role
+	roleStr := ExtractString(jsonContent, "role")
+	if roleStr != string(RoleAssistant) && roleStr != string(RoleUser) {
+		return nil, fmt.Errorf("unsupported role: %s", roleStr)
+	}
+	result.Role = Role(roleStr)
+
+	// Extract content
+	contentMap, ok := jsonContent["content"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("content is not an object")
+	}
+
+	content, err := ParseContent(contentMap)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = content
+
+	return &result, nil
+}
+
 func ParseResourceContents(contentMap map[string]any) (ResourceContents, error) {
 	uri := ExtractString(contentMap, "uri")
 	if uri == "" {
@@ -14,6 +14,7 @@ import (
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // Server encapsulates an MCP server and manages resources like pipes and context.
@@ -126,7 +127,7 @@ func (s *Server) Start(ctx context.Context) error {
 		logger := log.New(&s.logBuffer, "", 0)
 
 		stdioServer := server.NewStdioServer(mcpServer)
-		stdioServer.SetErrorLogger(logger)
+		stdioServer.SetLogger(util.NewStdLogger(logger))
 
 		if err := stdioServer.Listen(ctx, s.serverReader, s.serverWriter); err != nil {
 			logger.Println("StdioServer.Listen failed:", err)
@@ -0,0 +1,264 @@
+// Package metrics provides the counters, gauges and histograms recorded by MCP
+// servers, and their exposition in the Prometheus text format
+// (https://prometheus.io/docs/instrumenting/exposition_formats/).
+//
+// Metrics are created in a Registry, which is an http.Handler serving them:
+//
+//	registry := metrics.NewRegistry()
+//	s := server.NewMCPServer("my-server", "1.0.0", server.WithMetrics(registry))
+//	http.Handle("/mcp", server.NewStreamableHTTPServer(s))
+//	http.Handle("/metrics", registry)
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds of the buckets of histograms measuring
+// durations in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// metricType is the type of a metric in the exposition format
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+// desc describes a metric
+type desc struct {
+	name       string
+	help       string
+	typ        metricType
+	labelNames []string
+}
+
+// labelKey joins label values into the key of a series
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+// checkLabels panics if the number of label values doesn't match the label names
+func (d *desc) checkLabels(labelValues []string) {
+	if len(labelValues) != len(d.labelNames) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", d.name, len(d.labelNames), len(labelValues)))
+	}
+}
+
+// Counter is a metric whose value only goes up, partitioned by label values
+type Counter struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// Inc increments the counter of the label values by 1
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the counter of the label values
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: counter %s can't decrease", c.name))
+	}
+	c.checkLabels(labelValues)
+	key := labelKey(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	series, ok := c.series[key]
+	if !ok {
+		series = &counterSeries{labelValues: slices.Clone(labelValues)}
+		c.series[key] = series
+	}
+	series.value += v
+}
+
+// Value returns the value of the counter of the label values
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if series, ok := c.series[labelKey(labelValues)]; ok {
+		return series.value
+	}
+	return 0
+}
+
+func (c *Counter) samples() []sample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	samples := make([]sample, 0, len(c.series))
+	for _, series := range c.series {
+		samples = append(samples, sample{labelValues: series.labelValues, value: series.value})
+	}
+	return samples
+}
+
+// Gauge is a metric whose value can go up and down, partitioned by label values
+type Gauge struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+// Set sets the gauge of the label values to v
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.update(labelValues, func(value float64) float64 { return v })
+}
+
+// Add adds v, which can be negative, to the gauge of the label values
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.update(labelValues, func(value float64) float64 { return value + v })
+}
+
+func (g *Gauge) update(labelValues []string, f func(float64) float64) {
+	g.checkLabels(labelValues)
+	key := labelKey(labelValues)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	series, ok := g.series[key]
+	if !ok {
+		series = &counterSeries{labelValues: slices.Clone(labelValues)}
+		g.series[key] = series
+	}
+	series.value = f(series.value)
+}
+
+// Value returns the value of the gauge of the label values
+func (g *Gauge) Value(labelValues ...string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if series, ok := g.series[labelKey(labelValues)]; ok {
+		return series.value
+	}
+	return 0
+}
+
+func (g *Gauge) samples() []sample {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	samples := make([]sample, 0, len(g.series))
+	for _, series := range g.series {
+		samples = append(samples, sample{labelValues: series.labelValues, value: series.value})
+	}
+	return samples
+}
+
+// GaugeFunc is a gauge whose values are collected when the metrics are scraped
+type GaugeFunc struct {
+	desc
+	collect func(set func(value float64, labelValues ...string))
+}
+
+func (g *GaugeFunc) samples() []sample {
+	byKey := make(map[string]*sample)
+	g.collect(func(value float64, labelValues ...string) {
+		g.checkLabels(labelValues)
+		key := labelKey(labelValues)
+		if s, ok := byKey[key]; ok {
+			s.value = value
+			return
+		}
+		byKey[key] = &sample{labelValues: slices.Clone(labelValues), value: value}
+	})
+	samples := make([]sample, 0, len(byKey))
+	for _, s := range byKey {
+		samples = append(samples, *s)
+	}
+	return samples
+}
+
+// Histogram is a metric counting observations in buckets, partitioned by label values
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // per bucket, not cumulative, with +Inf last
+	count       uint64
+	sum         float64
+}
+
+// Observe adds an observation to the histogram of the label values
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.checkLabels(labelValues)
+	key := labelKey(labelValues)
+	bucket := sort.SearchFloat64s(h.buckets, v)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{
+			labelValues: slices.Clone(labelValues),
+			counts:      make([]uint64, len(h.buckets)+1),
+		}
+		h.series[key] = series
+	}
+	series.counts[bucket]++
+	series.count++
+	series.sum += v
+}
+
+// Count returns the number of observations of the histogram of the label values
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if series, ok := h.series[labelKey(labelValues)]; ok {
+		return series.count
+	}
+	return 0
+}
+
+func (h *Histogram) samples() []sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	samples := make([]sample, 0, len(h.series))
+	for _, series := range h.series {
+		samples = append(samples, sample{
+			labelValues: series.labelValues,
+			counts:      slices.Clone(series.counts),
+			count:       series.count,
+			value:       series.sum,
+		})
+	}
+	return samples
+}
+
+// sample is the value of a series when the metrics are scraped. For histograms,
+// value is the sum of the observations.
+type sample struct {
+	labelValues []string
+	value       float64
+	counts      []uint64
+	count       uint64
+}
+
+// formatFloat formats a value in the exposition format
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
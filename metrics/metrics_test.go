@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter(t *testing.T) {
+	counter := NewRegistry().NewCounter("requests_total", "Requests.", "method")
+	counter.Inc("ping")
+	counter.Add(2, "ping")
+	counter.Inc("tools/call")
+
+	assert.Equal(t, 3.0, counter.Value("ping"))
+	assert.Equal(t, 1.0, counter.Value("tools/call"))
+	assert.Equal(t, 0.0, counter.Value("unknown"))
+	assert.Panics(t, func() { counter.Add(-1, "ping") })
+	assert.Panics(t, func() { counter.Inc() }, "label values must match the label names")
+}
+
+func TestGauge(t *testing.T) {
+	gauge := NewRegistry().NewGauge("sessions", "Sessions.")
+	gauge.Add(3)
+	gauge.Add(-1)
+	assert.Equal(t, 2.0, gauge.Value())
+	gauge.Set(10)
+	assert.Equal(t, 10.0, gauge.Value())
+}
+
+func TestHistogram(t *testing.T) {
+	histogram := NewRegistry().NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "tool")
+	histogram.Observe(0.05, "echo")
+	histogram.Observe(0.1, "echo")
+	histogram.Observe(5, "echo")
+
+	assert.Equal(t, uint64(3), histogram.Count("echo"))
+	samples := histogram.samples()
+	assert.Len(t, samples, 1)
+	assert.Equal(t, []uint64{2, 0, 1}, samples[0].counts)
+	assert.InDelta(t, 5.15, samples[0].value, 1e-9)
+
+	assert.Panics(t, func() {
+		NewRegistry().NewHistogram("latency_seconds", "Latency.", []float64{1, 0.1})
+	})
+}
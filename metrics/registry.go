@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// collector is a metric of a registry
+type collector interface {
+	describe() *desc
+	samples() []sample
+}
+
+func (d *desc) describe() *desc { return d }
+
+// Registry holds metrics and serves them in the Prometheus text format. The
+// metrics are created by the New* methods: creating a metric with the name of
+// an existing one returns the existing metric if it has the same type and label
+// names, and panics otherwise.
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]collector
+}
+
+var _ http.Handler = (*Registry)(nil)
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// register adds the collector built by newCollector, or returns the existing
+// collector with the same name
+func register[T collector](r *Registry, d desc, newCollector func(d desc) T) T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.collectors[d.name]; ok {
+		c, ok := existing.(T)
+		if !ok || !slices.Equal(existing.describe().labelNames, d.labelNames) {
+			panic(fmt.Sprintf("metrics: %s is already registered with another type or labels", d.name))
+		}
+		return c
+	}
+	c := newCollector(d)
+	r.collectors[d.name] = c
+	return c
+}
+
+// NewCounter creates a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return register(r, desc{name, help, typeCounter, labelNames}, func(d desc) *Counter {
+		return &Counter{desc: d, series: make(map[string]*counterSeries)}
+	})
+}
+
+// NewGauge creates a gauge with the given label names
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return register(r, desc{name, help, typeGauge, labelNames}, func(d desc) *Gauge {
+		return &Gauge{desc: d, series: make(map[string]*counterSeries)}
+	})
+}
+
+// NewGaugeFunc creates a gauge with the given label names, whose values are
+// set by collect each time the metrics are scraped
+func (r *Registry) NewGaugeFunc(
+	name, help string,
+	collect func(set func(value float64, labelValues ...string)),
+	labelNames ...string,
+) *GaugeFunc {
+	return register(r, desc{name, help, typeGauge, labelNames}, func(d desc) *GaugeFunc {
+		return &GaugeFunc{desc: d, collect: collect}
+	})
+}
+
+// NewHistogram creates a histogram with the given bucket upper bounds, in
+// increasing order, and label names. DefaultBuckets are used if buckets is nil.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	if !slices.IsSorted(buckets) {
+		panic(fmt.Sprintf("metrics: buckets of %s are not sorted", name))
+	}
+	return register(r, desc{name, help, typeHistogram, labelNames}, func(d desc) *Histogram {
+		return &Histogram{desc: d, buckets: slices.Clone(buckets), series: make(map[string]*histogramSeries)}
+	})
+}
+
+// WriteText writes the metrics in the Prometheus text format, sorted by name
+// and label values
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.Unlock()
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].describe().name < collectors[j].describe().name
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		d := c.describe()
+		samples := c.samples()
+		sort.Slice(samples, func(i, j int) bool {
+			return slices.Compare(samples[i].labelValues, samples[j].labelValues) < 0
+		})
+		fmt.Fprintf(bw, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", d.name, d.typ)
+		for _, s := range samples {
+			if d.typ != typeHistogram {
+				fmt.Fprintf(bw, "%s%s %s\n", d.name, formatLabels(d.labelNames, s.labelValues, "", ""), formatFloat(s.value))
+				continue
+			}
+			h := c.(*Histogram)
+			var cumulative uint64
+			for i, upperBound := range h.buckets {
+				cumulative += s.counts[i]
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", d.name, formatLabels(d.labelNames, s.labelValues, "le", formatFloat(upperBound)), cumulative)
+			}
+			fmt.Fprintf(bw, "%s_bucket%s %d\n", d.name, formatLabels(d.labelNames, s.labelValues, "le", "+Inf"), s.count)
+			fmt.Fprintf(bw, "%s_sum%s %s\n", d.name, formatLabels(d.labelNames, s.labelValues, "", ""), formatFloat(s.value))
+			fmt.Fprintf(bw, "%s_count%s %d\n", d.name, formatLabels(d.labelNames, s.labelValues, "", ""), s.count)
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if req.Method == http.MethodHead {
+		return
+	}
+	_ = r.WriteText(w)
+}
+
+// formatLabels formats the labels of a sample, with an extra label if extraName
+// isn't empty
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(values[i]))
+		b.WriteByte('"')
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(extraName)
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(extraValue))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// labelValueEscaper escapes backslashes, double quotes and line feeds in label values
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeHelp escapes backslashes and line feeds in help texts
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounter("requests_total", "Requests.", "method")
+	assert.Same(t, counter, registry.NewCounter("requests_total", "Requests.", "method"))
+	assert.Panics(t, func() { registry.NewGauge("requests_total", "Requests.", "method") })
+	assert.Panics(t, func() { registry.NewCounter("requests_total", "Requests.", "tool") })
+}
+
+func TestRegistry_WriteText(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("requests_total", "Requests received,\nper method.", "method", "status").Inc("tools/call", "ok")
+	registry.NewCounter("requests_total", "", "method", "status").Inc("ping", `a"b\c`)
+	registry.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "tool").Observe(0.5, "echo")
+	registry.NewGaugeFunc("queue_depth", "Queued events.", func(set func(float64, ...string)) {
+		set(7)
+	})
+	registry.NewGauge("idle", "Never set.")
+
+	var b strings.Builder
+	require.NoError(t, registry.WriteText(&b))
+	assert.Equal(t, `# HELP idle Never set.
+# TYPE idle gauge
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{tool="echo",le="0.1"} 0
+latency_seconds_bucket{tool="echo",le="1"} 1
+latency_seconds_bucket{tool="echo",le="+Inf"} 1
+latency_seconds_sum{tool="echo"} 0.5
+latency_seconds_count{tool="echo"} 1
+# HELP queue_depth Queued events.
+# TYPE queue_depth gauge
+queue_depth 7
+# HELP requests_total Requests received,\nper method.
+# TYPE requests_total counter
+requests_total{method="ping",status="a\"b\\c"} 1
+requests_total{method="tools/call",status="ok"} 1
+`, b.String())
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("requests_total", "Requests.").Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "requests_total 1\n")
+
+	recorder = httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/util"
+)
+
+// protectedResourceMetadataPath is the well-known path of the OAuth protected
+// resource metadata (RFC 9728)
+const protectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// TokenClaims are the claims of a verified access token
+type TokenClaims struct {
+	// Subject is the user or client the token was issued for
+	Subject string
+	// Issuer is the authorization server that issued the token
+	Issuer string
+	// Audience lists the resources the token was issued for
+	Audience []string
+	// Scopes granted by the token
+	Scopes []string
+	// ClientID is the client the token was issued to
+	ClientID string
+	// ExpiresAt is when the token expires
+	ExpiresAt time.Time
+	// Raw holds all the claims of the token
+	Raw map[string]any
+}
+
+// HasScope reports whether the token grants the scope
+func (c *TokenClaims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// TokenVerifier verifies bearer access tokens. It returns an error wrapping
+// ErrInvalidToken if the token isn't valid, whose message is sent to the
+// client. Other errors are logged and answered with a generic message.
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (*TokenClaims, error)
+}
+
+// resourceTokenVerifier is implemented by the verifiers that check that the
+// tokens were issued for the resource when no audience is configured
+type resourceTokenVerifier interface {
+	verifyTokenFor(ctx context.Context, token, resource string) (*TokenClaims, error)
+}
+
+// TokenVerifierFunc is a function verifying tokens, e.g. by calling the token
+// introspection endpoint (RFC 7662) of the authorization server.
+// NewTokenClaims builds claims from an introspection response.
+type TokenVerifierFunc func(ctx context.Context, token string) (*TokenClaims, error)
+
+// VerifyToken implements TokenVerifier
+func (f TokenVerifierFunc) VerifyToken(ctx context.Context, token string) (*TokenClaims, error) {
+	return f(ctx, token)
+}
+
+// NewTokenClaims builds token claims from JSON claims, such as the ones of a JWT
+// or of a token introspection response: sub, iss, aud, scope (or scp),
+// client_id (or azp) and exp.
+func NewTokenClaims(raw map[string]any) *TokenClaims {
+	return claimsFromMap(raw)
+}
+
+type tokenClaimsKey struct{}
+
+// TokenClaimsFromContext returns the claims of the access token of the request,
+// verified by the resource server of the transport (see ResourceServerConfig)
+func TokenClaimsFromContext(ctx context.Context) (*TokenClaims, bool) {
+	claims, ok := ctx.Value(tokenClaimsKey{}).(*TokenClaims)
+	return claims, ok
+}
+
+// ResourceServerConfig configures StreamableHTTPServer and SSEServer as OAuth
+// 2.1 resource servers, as required by the MCP authorization specification:
+// requests must carry a bearer access token, which is verified before they are
+// handled, and the protected resource metadata (RFC 9728) is served at
+// /.well-known/oauth-protected-resource.
+type ResourceServerConfig struct {
+	// Verifier verifies the access tokens, e.g. NewJWTVerifier
+	Verifier TokenVerifier
+	// Resource is the URL of the MCP server, e.g. https://example.com/mcp.
+	// It defaults to the origin of the requests.
+	Resource string
+	// AuthorizationServers are the issuer URLs of the authorization servers
+	// the clients can get tokens from
+	AuthorizationServers []string
+	// RequiredScopes are the scopes every token must grant. Requests with
+	// tokens missing one are answered with 403 Forbidden.
+	RequiredScopes []string
+	// ScopesSupported are the scopes advertised in the metadata
+	ScopesSupported []string
+	// ResourceName is the human readable name advertised in the metadata
+	ResourceName string
+}
+
+// resourceServer verifies the access tokens of HTTP requests
+type resourceServer struct {
+	config ResourceServerConfig
+	logger util.Logger
+}
+
+// metadataPaths returns the paths the protected resource metadata is served at:
+// the well-known path, and the well-known path followed by the path of the
+// resource if it has one
+func (rs *resourceServer) metadataPaths() []string {
+	paths := []string{protectedResourceMetadataPath}
+	if u, err := url.Parse(rs.config.Resource); err == nil && strings.Trim(u.Path, "/") != "" {
+		paths = append(paths, protectedResourceMetadataPath+"/"+strings.Trim(u.Path, "/"))
+	}
+	return paths
+}
+
+// resource returns the URL of the resource
+func (rs *resourceServer) resource(r *http.Request) string {
+	if rs.config.Resource != "" {
+		return rs.config.Resource
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// metadataURL returns the URL of the protected resource metadata
+func (rs *resourceServer) metadataURL(r *http.Request) string {
+	resource := rs.resource(r)
+	u, err := url.Parse(resource)
+	if err != nil {
+		return resource + protectedResourceMetadataPath
+	}
+	metadataPath := protectedResourceMetadataPath
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		metadataPath += "/" + path
+	}
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: metadataPath}).String()
+}
+
+// serveMetadata serves the protected resource metadata if the request is for
+// it, and reports whether it was
+func (rs *resourceServer) serveMetadata(w http.ResponseWriter, r *http.Request) bool {
+	if !slices.Contains(rs.metadataPaths(), r.URL.Path) {
+		return false
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return true
+	}
+	metadata := map[string]any{
+		"resource":                 rs.resource(r),
+		"bearer_methods_supported": []string{"header"},
+	}
+	if len(rs.config.AuthorizationServers) > 0 {
+		metadata["authorization_servers"] = rs.config.AuthorizationServers
+	}
+	if len(rs.config.ScopesSupported) > 0 {
+		metadata["scopes_supported"] = rs.config.ScopesSupported
+	}
+	if rs.config.ResourceName != "" {
+		metadata["resource_name"] = rs.config.ResourceName
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(metadata); err != nil {
+		rs.logger.Error("failed to write protected resource metadata", "err", err)
+	}
+	return true
+}
+
+// authenticate verifies the bearer token of the request, and returns the
+// request with the claims of the token in its context. Requests without a
+// valid token are answered with 401 Unauthorized, and requests whose token
+// lacks a required scope with 403 Forbidden.
+func (rs *resourceServer) authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	token = strings.TrimSpace(token)
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		rs.challenge(w, r, http.StatusUnauthorized, "", "")
+		return nil, false
+	}
+
+	var claims *TokenClaims
+	var err error
+	if verifier, ok := rs.config.Verifier.(resourceTokenVerifier); ok {
+		claims, err = verifier.verifyTokenFor(r.Context(), token, rs.resource(r))
+	} else {
+		claims, err = rs.config.Verifier.VerifyToken(r.Context(), token)
+	}
+	if err != nil || claims == nil {
+		description := "invalid access token"
+		if errors.Is(err, ErrInvalidToken) {
+			description = err.Error()
+		} else if err != nil {
+			rs.logger.Error("failed to verify access token", "err", err)
+		}
+		rs.challenge(w, r, http.StatusUnauthorized, "invalid_token", description)
+		return nil, false
+	}
+	for _, scope := range rs.config.RequiredScopes {
+		if !claims.HasScope(scope) {
+			rs.challenge(w, r, http.StatusForbidden, "insufficient_scope", "missing scope "+scope)
+			return nil, false
+		}
+	}
+	return r.WithContext(context.WithValue(r.Context(), tokenClaimsKey{}, claims)), true
+}
+
+// challenge answers a request with the status and a WWW-Authenticate header
+// (RFC 6750) pointing the client to the protected resource metadata
+func (rs *resourceServer) challenge(w http.ResponseWriter, r *http.Request, status int, errorCode, description string) {
+	params := []string{fmt.Sprintf("resource_metadata=%q", rs.metadataURL(r))}
+	if errorCode != "" {
+		params = append(params,
+			fmt.Sprintf("error=%q", errorCode),
+			fmt.Sprintf("error_description=%q", strings.ReplaceAll(description, `"`, "'")),
+		)
+	}
+	if status == http.StatusForbidden && len(rs.config.RequiredScopes) > 0 {
+		params = append(params, fmt.Sprintf("scope=%q", strings.Join(rs.config.RequiredScopes, " ")))
+	}
+	w.Header().Set("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	http.Error(w, http.StatusText(status), status)
+}
+
+// NewProtectedResourceMetadataHandler returns a handler serving the protected
+// resource metadata of the config, for servers whose routes are set up by hand,
+// e.g. with WithDynamicBasePath. Mount it at /.well-known/oauth-protected-resource
+// and at that path followed by the path of the resource.
+func NewProtectedResourceMetadataHandler(config ResourceServerConfig) http.Handler {
+	rs := &resourceServer{config: config, logger: util.DefaultLogger()}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !rs.serveMetadata(w, r) {
+			http.NotFound(w, r)
+		}
+	})
+}
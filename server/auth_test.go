@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newTestResourceServerConfig returns a config accepting the tokens "alice" and
+// "carol" (with the mcp scope) and "bob" (without scopes)
+func newTestResourceServerConfig() ResourceServerConfig {
+	return ResourceServerConfig{
+		Verifier: TokenVerifierFunc(func(ctx context.Context, token string) (*TokenClaims, error) {
+			switch token {
+			case "alice":
+				return &TokenClaims{Subject: "alice", Scopes: []string{"mcp"}}, nil
+			case "bob":
+				return &TokenClaims{Subject: "bob"}, nil
+			case "carol":
+				return &TokenClaims{Subject: "carol", Scopes: []string{"mcp"}}, nil
+			case "unreachable":
+				return nil, errors.New("introspection endpoint unreachable")
+			}
+			return nil, ErrInvalidToken
+		}),
+		Resource:             "https://mcp.example.com/mcp",
+		AuthorizationServers: []string{"https://auth.example.com"},
+		RequiredScopes:       []string{"mcp"},
+		ScopesSupported:      []string{"mcp"},
+		ResourceName:         "Example",
+	}
+}
+
+func sendWithToken(t *testing.T, method, url, token, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestStreamableHTTPServer_Auth(t *testing.T) {
+	mcpServer := NewMCPServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		claims, ok := TokenClaimsFromContext(ctx)
+		if !ok {
+			return nil, errors.New("no claims")
+		}
+		return mcp.NewToolResultText(claims.Subject), nil
+	})
+	server := NewTestStreamableHTTPServer(mcpServer, WithStreamableHTTPAuth(newTestResourceServerConfig()))
+	defer server.Close()
+	initialize := `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-03-26", "clientInfo": {"name": "test", "version": "1.0.0"}}}`
+
+	t.Run("protected resource metadata", func(t *testing.T) {
+		for _, path := range []string{"/.well-known/oauth-protected-resource", "/.well-known/oauth-protected-resource/mcp"} {
+			resp := sendWithToken(t, http.MethodGet, server.URL+path, "", "")
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode, path)
+			var metadata map[string]any
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&metadata))
+			assert.Equal(t, map[string]any{
+				"resource":                 "https://mcp.example.com/mcp",
+				"authorization_servers":    []any{"https://auth.example.com"},
+				"scopes_supported":         []any{"mcp"},
+				"bearer_methods_supported": []any{"header"},
+				"resource_name":            "Example",
+			}, metadata)
+		}
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		resp := sendWithToken(t, http.MethodPost, server.URL+"/mcp", "", initialize)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t,
+			`Bearer resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource/mcp"`,
+			resp.Header.Get("WWW-Authenticate"),
+		)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		for _, token := range []string{"mallory", "unreachable"} {
+			resp := sendWithToken(t, http.MethodPost, server.URL+"/mcp", token, initialize)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			assert.Contains(t, resp.Header.Get("WWW-Authenticate"), `error="invalid_token"`)
+			assert.NotContains(t, resp.Header.Get("WWW-Authenticate"), "unreachable", "internal errors aren't disclosed")
+		}
+	})
+
+	t.Run("insufficient scope", func(t *testing.T) {
+		resp := sendWithToken(t, http.MethodPost, server.URL+"/mcp", "bob", initialize)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("WWW-Authenticate"), `error="insufficient_scope"`)
+		assert.Contains(t, resp.Header.Get("WWW-Authenticate"), `scope="mcp"`)
+	})
+
+	t.Run("claims are available to handlers", func(t *testing.T) {
+		resp := sendWithToken(t, http.MethodPost, server.URL+"/mcp", "alice", initialize)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		sessionID := resp.Header.Get(headerKeySessionID)
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/mcp",
+			strings.NewReader(`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "whoami"}}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer alice")
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"text":"alice"`)
+	})
+}
+
+func TestSSEServer_Auth(t *testing.T) {
+	config := newTestResourceServerConfig()
+	config.Resource = ""
+	sseServer := NewSSEServer(NewMCPServer("test-server", "1.0.0"), WithSSEAuth(config))
+
+	recorder := httptest.NewRecorder()
+	sseServer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://mcp.example.com/sse", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t,
+		`Bearer resource_metadata="http://mcp.example.com/.well-known/oauth-protected-resource"`,
+		recorder.Header().Get("WWW-Authenticate"),
+	)
+
+	recorder = httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "http://mcp.example.com/message?sessionId=unknown", strings.NewReader(`{}`))
+	request.Header.Set("Authorization", "Bearer mallory")
+	sseServer.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	sseServer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://mcp.example.com/.well-known/oauth-protected-resource", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"resource":"http://mcp.example.com"`)
+}
+
+func TestSSEServer_AuthBindsSessions(t *testing.T) {
+	sseServer := NewSSEServer(NewMCPServer("test-server", "1.0.0"), WithSSEAuth(newTestResourceServerConfig()))
+	testServer := httptest.NewServer(sseServer)
+	sseServer.baseURL = testServer.URL
+	defer testServer.Close()
+	defer sseServer.Shutdown(context.Background())
+
+	sseResp := sendWithToken(t, http.MethodGet, testServer.URL+"/sse", "alice", "")
+	defer sseResp.Body.Close()
+	require.Equal(t, http.StatusOK, sseResp.StatusCode)
+	endpointEvent, err := readSSEEvent(sseResp)
+	require.NoError(t, err)
+	messageURL := strings.TrimSpace(strings.Split(strings.Split(endpointEvent, "data: ")[1], "\n")[0])
+
+	// the session can only be used with tokens of the subject that opened it
+	resp := sendWithToken(t, http.MethodPost, messageURL, "carol", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = sendWithToken(t, http.MethodPost, messageURL, "alice", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func TestStreamableHTTPServer_AuthBindsSessions(t *testing.T) {
+	// the replicas share the session store, so that the subject of the
+	// sessions is known to both
+	store := NewMemorySessionStore()
+	replicas := []*httptest.Server{
+		NewTestStreamableHTTPServer(NewMCPServer("test-server", "1.0.0"),
+			WithStreamableHTTPAuth(newTestResourceServerConfig()), WithSessionStore(store)),
+		NewTestStreamableHTTPServer(NewMCPServer("test-server", "1.0.0"),
+			WithStreamableHTTPAuth(newTestResourceServerConfig()), WithSessionStore(store)),
+	}
+	for _, replica := range replicas {
+		defer replica.Close()
+	}
+	send := func(method, url, token, sessionID, body string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	resp := sendWithToken(t, http.MethodPost, replicas[0].URL+"/mcp", "alice",
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-03-26", "clientInfo": {"name": "test", "version": "1.0.0"}}}`)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get(headerKeySessionID)
+	ping := `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+
+	// the session can only be used with tokens of the subject that initialized it
+	for _, replica := range replicas {
+		assert.Equal(t, http.StatusForbidden, send(http.MethodPost, replica.URL+"/mcp", "carol", sessionID, ping))
+		assert.Equal(t, http.StatusForbidden, send(http.MethodGet, replica.URL+"/mcp", "carol", sessionID, ""))
+		assert.Equal(t, http.StatusForbidden, send(http.MethodDelete, replica.URL+"/mcp", "carol", sessionID, ""))
+		assert.Equal(t, http.StatusOK, send(http.MethodPost, replica.URL+"/mcp", "alice", sessionID, ping))
+	}
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, replicas[1].URL+"/mcp", "alice", sessionID, ""))
+}
+
+func TestMCPServer_PrincipalFromTokenClaims(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	ctx := context.WithValue(context.Background(), tokenClaimsKey{}, &TokenClaims{Subject: "alice"})
+	assert.Equal(t, &Principal{ID: "alice"}, server.principal(ctx))
+	assert.Nil(t, server.principal(context.Background()))
+}
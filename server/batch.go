@@ -0,0 +1,121 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// isBatch reports whether the message is a JSON-RPC batch, i.e. a JSON array
+func isBatch(message []byte) bool {
+	message = bytes.TrimLeft(message, " \t\r\n")
+	return len(message) > 0 && message[0] == '['
+}
+
+// parseBatch splits a JSON-RPC batch into its messages. It returns the error
+// response to send instead if the batch is invalid.
+func parseBatch(message json.RawMessage) ([]json.RawMessage, mcp.JSONRPCMessage) {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(message, &messages); err != nil {
+		return nil, createErrorResponse(nil, mcp.PARSE_ERROR, "Failed to parse batch")
+	}
+	if len(messages) == 0 {
+		return nil, createErrorResponse(nil, mcp.INVALID_REQUEST, "Batch must not be empty")
+	}
+	return messages, nil
+}
+
+// batchHasRequests reports whether any of the messages of a batch is a request,
+// which has to be answered
+func batchHasRequests(messages []json.RawMessage) bool {
+	for _, message := range messages {
+		var baseMessage struct {
+			ID     any           `json:"id,omitempty"`
+			Method mcp.MCPMethod `json:"method"`
+		}
+		if err := json.Unmarshal(message, &baseMessage); err != nil || (baseMessage.ID != nil && baseMessage.Method != "") {
+			// invalid messages are answered with an error
+			return true
+		}
+	}
+	return false
+}
+
+// handleBatch processes a JSON-RPC batch and returns the responses to its requests,
+// or nil if the batch only holds notifications and responses.
+func (s *MCPServer) handleBatch(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+	messages, errResponse := parseBatch(message)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	responses := make([]mcp.JSONRPCMessage, len(messages))
+	s.handleBatchMessages(ctx, messages, func(i int, response mcp.JSONRPCMessage) {
+		responses[i] = response
+	})
+
+	batchResponse := make(mcp.JSONRPCBatchResponse, 0, len(responses))
+	for _, response := range responses {
+		if response != nil {
+			batchResponse = append(batchResponse, response)
+		}
+	}
+	if len(batchResponse) == 0 {
+		return nil
+	}
+	return batchResponse
+}
+
+// handleBatchMessages processes the messages of a JSON-RPC batch, and passes the
+// response to each of them to respond, along with the index of the message, as
+// soon as it is ready. It returns once every message has been processed.
+//
+// Notifications and responses are processed in order, since the order may be
+// significant (e.g. for notifications/cancelled). Requests are processed
+// concurrently, like requests received in separate messages, so respond may be
+// called concurrently. They are tracked before the next messages are processed,
+// so that they can be cancelled by notifications later in the batch. Initialize
+// requests are rejected, as the specification forbids batching them.
+func (s *MCPServer) handleBatchMessages(
+	ctx context.Context,
+	messages []json.RawMessage,
+	respond func(i int, response mcp.JSONRPCMessage),
+) {
+	var wg sync.WaitGroup
+	for i, message := range messages {
+		if isBatch(message) {
+			respond(i, createErrorResponse(nil, mcp.INVALID_REQUEST, "Batches must not be nested"))
+			continue
+		}
+
+		var baseMessage struct {
+			ID     any           `json:"id,omitempty"`
+			Method mcp.MCPMethod `json:"method"`
+		}
+		if err := json.Unmarshal(message, &baseMessage); err != nil || baseMessage.ID == nil || baseMessage.Method == "" {
+			// notifications, responses and invalid messages
+			respond(i, s.handleMessage(ctx, message))
+			continue
+		}
+		if baseMessage.Method == mcp.MethodInitialize {
+			respond(i, createErrorResponse(
+				baseMessage.ID,
+				mcp.INVALID_REQUEST,
+				"Initialize request must not be part of a batch",
+			))
+			continue
+		}
+
+		requestCtx, done := s.trackRequest(ctx, baseMessage.ID, baseMessage.Method)
+		wg.Add(1)
+		go func(i int, message json.RawMessage) {
+			defer wg.Done()
+			defer done()
+			respond(i, s.handleMessage(requestCtx, message))
+		}(i, message)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newBatchTestServer creates a server with a tool that only returns once two calls
+// of it are running, so that batches are only answered if they run concurrently
+func newBatchTestServer() *MCPServer {
+	server := NewMCPServer("test-server", "1.0.0")
+	rendezvous := make(chan struct{})
+	server.AddTool(mcp.NewTool("wait"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case rendezvous <- struct{}{}:
+		case <-rendezvous:
+		case <-time.After(5 * time.Second):
+			return nil, context.DeadlineExceeded
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	return server
+}
+
+const testBatch = `[
+	{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "wait"}},
+	{"jsonrpc": "2.0", "method": "notifications/initialized"},
+	{"jsonrpc": "2.0", "id": "two", "method": "tools/call", "params": {"name": "wait"}},
+	{"jsonrpc": "2.0", "id": 3, "method": "ping"}
+]`
+
+func TestMCPServer_HandleBatch(t *testing.T) {
+	t.Run("requests are answered in order", func(t *testing.T) {
+		server := newBatchTestServer()
+		response := server.HandleMessage(context.Background(), []byte(testBatch))
+		batch, ok := response.(mcp.JSONRPCBatchResponse)
+		require.True(t, ok, "expected a batch response, got %#v", response)
+		require.Len(t, batch, 3)
+
+		var ids []mcp.RequestId
+		for _, response := range batch {
+			response, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok, "expected a successful response, got %#v", response)
+			ids = append(ids, response.ID)
+		}
+		assert.Equal(t, []mcp.RequestId{mcp.NewRequestId(float64(1)), mcp.NewRequestId("two"), mcp.NewRequestId(float64(3))}, ids)
+	})
+
+	t.Run("batch of notifications has no response", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		response := server.HandleMessage(context.Background(), []byte(`[
+			{"jsonrpc": "2.0", "method": "notifications/initialized"},
+			{"jsonrpc": "2.0", "method": "notifications/roots/list_changed"}
+		]`))
+		assert.Nil(t, response)
+	})
+
+	t.Run("invalid batches", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			message string
+			code    int
+		}{
+			{name: "empty batch", message: `[]`, code: mcp.INVALID_REQUEST},
+			{name: "malformed batch", message: `[{"jsonrpc": "2.0",`, code: mcp.PARSE_ERROR},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				server := NewMCPServer("test-server", "1.0.0")
+				response, ok := server.HandleMessage(context.Background(), []byte(tt.message)).(mcp.JSONRPCError)
+				require.True(t, ok)
+				assert.Equal(t, tt.code, response.Error.Code)
+			})
+		}
+	})
+
+	t.Run("requests can be cancelled later in the batch", func(t *testing.T) {
+		server, _, causes := newBlockingToolServer()
+		session := &sessionTestClient{sessionID: "session-1", initialized: true}
+		ctx := server.WithContext(context.Background(), session)
+
+		response := server.HandleMessage(ctx, []byte(`[`+string(callToolMessage(1, "block"))+`,`+string(cancelledMessage(1, "user aborted"))+`]`))
+		assert.Nil(t, response, "the response of a cancelled request must not be sent")
+		assert.ErrorIs(t, <-causes, ErrRequestCancelled)
+		assert.Empty(t, server.inFlight.sessions)
+	})
+
+	t.Run("invalid batch entries", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		response := server.HandleMessage(context.Background(), []byte(`[
+			{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-06-18"}},
+			[{"jsonrpc": "2.0", "id": 2, "method": "ping"}],
+			{"jsonrpc": "1.0", "id": 3, "method": "ping"},
+			{"jsonrpc": "2.0", "id": 4, "method": "ping"}
+		]`))
+		batch, ok := response.(mcp.JSONRPCBatchResponse)
+		require.True(t, ok)
+		require.Len(t, batch, 4)
+		for i, code := range []int{mcp.INVALID_REQUEST, mcp.INVALID_REQUEST, mcp.INVALID_REQUEST} {
+			response, ok := batch[i].(mcp.JSONRPCError)
+			require.True(t, ok, "entry %d should be an error", i)
+			assert.Equal(t, code, response.Error.Code)
+		}
+		assert.IsType(t, mcp.JSONRPCResponse{}, batch[3])
+	})
+}
+
+func TestStdioServer_Batch(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = NewStdioServer(newBatchTestServer()).Listen(ctx, stdinReader, stdoutWriter)
+	}()
+
+	_, err := stdinWriter.Write([]byte(strings.ReplaceAll(testBatch, "\n", "") + "\n"))
+	require.NoError(t, err)
+
+	scanner := bufio.NewScanner(stdoutReader)
+	require.True(t, scanner.Scan())
+	var responses []map[string]any
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &responses))
+	require.Len(t, responses, 3)
+	assert.Equal(t, float64(1), responses[0]["id"])
+	assert.Equal(t, "two", responses[1]["id"])
+	assert.Equal(t, float64(3), responses[2]["id"])
+}
+
+func TestStreamableHTTP_Batch(t *testing.T) {
+	server := NewTestStreamableHTTPServer(newBatchTestServer(), WithStateLess(true))
+	defer server.Close()
+
+	post := func(t *testing.T, accept string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(testBatch))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", accept)
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("json response", func(t *testing.T) {
+		resp := post(t, "application/json")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		var responses []map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&responses))
+		require.Len(t, responses, 3)
+		assert.Equal(t, float64(1), responses[0]["id"])
+	})
+
+	t.Run("sse response", func(t *testing.T) {
+		resp := post(t, "application/json, text/event-stream")
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		ids := map[any]bool{}
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var response map[string]any
+			require.NoError(t, json.Unmarshal([]byte(data), &response))
+			ids[response["id"]] = true
+		}
+		assert.Equal(t, map[any]bool{float64(1): true, "two": true, float64(3): true}, ids)
+	})
+
+	t.Run("batch of notifications", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(
+			`[{"jsonrpc": "2.0", "method": "notifications/initialized"}]`,
+		))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	})
+}
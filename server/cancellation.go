@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// inFlightRequest is a request being handled, which can be cancelled by the client
+type inFlightRequest struct {
+	cancel context.CancelCauseFunc
+}
+
+// inFlightRequests keeps track of the requests being handled for each session.
+// The zero value is ready to use.
+type inFlightRequests struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]*inFlightRequest // sessionID -> request ID -> request
+}
+
+func (r *inFlightRequests) add(sessionID, requestID string, request *inFlightRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sessions == nil {
+		r.sessions = make(map[string]map[string]*inFlightRequest)
+	}
+	if r.sessions[sessionID] == nil {
+		r.sessions[sessionID] = make(map[string]*inFlightRequest)
+	}
+	r.sessions[sessionID][requestID] = request
+}
+
+func (r *inFlightRequests) remove(sessionID, requestID string, request *inFlightRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// the client may have reused the ID for a newer request
+	if r.sessions[sessionID][requestID] != request {
+		return
+	}
+	delete(r.sessions[sessionID], requestID)
+	if len(r.sessions[sessionID]) == 0 {
+		delete(r.sessions, sessionID)
+	}
+}
+
+// cancel cancels the request with the given ID, and reports whether it was in flight
+func (r *inFlightRequests) cancel(sessionID, requestID string, cause error) bool {
+	r.mu.Lock()
+	request, ok := r.sessions[sessionID][requestID]
+	r.mu.Unlock()
+	if ok {
+		request.cancel(cause)
+	}
+	return ok
+}
+
+// trackedRequestKey is the context key of the ID of the request tracked by a
+// context, so that requests tracked before they are handled, like those of
+// batches, aren't tracked again
+type trackedRequestKey struct{}
+
+// trackRequest derives the context a request is handled with, which is cancelled
+// when the client sends notifications/cancelled for the request. done must be
+// called once the request has been handled.
+//
+// Requests without a session ID, like those of stateless servers, can't be
+// cancelled: they can come from any client, which could cancel the requests of
+// the others.
+func (s *MCPServer) trackRequest(ctx context.Context, id any, method mcp.MCPMethod) (context.Context, func()) {
+	sessionID := requestSessionID(ctx)
+	// the client must not cancel its initialize request
+	if method == mcp.MethodInitialize || sessionID == "" {
+		return ctx, func() {}
+	}
+
+	requestID := mcp.NewRequestId(id).String()
+	if tracked, ok := ctx.Value(trackedRequestKey{}).(string); ok && tracked == requestID {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(context.WithValue(ctx, trackedRequestKey{}, requestID))
+	request := &inFlightRequest{cancel: cancel}
+	s.inFlight.add(sessionID, requestID, request)
+	return ctx, func() {
+		s.inFlight.remove(sessionID, requestID, request)
+		cancel(nil)
+	}
+}
+
+// handleCancelled cancels the context of the request referenced by a
+// notifications/cancelled notification. Unknown or already completed requests
+// are ignored, as the notification may arrive after the response was sent.
+func (s *MCPServer) handleCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	data, err := json.Marshal(notification.Params.AdditionalFields)
+	if err != nil {
+		return
+	}
+	var params mcp.CancelledNotificationParams
+	if err := json.Unmarshal(data, &params); err != nil || params.RequestId.IsNil() {
+		return
+	}
+
+	sessionID := requestSessionID(ctx)
+	if sessionID == "" {
+		return
+	}
+	cause := ErrRequestCancelled
+	if params.Reason != "" {
+		cause = fmt.Errorf("%w: %s", ErrRequestCancelled, params.Reason)
+	}
+	s.inFlight.cancel(sessionID, params.RequestId.String(), cause)
+}
+
+// requestSessionID returns the ID of the session in ctx, or an empty string
+// if there is none
+func requestSessionID(ctx context.Context) string {
+	if session := ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
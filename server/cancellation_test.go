@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func callToolMessage(id any, name string) []byte {
+	idBytes, _ := json.Marshal(id)
+	return []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "method": "tools/call", "params": {"name": %q}}`, idBytes, name))
+}
+
+func cancelledMessage(id any, reason string) []byte {
+	idBytes, _ := json.Marshal(id)
+	return []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": %s, "reason": %q}}`, idBytes, reason))
+}
+
+// newBlockingToolServer creates a server with a tool that blocks until its
+// context is done, and reports the cause to the returned channel
+func newBlockingToolServer() (*MCPServer, chan struct{}, chan error) {
+	started := make(chan struct{}, 1)
+	causes := make(chan error, 1)
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddTool(mcp.NewTool("block"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started <- struct{}{}
+		select {
+		case <-ctx.Done():
+			causes <- context.Cause(ctx)
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			causes <- nil
+			return mcp.NewToolResultText("done"), nil
+		}
+	})
+	return server, started, causes
+}
+
+func TestMCPServer_Cancellation(t *testing.T) {
+	tests := []struct {
+		name string
+		id   any
+	}{
+		{name: "numeric request id", id: 7},
+		{name: "string request id", id: "request-7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, started, causes := newBlockingToolServer()
+			session := &sessionTestClient{sessionID: "session-1", initialized: true}
+			ctx := server.WithContext(context.Background(), session)
+
+			responses := make(chan mcp.JSONRPCMessage, 1)
+			go func() {
+				responses <- server.HandleMessage(ctx, callToolMessage(tt.id, "block"))
+			}()
+			<-started
+
+			assert.Nil(t, server.HandleMessage(ctx, cancelledMessage(tt.id, "user aborted")))
+
+			select {
+			case cause := <-causes:
+				assert.True(t, errors.Is(cause, ErrRequestCancelled), "unexpected cause: %v", cause)
+				assert.ErrorContains(t, cause, "user aborted")
+			case <-time.After(2 * time.Second):
+				t.Fatal("tool handler was not cancelled")
+			}
+			assert.Nil(t, <-responses, "the response of a cancelled request must not be sent")
+			assert.Empty(t, server.inFlight.sessions)
+		})
+	}
+
+	t.Run("other sessions cannot cancel the request", func(t *testing.T) {
+		server, started, causes := newBlockingToolServer()
+		ctx := server.WithContext(context.Background(), &sessionTestClient{sessionID: "session-1", initialized: true})
+		otherCtx := server.WithContext(context.Background(), &sessionTestClient{sessionID: "session-2", initialized: true})
+
+		responses := make(chan mcp.JSONRPCMessage, 1)
+		go func() {
+			responses <- server.HandleMessage(ctx, callToolMessage(1, "block"))
+		}()
+		<-started
+
+		server.HandleMessage(otherCtx, cancelledMessage(1, ""))
+		select {
+		case cause := <-causes:
+			t.Fatalf("tool handler was cancelled: %v", cause)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		server.HandleMessage(ctx, cancelledMessage(1, ""))
+		assert.ErrorIs(t, <-causes, ErrRequestCancelled)
+		assert.Nil(t, <-responses)
+	})
+
+	t.Run("requests without a session ID cannot be cancelled", func(t *testing.T) {
+		// e.g. two clients of a stateless server using the same request ID
+		server, started, causes := newBlockingToolServer()
+		ctx := server.WithContext(context.Background(), &sessionTestClient{initialized: true})
+		otherCtx := server.WithContext(context.Background(), &sessionTestClient{initialized: true})
+
+		go server.HandleMessage(ctx, callToolMessage(1, "block"))
+		<-started
+		assert.Empty(t, server.inFlight.sessions)
+
+		server.HandleMessage(otherCtx, cancelledMessage(1, ""))
+		select {
+		case cause := <-causes:
+			t.Fatalf("tool handler was cancelled: %v", cause)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("unknown requests are ignored", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		assert.Nil(t, server.HandleMessage(context.Background(), cancelledMessage(42, "")))
+		assert.Nil(t, server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {}}`)))
+	})
+
+	t.Run("cancellation still reaches notification handlers", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		received := make(chan mcp.JSONRPCNotification, 1)
+		server.AddNotificationHandler(mcp.MethodNotificationCancelled, func(ctx context.Context, notification mcp.JSONRPCNotification) {
+			received <- notification
+		})
+		server.HandleMessage(context.Background(), cancelledMessage(42, "timeout"))
+		notification := <-received
+		assert.Equal(t, "timeout", notification.Params.AdditionalFields["reason"])
+	})
+
+	t.Run("completed requests are untracked", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`))
+		require.IsType(t, mcp.JSONRPCResponse{}, response)
+		assert.Empty(t, server.inFlight.sessions)
+	})
+}
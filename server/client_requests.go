@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// clientResponse is the outcome of a request sent to the client
+type clientResponse struct {
+	result json.RawMessage
+	err    error
+}
+
+// pendingRequests correlates requests sent from the server to the client with the
+// responses the client sends back later on the same session.
+// The zero value is ready to use.
+type pendingRequests struct {
+	mu      sync.Mutex
+	waiting map[string]chan clientResponse // request id -> response
+}
+
+// send registers the request, hands it to write and blocks until the matching
+// response is delivered or ctx is done.
+func (p *pendingRequests) send(
+	ctx context.Context,
+	request mcp.JSONRPCRequest,
+	write func(mcp.JSONRPCRequest) error,
+) (json.RawMessage, error) {
+	key := request.ID.String()
+	responseChan := make(chan clientResponse, 1)
+
+	p.mu.Lock()
+	if p.waiting == nil {
+		p.waiting = make(map[string]chan clientResponse)
+	}
+	p.waiting[key] = responseChan
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.waiting, key)
+		p.mu.Unlock()
+	}()
+
+	if err := write(request); err != nil {
+		return nil, err
+	}
+
+	select {
+	case response := <-responseChan:
+		return response.result, response.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// deliver hands the response to the request waiting for it.
+// It returns false if no request with the given ID is pending.
+func (p *pendingRequests) deliver(id mcp.RequestId, result json.RawMessage, err error) bool {
+	key := id.String()
+
+	p.mu.Lock()
+	responseChan, ok := p.waiting[key]
+	delete(p.waiting, key)
+	p.mu.Unlock()
+
+	if ok {
+		responseChan <- clientResponse{result: result, err: err}
+	}
+	return ok
+}
+
+// cancelAll fails every pending request with err, e.g. when the session is closed.
+func (p *pendingRequests) cancelAll(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, responseChan := range p.waiting {
+		responseChan <- clientResponse{err: err}
+		delete(p.waiting, key)
+	}
+}
+
+// newClientRequest builds a JSON-RPC request sent from the server to the client
+func newClientRequest(id int64, method mcp.MCPMethod, params any) mcp.JSONRPCRequest {
+	return mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(id),
+		Params:  params,
+		Request: mcp.Request{
+			Method: string(method),
+		},
+	}
+}
+
+// sendClientRequest sends a request to the client of the session in ctx and waits for its result.
+func (s *MCPServer) sendClientRequest(
+	ctx context.Context,
+	method mcp.MCPMethod,
+	params any,
+) (json.RawMessage, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoActiveSession
+	}
+
+	sessionWithRequests, ok := session.(SessionWithRequests)
+	if !ok {
+		return nil, ErrSessionDoesNotSupportRequests
+	}
+
+	return sessionWithRequests.SendRequest(ctx, method, params)
+}
+
+// handleClientResponse routes a response received from the client to the request
+// of the current session that is waiting for it. Responses nobody waits for, like
+// those to keep-alive pings, are dropped.
+func (s *MCPServer) handleClientResponse(ctx context.Context, message json.RawMessage) {
+	sessionWithRequests, ok := ClientSessionFromContext(ctx).(SessionWithRequests)
+	if !ok {
+		return
+	}
+
+	var response struct {
+		ID     mcp.RequestId   `json:"id"`
+		Result json.RawMessage `json:"result,omitempty"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Data    any    `json:"data,omitempty"`
+		} `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(message, &response); err != nil {
+		return
+	}
+
+	var err error
+	if response.Error != nil {
+		err = &ClientRequestError{
+			Code:    response.Error.Code,
+			Message: response.Error.Message,
+			Data:    response.Error.Data,
+		}
+	}
+	sessionWithRequests.HandleResponse(response.ID, response.Result, err)
+}
+
+// clientCapabilitiesFromSession returns the capabilities the client advertised
+// during initialization, if the session keeps track of them.
+func clientCapabilitiesFromSession(session ClientSession) (mcp.ClientCapabilities, bool) {
+	sessionWithCapabilities, ok := session.(SessionWithClientCapabilities)
+	if !ok {
+		return mcp.ClientCapabilities{}, false
+	}
+	return sessionWithCapabilities.GetClientCapabilities(), true
+}
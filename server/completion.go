@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yosida95/uritemplate/v3"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxCompletionValues is the maximum number of values of a completion result
+const maxCompletionValues = 100
+
+// CompletionProviderFunc returns completion options for an argument of a prompt
+// or resource template. request.Params.Argument holds the name of the argument
+// and the value typed so far.
+type CompletionProviderFunc func(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error)
+
+// AddPromptCompletionProvider registers a completion provider for the arguments
+// of the prompt with the given name
+func (s *MCPServer) AddPromptCompletionProvider(promptName string, provider CompletionProviderFunc) {
+	s.implicitlyRegisterCompletionCapabilities()
+
+	s.completionsMu.Lock()
+	defer s.completionsMu.Unlock()
+	s.promptCompletions[promptName] = provider
+}
+
+// resourceCompletion is the completion provider of a resource template
+type resourceCompletion struct {
+	// template is the parsed URI template, nil if it's invalid, in which case
+	// the provider only completes references to the raw template
+	template *mcp.URITemplate
+	provider CompletionProviderFunc
+}
+
+// AddResourceCompletionProvider registers a completion provider for the variables
+// of the resource template with the given URI template
+func (s *MCPServer) AddResourceCompletionProvider(uriTemplate string, provider CompletionProviderFunc) {
+	s.implicitlyRegisterCompletionCapabilities()
+
+	completion := resourceCompletion{provider: provider}
+	if template, err := uritemplate.New(uriTemplate); err == nil {
+		completion.template = &mcp.URITemplate{Template: template}
+	}
+
+	s.completionsMu.Lock()
+	defer s.completionsMu.Unlock()
+	s.resourceCompletions[uriTemplate] = completion
+	s.completionIndex.Store(nil)
+}
+
+// DeletePromptCompletionProvider removes the completion provider of a prompt
+func (s *MCPServer) DeletePromptCompletionProvider(promptName string) {
+	s.completionsMu.Lock()
+	defer s.completionsMu.Unlock()
+	delete(s.promptCompletions, promptName)
+}
+
+// DeleteResourceCompletionProvider removes the completion provider of a resource template
+func (s *MCPServer) DeleteResourceCompletionProvider(uriTemplate string) {
+	s.completionsMu.Lock()
+	defer s.completionsMu.Unlock()
+	delete(s.resourceCompletions, uriTemplate)
+	s.completionIndex.Store(nil)
+}
+
+// resourceCompletionIndex returns the index of the templates of the resource
+// completion providers, building it if they changed since it was last built.
+// It must be called with completionsMu held.
+func (s *MCPServer) resourceCompletionIndex() *templateIndex {
+	if index := s.completionIndex.Load(); index != nil {
+		return index
+	}
+	templates := make([]ServerResourceTemplate, 0, len(s.resourceCompletions))
+	for _, completion := range s.resourceCompletions {
+		if completion.template != nil {
+			templates = append(templates, ServerResourceTemplate{
+				Template: mcp.ResourceTemplate{URITemplate: completion.template},
+			})
+		}
+	}
+	index := newTemplateIndex(templates)
+	s.completionIndex.Store(index)
+	return index
+}
+
+// completionReference is the prompt or resource whose arguments are completed
+type completionReference struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+}
+
+func parseCompletionReference(ref any) (completionReference, error) {
+	var reference completionReference
+	refBytes, err := json.Marshal(ref)
+	if err != nil {
+		return reference, err
+	}
+	if err := json.Unmarshal(refBytes, &reference); err != nil {
+		return reference, err
+	}
+	switch reference.Type {
+	case mcp.RefTypePrompt, mcp.RefTypeResource:
+		return reference, nil
+	default:
+		return reference, fmt.Errorf("unknown reference type %q", reference.Type)
+	}
+}
+
+// completionProvider returns the provider for the reference, or nil if there is none
+func (s *MCPServer) completionProvider(reference completionReference) CompletionProviderFunc {
+	s.completionsMu.RLock()
+	defer s.completionsMu.RUnlock()
+
+	if reference.Type == mcp.RefTypePrompt {
+		return s.promptCompletions[reference.Name]
+	}
+	if completion, ok := s.resourceCompletions[reference.URI]; ok {
+		return completion.provider
+	}
+	// the reference may also be a URI expanded from a template, matched
+	// like resources/read does
+	if template, ok := s.resourceCompletionIndex().match(reference.URI); ok {
+		return s.resourceCompletions[template.Template.URITemplate.Raw()].provider
+	}
+	return nil
+}
+
+func (s *MCPServer) handleComplete(
+	ctx context.Context,
+	id any,
+	request mcp.CompleteRequest,
+) (*mcp.CompleteResult, *requestError) {
+	reference, err := parseCompletionReference(request.Params.Ref)
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("invalid completion reference: %w", err),
+		}
+	}
+
+	// completions may disclose what the prompt or resource would, so they
+	// require the same scopes
+	if reference.Type == mcp.RefTypePrompt {
+		err = s.authorize(ctx, "prompt", reference.Name, s.promptScopes(ctx, reference.Name))
+	} else {
+		err = s.authorize(ctx, "resource", reference.URI, s.resourceScopes(ctx, reference.URI))
+	}
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.FORBIDDEN,
+			err:  err,
+		}
+	}
+
+	provider := s.completionProvider(reference)
+	result := &mcp.CompleteResult{}
+	if provider != nil {
+		result, err = provider(ctx, request)
+		if err != nil {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INTERNAL_ERROR,
+				err:  err,
+			}
+		}
+		if result == nil {
+			result = &mcp.CompleteResult{}
+		}
+	}
+
+	// values must always be an array, with at most 100 items
+	if result.Completion.Values == nil {
+		result.Completion.Values = []string{}
+	}
+	if len(result.Completion.Values) > maxCompletionValues {
+		if result.Completion.Total == 0 {
+			result.Completion.Total = len(result.Completion.Values)
+		}
+		result.Completion.Values = result.Completion.Values[:maxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	return result, nil
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func completeMessage(ref string, argumentName, argumentValue string) []byte {
+	return []byte(fmt.Sprintf(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "completion/complete",
+		"params": {
+			"ref": %s,
+			"argument": {"name": %q, "value": %q}
+		}
+	}`, ref, argumentName, argumentValue))
+}
+
+func prefixCompletion(options ...string) CompletionProviderFunc {
+	return func(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		result := &mcp.CompleteResult{}
+		for _, option := range options {
+			if strings.HasPrefix(option, request.Params.Argument.Value) {
+				result.Completion.Values = append(result.Completion.Values, option)
+			}
+		}
+		return result, nil
+	}
+}
+
+func TestMCPServer_Complete(t *testing.T) {
+	t.Run("not supported without completions capability", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		response := server.HandleMessage(context.Background(), completeMessage(`{"type": "ref/prompt", "name": "greet"}`, "name", "a"))
+		errorResponse, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok, "expected error response, got %T", response)
+		assert.Equal(t, mcp.METHOD_NOT_FOUND, errorResponse.Error.Code)
+	})
+
+	t.Run("capability is advertised", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithCompletions())
+		response := server.HandleMessage(context.Background(), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "initialize",
+			"params": {"protocolVersion": "2025-03-26", "clientInfo": {"name": "test", "version": "1.0.0"}}
+		}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		result, ok := resp.Result.(mcp.InitializeResult)
+		require.True(t, ok)
+		assert.NotNil(t, result.Capabilities.Completions)
+	})
+
+	t.Run("adding a provider enables the capability", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		server.AddPromptCompletionProvider("greet", prefixCompletion("alice", "bob"))
+		assert.NotNil(t, server.capabilities.completions)
+	})
+
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddPromptCompletionProvider("greet", prefixCompletion("alice", "alfred", "bob"))
+	server.AddResourceCompletionProvider("file:///{path}", prefixCompletion("docs", "downloads", "src"))
+	server.AddResourceCompletionProvider("users://{id}/profile", prefixCompletion("id"))
+	server.AddResourceCompletionProvider("users://me/{field}", prefixCompletion("field"))
+	server.AddResourceCompletionProvider("users://{+path}", prefixCompletion("path"))
+	server.AddPromptCompletionProvider("many", func(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		result := &mcp.CompleteResult{}
+		for i := range 150 {
+			result.Completion.Values = append(result.Completion.Values, fmt.Sprint(i))
+		}
+		return result, nil
+	})
+	server.AddPromptCompletionProvider("failing", func(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		return nil, errors.New("provider failed")
+	})
+
+	tests := []struct {
+		name      string
+		message   []byte
+		errorCode int
+		validate  func(t *testing.T, result mcp.CompleteResult)
+	}{
+		{
+			name:    "prompt argument",
+			message: completeMessage(`{"type": "ref/prompt", "name": "greet"}`, "name", "al"),
+			validate: func(t *testing.T, result mcp.CompleteResult) {
+				assert.Equal(t, []string{"alice", "alfred"}, result.Completion.Values)
+				assert.False(t, result.Completion.HasMore)
+			},
+		},
+		{
+			name:    "resource template variable",
+			message: completeMessage(`{"type": "ref/resource", "uri": "file:///{path}"}`, "path", "d"),
+			validate: func(t *testing.T, result mcp.CompleteResult) {
+				assert.Equal(t, []string{"docs", "downloads"}, result.Completion.Values)
+			},
+		},
+		{
+			name:    "resource uri matching a template",
+			message: completeMessage(`{"type": "ref/resource", "uri": "file:///src"}`, "path", "s"),
+			validate: func(t *testing.T, result mcp.CompleteResult) {
+				assert.Equal(t, []string{"src"}, result.Completion.Values)
+			},
+		},
+		{
+			name:    "resource uri matching several templates",
+			message: completeMessage(`{"type": "ref/resource", "uri": "users://me/profile"}`, "field", ""),
+			validate: func(t *testing.T, result mcp.CompleteResult) {
+				// the most specific template wins, like for resources/read
+				assert.Equal(t, []string{"field"}, result.Completion.Values)
+			},
+		},
+		{
+			name:    "no provider returns no values",
+			message: completeMessage(`{"type": "ref/prompt", "name": "unknown"}`, "name", "a"),
+			validate: func(t *testing.T, result mcp.CompleteResult) {
+				assert.NotNil(t, result.Completion.Values)
+				assert.Empty(t, result.Completion.Values)
+			},
+		},
+		{
+			name:    "values are limited to 100",
+			message: completeMessage(`{"type": "ref/prompt", "name": "many"}`, "n", ""),
+			validate: func(t *testing.T, result mcp.CompleteResult) {
+				assert.Len(t, result.Completion.Values, 100)
+				assert.Equal(t, 150, result.Completion.Total)
+				assert.True(t, result.Completion.HasMore)
+			},
+		},
+		{
+			name:      "unknown reference type",
+			message:   completeMessage(`{"type": "ref/unknown"}`, "name", "a"),
+			errorCode: mcp.INVALID_PARAMS,
+		},
+		{
+			name:      "provider error",
+			message:   completeMessage(`{"type": "ref/prompt", "name": "failing"}`, "name", "a"),
+			errorCode: mcp.INTERNAL_ERROR,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := server.HandleMessage(context.Background(), tt.message)
+			if tt.errorCode != 0 {
+				errorResponse, ok := response.(mcp.JSONRPCError)
+				require.True(t, ok, "expected error response, got %T", response)
+				assert.Equal(t, tt.errorCode, errorResponse.Error.Code)
+				return
+			}
+
+			resp, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok, "expected response, got %+v", response)
+
+			// check the result as the client sees it
+			data, err := json.Marshal(resp.Result)
+			require.NoError(t, err)
+			var result mcp.CompleteResult
+			require.NoError(t, json.Unmarshal(data, &result))
+			tt.validate(t, result)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RequestElicitation sends an elicitation/create request to the client of the
+// session in ctx, and waits for the user to accept, decline or cancel it.
+// It is meant to be called from within a handler, for example:
+//
+//	result, err := server.ServerFromContext(ctx).RequestElicitation(ctx, mcp.ElicitRequest{
+//		Params: mcp.ElicitationParams{
+//			Message: "Which environment should be deployed?",
+//			RequestedSchema: mcp.NewElicitationSchema(map[string]mcp.PrimitiveSchema{
+//				"environment": {Type: "string", Enum: []string{"staging", "production"}},
+//			}, "environment"),
+//		},
+//	})
+//
+// The requested schema is validated before the request is sent, and the
+// content of an accepted result is validated against it.
+//
+// It fails with ErrElicitationNotSupported if the client did not advertise
+// the elicitation capability during initialization.
+func (s *MCPServer) RequestElicitation(
+	ctx context.Context,
+	request mcp.ElicitRequest,
+) (*mcp.ElicitResult, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoActiveSession
+	}
+
+	capabilities, ok := clientCapabilitiesFromSession(session)
+	if !ok || capabilities.Elicitation == nil {
+		return nil, ErrElicitationNotSupported
+	}
+
+	schema := request.Params.RequestedSchema
+	if err := schema.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid requested schema: %w", err)
+	}
+
+	response, err := s.sendClientRequest(ctx, mcp.MethodElicitationCreate, request.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.ElicitResult
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal elicitation result: %w", err)
+	}
+
+	switch result.Action {
+	case mcp.ElicitationResponseActionAccept:
+		if err := schema.ValidateContent(result.Content); err != nil {
+			return nil, fmt.Errorf("invalid elicitation content: %w", err)
+		}
+	case mcp.ElicitationResponseActionDecline, mcp.ElicitationResponseActionCancel:
+		result.Content = nil
+	default:
+		return nil, fmt.Errorf("unknown elicitation action %q", result.Action)
+	}
+	return &result, nil
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newTestElicitRequest() mcp.ElicitRequest {
+	return mcp.ElicitRequest{
+		Params: mcp.ElicitationParams{
+			Message: "What is your name?",
+			RequestedSchema: mcp.NewElicitationSchema(map[string]mcp.PrimitiveSchema{
+				"name": {Type: "string"},
+			}, "name"),
+		},
+	}
+}
+
+// answerElicitation answers the next request sent to the session with the given result
+func answerElicitation(t *testing.T, server *MCPServer, ctx context.Context, session *sessionTestClientWithRequests, result string) {
+	go func() {
+		request := <-session.requests
+		assert.Equal(t, string(mcp.MethodElicitationCreate), request.Method)
+		response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": %s}`, mustMarshal(t, request.ID), result)
+		assert.Nil(t, server.HandleMessage(ctx, []byte(response)))
+	}()
+}
+
+func TestMCPServer_RequestElicitation(t *testing.T) {
+	elicitationCapabilities := mcp.ClientCapabilities{Elicitation: &struct{}{}}
+
+	t.Run("no session in context", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		_, err := server.RequestElicitation(context.Background(), newTestElicitRequest())
+		assert.ErrorIs(t, err, ErrNoActiveSession)
+	})
+
+	t.Run("client does not support elicitation", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(mcp.ClientCapabilities{})
+		ctx := server.WithContext(context.Background(), session)
+
+		_, err := server.RequestElicitation(ctx, newTestElicitRequest())
+		assert.ErrorIs(t, err, ErrElicitationNotSupported)
+		assert.Empty(t, session.requests, "no request should be sent to the client")
+	})
+
+	t.Run("invalid schema is not sent", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(elicitationCapabilities)
+		ctx := server.WithContext(context.Background(), session)
+
+		request := newTestElicitRequest()
+		request.Params.RequestedSchema.Properties["address"] = mcp.PrimitiveSchema{Type: "object"}
+		_, err := server.RequestElicitation(ctx, request)
+		assert.ErrorContains(t, err, "invalid requested schema")
+		assert.Empty(t, session.requests, "no request should be sent to the client")
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(elicitationCapabilities)
+		ctx := server.WithContext(context.Background(), session)
+
+		answerElicitation(t, server, ctx, session, `{"action": "accept", "content": {"name": "Ann"}}`)
+		result, err := server.RequestElicitation(ctx, newTestElicitRequest())
+		require.NoError(t, err)
+		assert.Equal(t, mcp.ElicitationResponseActionAccept, result.Action)
+		assert.Equal(t, map[string]any{"name": "Ann"}, result.Content)
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(elicitationCapabilities)
+		ctx := server.WithContext(context.Background(), session)
+
+		answerElicitation(t, server, ctx, session, `{"action": "decline"}`)
+		result, err := server.RequestElicitation(ctx, newTestElicitRequest())
+		require.NoError(t, err)
+		assert.Equal(t, mcp.ElicitationResponseActionDecline, result.Action)
+		assert.Nil(t, result.Content)
+	})
+
+	t.Run("content not matching the schema", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(elicitationCapabilities)
+		ctx := server.WithContext(context.Background(), session)
+
+		answerElicitation(t, server, ctx, session, `{"action": "accept", "content": {"name": 42}}`)
+		_, err := server.RequestElicitation(ctx, newTestElicitRequest())
+		assert.ErrorContains(t, err, "invalid elicitation content")
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(elicitationCapabilities)
+		ctx := server.WithContext(context.Background(), session)
+
+		answerElicitation(t, server, ctx, session, `{"action": "maybe"}`)
+		_, err := server.RequestElicitation(ctx, newTestElicitRequest())
+		assert.ErrorContains(t, err, `unknown elicitation action "maybe"`)
+	})
+}
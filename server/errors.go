@@ -3,6 +3,8 @@ package server
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 var (
@@ -13,17 +15,171 @@ var (
 	ErrToolNotFound     = errors.New("tool not found")
 
 	// Session-related errors
-	ErrSessionNotFound              = errors.New("session not found")
-	ErrSessionExists                = errors.New("session already exists")
-	ErrSessionNotInitialized        = errors.New("session not properly initialized")
-	ErrSessionDoesNotSupportTools   = errors.New("session does not support per-session tools")
-	ErrSessionDoesNotSupportLogging = errors.New("session does not support setting logging level")
+	ErrSessionNotFound                = errors.New("session not found")
+	ErrSessionExists                  = errors.New("session already exists")
+	ErrSessionNotInitialized          = errors.New("session not properly initialized")
+	ErrSessionDoesNotSupportTools     = errors.New("session does not support per-session tools")
+	ErrSessionDoesNotSupportPrompts   = errors.New("session does not support per-session prompts")
+	ErrSessionDoesNotSupportResources = errors.New("session does not support per-session resources")
+	ErrSessionDoesNotSupportLogging   = errors.New("session does not support setting logging level")
+	ErrSessionDoesNotSupportRequests  = errors.New("session does not support server-to-client requests")
+	ErrSessionClosed                  = errors.New("session closed")
+	ErrNoActiveSession                = errors.New("no active session in context")
+	ErrNoStreamForRequest             = errors.New("no open stream to send the request to the client")
+	ErrUnknownEventID                 = errors.New("unknown event ID")
+	ErrSessionConflict                = errors.New("session changed concurrently")
+
+	// Client capability errors
+	ErrSamplingNotSupported    = errors.New("client does not support sampling")
+	ErrRootsNotSupported       = errors.New("client does not support roots")
+	ErrElicitationNotSupported = errors.New("client does not support elicitation")
+
+	// Request-related errors
+	ErrRequestCancelled = errors.New("request cancelled by the client")
+	ErrRateLimited      = errors.New("rate limit exceeded")
+
+	// Authorization errors
+	ErrInvalidToken = errors.New("invalid token")
+	ErrForbidden    = errors.New("forbidden")
 
 	// Notification-related errors
 	ErrNotificationNotInitialized = errors.New("notification channel not initialized")
 	ErrNotificationChannelBlocked = errors.New("notification channel full or blocked")
 )
 
+// ClientRequestError is returned when the client answers a request sent by the
+// server with a JSON-RPC error.
+type ClientRequestError struct {
+	Code    int
+	Message string
+	Data    any
+}
+
+func (e *ClientRequestError) Error() string {
+	return fmt.Sprintf("client returned error %d: %s", e.Code, e.Message)
+}
+
+// RequestError is an error answered to the client with the given JSON-RPC error
+// code, e.g. by request middlewares rejecting a request with mcp.INVALID_PARAMS.
+type RequestError struct {
+	Code    int
+	Message string
+	Data    any
+}
+
+// NewRequestError creates a RequestError with the given code and message.
+func NewRequestError(code int, message string) *RequestError {
+	return &RequestError{Code: code, Message: message}
+}
+
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+// RateLimitError is returned when a tool call exceeds a rate limit or quota (see
+// WithRateLimit). It matches ErrRateLimited with errors.Is, and is answered to the
+// client with the mcp.RATE_LIMITED code and the details of the limit as data, e.g.
+// {"scope": "session", "tool": "search", "limit": "rate", "retryAfter": 1.5}.
+type RateLimitError struct {
+	// Scope of the exceeded limit
+	Scope RateLimitScope
+	// Tool whose calls are limited, or empty if the limit applies to all tools
+	Tool string
+	// Quota is set if the quota was exhausted, rather than the rate exceeded
+	Quota bool
+	// RetryAfter is the time after which the call can be retried, or 0 if it
+	// can't be, as the quota is never reset
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	limit := "rate limit"
+	if e.Quota {
+		limit = "quota"
+	}
+	message := fmt.Sprintf("%s %s exceeded", e.Scope, limit)
+	if e.Tool != "" {
+		message += fmt.Sprintf(" for tool '%s'", e.Tool)
+	}
+	if e.RetryAfter > 0 {
+		message += fmt.Sprintf(", retry after %s", e.RetryAfter)
+	}
+	return message
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// data returns the details of the error sent to the client
+func (e *RateLimitError) data() map[string]any {
+	data := map[string]any{
+		"scope": e.Scope,
+		"limit": "rate",
+	}
+	if e.Quota {
+		data["limit"] = "quota"
+	}
+	if e.Tool != "" {
+		data["tool"] = e.Tool
+	}
+	if e.RetryAfter > 0 {
+		data["retryAfter"] = e.RetryAfter.Seconds()
+	}
+	return data
+}
+
+// ForbiddenError is returned when the principal of a request lacks scopes
+// required by a tool, prompt or resource (see ServerTool.RequiredScopes). It
+// matches ErrForbidden with errors.Is, and is answered to the client with the
+// mcp.FORBIDDEN code and the missing scopes as data, e.g.
+// {"tool": "delete_record", "missingScopes": ["records:write"]}.
+type ForbiddenError struct {
+	// Kind of the item: "tool", "prompt" or "resource"
+	Kind string
+	// Name of the tool or prompt, or URI of the resource
+	Name string
+	// MissingScopes are the required scopes the principal wasn't granted
+	MissingScopes []string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("%s '%s' requires scopes %s", e.Kind, e.Name, strings.Join(e.MissingScopes, ", "))
+}
+
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// data returns the details of the error sent to the client
+func (e *ForbiddenError) data() map[string]any {
+	return map[string]any{
+		e.Kind:          e.Name,
+		"missingScopes": e.MissingScopes,
+	}
+}
+
+// SchemaViolation describes a value that does not conform to a JSON Schema.
+type SchemaViolation struct {
+	// JSON path of the offending value, e.g. $.items[0].name
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError is returned when a value does not conform to a JSON Schema.
+// It lists every violation found.
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Path+": "+violation.Message)
+	}
+	return "schema validation failed: " + strings.Join(messages, "; ")
+}
+
 // ErrDynamicPathConfig is returned when attempting to use static path methods with dynamic path configuration
 type ErrDynamicPathConfig struct {
 	Method string
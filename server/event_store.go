@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// EventStore stores the messages sent on the SSE streams of a
+// StreamableHTTPServer, so that clients can resume a stream after a dropped
+// connection by reconnecting with the Last-Event-ID header (see WithEventStore).
+// Implementations must be safe for concurrent use, and can share the events
+// between the replicas of a server.
+type EventStore interface {
+	// StoreEvent stores a message sent on a stream, and returns the ID of its
+	// event. Event IDs must be unique across all the streams of a session.
+	StoreEvent(ctx context.Context, streamID string, message json.RawMessage) (string, error)
+	// StreamIDForEventID returns the ID of the stream of an event, or an error
+	// wrapping ErrUnknownEventID if the event isn't stored.
+	StreamIDForEventID(ctx context.Context, eventID string) (string, error)
+	// ReplayEventsAfter calls send with the events stored on the stream of
+	// lastEventID after it, in order. It returns an error wrapping
+	// ErrUnknownEventID if the event isn't stored anymore, as events following
+	// it may be missing.
+	ReplayEventsAfter(ctx context.Context, lastEventID string, send func(eventID string, message json.RawMessage) error) error
+}
+
+// defaultEventStoreCapacity is the number of events kept by an
+// InMemoryEventStore created with a capacity of 0
+const defaultEventStoreCapacity = 1024
+
+// InMemoryEventStore is an EventStore keeping the last events of all the
+// streams in memory, in a ring buffer. Clients disconnected long enough for
+// the event they resume from to be overwritten can't resume their stream.
+type InMemoryEventStore struct {
+	mu     sync.Mutex
+	events []storedEvent // ring buffer, the event with sequence number n is at n % len(events)
+	seq    uint64        // sequence number of the last stored event
+	index  map[string]uint64
+}
+
+type storedEvent struct {
+	id       string
+	streamID string
+	message  json.RawMessage
+}
+
+var _ EventStore = (*InMemoryEventStore)(nil)
+
+// NewInMemoryEventStore creates an in-memory event store keeping the last
+// capacity events, or 1024 if capacity is 0
+func NewInMemoryEventStore(capacity int) *InMemoryEventStore {
+	if capacity <= 0 {
+		capacity = defaultEventStoreCapacity
+	}
+	return &InMemoryEventStore{
+		events: make([]storedEvent, capacity),
+		index:  make(map[string]uint64, capacity),
+	}
+}
+
+// StoreEvent implements EventStore
+func (m *InMemoryEventStore) StoreEvent(_ context.Context, streamID string, message json.RawMessage) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seq++
+	slot := &m.events[m.seq%uint64(len(m.events))]
+	if slot.id != "" {
+		delete(m.index, slot.id)
+	}
+	// the sequence number is shared by all the streams, so the IDs are unique
+	*slot = storedEvent{
+		id:       fmt.Sprintf("%s_%d", streamID, m.seq),
+		streamID: streamID,
+		message:  message,
+	}
+	m.index[slot.id] = m.seq
+	return slot.id, nil
+}
+
+// StreamIDForEventID implements EventStore
+func (m *InMemoryEventStore) StreamIDForEventID(_ context.Context, eventID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seq, ok := m.index[eventID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownEventID, eventID)
+	}
+	return m.events[seq%uint64(len(m.events))].streamID, nil
+}
+
+// ReplayEventsAfter implements EventStore
+func (m *InMemoryEventStore) ReplayEventsAfter(
+	_ context.Context,
+	lastEventID string,
+	send func(eventID string, message json.RawMessage) error,
+) error {
+	m.mu.Lock()
+	seq, ok := m.index[lastEventID]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownEventID, lastEventID)
+	}
+	// events are overwritten in order, so all the events following a stored
+	// one are stored too
+	streamID := m.events[seq%uint64(len(m.events))].streamID
+	var events []storedEvent
+	for n := seq + 1; n <= m.seq; n++ {
+		if event := m.events[n%uint64(len(m.events))]; event.streamID == streamID {
+			events = append(events, event)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, event := range events {
+		if err := send(event.id, event.message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryEventStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryEventStore(4)
+	replay := func(lastEventID string) ([]string, error) {
+		var messages []string
+		err := store.ReplayEventsAfter(ctx, lastEventID, func(eventID string, message json.RawMessage) error {
+			messages = append(messages, string(message))
+			return nil
+		})
+		return messages, err
+	}
+
+	first, err := store.StoreEvent(ctx, "session/a", json.RawMessage(`1`))
+	require.NoError(t, err)
+	second, _ := store.StoreEvent(ctx, "session/b", json.RawMessage(`2`))
+	_, _ = store.StoreEvent(ctx, "session/a", json.RawMessage(`3`))
+	assert.NotEqual(t, first, second, "event IDs are unique across streams")
+
+	streamID, err := store.StreamIDForEventID(ctx, second)
+	require.NoError(t, err)
+	assert.Equal(t, "session/b", streamID)
+
+	messages, err := replay(first)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, messages, "only the events of the stream are replayed")
+	messages, err = replay(second)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+
+	// overwrite the first event
+	_, _ = store.StoreEvent(ctx, "session/a", json.RawMessage(`4`))
+	_, _ = store.StoreEvent(ctx, "session/a", json.RawMessage(`5`))
+	_, err = replay(first)
+	assert.ErrorIs(t, err, ErrUnknownEventID)
+	_, err = store.StreamIDForEventID(ctx, first)
+	assert.ErrorIs(t, err, ErrUnknownEventID)
+	messages, err = replay(second)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+}
@@ -0,0 +1,360 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // hashes of the supported algorithms
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JSONWebKeySet holds the public keys of a JWKS (RFC 7517), used to verify the
+// signature of JWT access tokens
+type JSONWebKeySet struct {
+	keys []jsonWebKey
+}
+
+// jsonWebKey is a public key of a JWKS
+type jsonWebKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// ParseJWKS parses a JSON Web Key Set, e.g. the document served at the jwks_uri
+// of an authorization server. RSA, EC (P-256, P-384 and P-521) and Ed25519
+// public keys are supported, and other keys are ignored.
+func ParseJWKS(data []byte) (*JSONWebKeySet, error) {
+	var document struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	set := &JSONWebKeySet{}
+	for _, k := range document.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch {
+		case k.Kty == "RSA":
+			key, err = parseRSAKey(k.N, k.E)
+		case k.Kty == "EC":
+			key, err = parseECKey(k.Crv, k.X, k.Y)
+		case k.Kty == "OKP" && k.Crv == "Ed25519":
+			var x []byte
+			x, err = base64.RawURLEncoding.DecodeString(k.X)
+			if err == nil && len(x) != ed25519.PublicKeySize {
+				err = errors.New("bad Ed25519 key size")
+			}
+			key = ed25519.PublicKey(x)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWKS key %q: %w", k.Kid, err)
+		}
+		set.keys = append(set.keys, jsonWebKey{kid: k.Kid, alg: k.Alg, key: key})
+	}
+	if len(set.keys) == 0 {
+		return nil, errors.New("JWKS has no supported signing key")
+	}
+	return set, nil
+}
+
+func parseRSAKey(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("bad modulus: %w", err)
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, errors.New("bad exponent")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
+
+func parseECKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", crv)
+	}
+	xBytes, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, fmt.Errorf("bad x coordinate: %w", err)
+	}
+	yBytes, err := base64.RawURLEncoding.DecodeString(y)
+	if err != nil {
+		return nil, fmt.Errorf("bad y coordinate: %w", err)
+	}
+	key := &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(xBytes),
+		Y:     new(big.Int).SetBytes(yBytes),
+	}
+	if !curve.IsOnCurve(key.X, key.Y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return key, nil
+}
+
+// JWTVerifierConfig configures the verification of JWT access tokens
+type JWTVerifierConfig struct {
+	// Keys are the keys the tokens may be signed with
+	Keys *JSONWebKeySet
+	// Issuer is the issuer the tokens must have, if not empty
+	Issuer string
+	// Audience is the audience the tokens must be issued for, typically the
+	// URL of the MCP server. It defaults to the resource of the resource server
+	// the verifier is used by (see ResourceServerConfig.Resource). Tokens
+	// verified outside of a resource server aren't checked if it's empty.
+	Audience string
+	// Leeway is the clock skew tolerated when checking the times of the tokens
+	Leeway time.Duration
+}
+
+// NewJWTVerifier creates a verifier of JWT access tokens (RFC 9068) signed with
+// one of the keys of the config. The tokens must have an expiration time, and
+// their scopes are read from the scope or scp claim.
+func NewJWTVerifier(config JWTVerifierConfig) TokenVerifier {
+	return &jwtVerifier{config: config, now: time.Now}
+}
+
+type jwtVerifier struct {
+	config JWTVerifierConfig
+	now    func() time.Time
+}
+
+// jwtAlgorithms are the supported signing algorithms, with their hash
+var jwtAlgorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+	"EdDSA": 0,
+}
+
+// VerifyToken implements TokenVerifier
+func (v *jwtVerifier) VerifyToken(ctx context.Context, token string) (*TokenClaims, error) {
+	return v.verifyTokenFor(ctx, token, "")
+}
+
+// verifyTokenFor implements resourceTokenVerifier
+func (v *jwtVerifier) verifyTokenFor(_ context.Context, token, resource string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed JWT", ErrInvalidToken)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT header", ErrInvalidToken)
+	}
+	hash, ok := jwtAlgorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT signature", ErrInvalidToken)
+	}
+	if !v.verifySignature(header.Alg, header.Kid, hash, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var raw map[string]any
+	if err := decodeJWTPart(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT claims", ErrInvalidToken)
+	}
+	claims := claimsFromMap(raw)
+	audience := v.config.Audience
+	if audience == "" {
+		audience = resource
+	}
+	if err := v.validateClaims(claims, raw, audience); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verifySignature verifies the signature with the keys matching the algorithm
+// and key ID of the token
+func (v *jwtVerifier) verifySignature(alg, kid string, hash crypto.Hash, signed, signature []byte) bool {
+	if v.config.Keys == nil {
+		return false
+	}
+	var digest []byte
+	if hash != 0 {
+		h := hash.New()
+		h.Write(signed)
+		digest = h.Sum(nil)
+	}
+	for _, k := range v.config.Keys.keys {
+		if (kid != "" && k.kid != kid) || (k.alg != "" && k.alg != alg) {
+			continue
+		}
+		switch key := k.key.(type) {
+		case *rsa.PublicKey:
+			if strings.HasPrefix(alg, "RS") && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+				return true
+			}
+			if strings.HasPrefix(alg, "PS") &&
+				rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil {
+				return true
+			}
+		case *ecdsa.PublicKey:
+			size := (key.Curve.Params().BitSize + 7) / 8
+			if !strings.HasPrefix(alg, "ES") || len(signature) != 2*size || key.Curve.Params().BitSize != ecdsaBitSize(alg) {
+				continue
+			}
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			if ecdsa.Verify(key, digest, r, s) {
+				return true
+			}
+		case ed25519.PublicKey:
+			if alg == "EdDSA" && ed25519.Verify(key, signed, signature) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ecdsaBitSize returns the size of the curve of an ECDSA algorithm
+func ecdsaBitSize(alg string) int {
+	switch alg {
+	case "ES256":
+		return 256
+	case "ES384":
+		return 384
+	default:
+		return 521
+	}
+}
+
+// validateClaims checks the times, issuer and audience of a token
+func (v *jwtVerifier) validateClaims(claims *TokenClaims, raw map[string]any, audience string) error {
+	now := v.now()
+	if claims.ExpiresAt.IsZero() {
+		return fmt.Errorf("%w: missing expiration time", ErrInvalidToken)
+	}
+	if !now.Before(claims.ExpiresAt.Add(v.config.Leeway)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	if nbf, ok := numericDate(raw["nbf"]); ok && now.Add(v.config.Leeway).Before(nbf) {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidToken)
+	}
+	if v.config.Issuer != "" && claims.Issuer != v.config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if audience != "" && !slices.Contains(claims.Audience, audience) {
+		return fmt.Errorf("%w: token not issued for %q", ErrInvalidToken, audience)
+	}
+	return nil
+}
+
+// decodeJWTPart decodes a base64url encoded JSON part of a JWT
+func decodeJWTPart(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// claimsFromMap builds the claims of a token from its JSON claims, as found in
+// JWTs and token introspection responses (RFC 7662)
+func claimsFromMap(raw map[string]any) *TokenClaims {
+	claims := &TokenClaims{Raw: raw}
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.ClientID, _ = raw["client_id"].(string)
+	if claims.ClientID == "" {
+		claims.ClientID, _ = raw["azp"].(string)
+	}
+	claims.Audience = stringList(raw["aud"], false)
+	claims.Scopes = stringList(raw["scope"], true)
+	if len(claims.Scopes) == 0 {
+		claims.Scopes = stringList(raw["scp"], true)
+	}
+	claims.ExpiresAt, _ = numericDate(raw["exp"])
+	return claims
+}
+
+// stringList converts a claim holding a string or an array of strings into a
+// list, splitting strings on spaces if split is set
+func stringList(value any, split bool) []string {
+	switch value := value.(type) {
+	case string:
+		if split {
+			return strings.Fields(value)
+		}
+		return []string{value}
+	case []any:
+		list := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// numericDate converts a claim holding seconds since the epoch into a time
+func numericDate(value any) (time.Time, bool) {
+	var seconds float64
+	switch value := value.(type) {
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = f
+	case float64:
+		seconds = value
+	default:
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigner signs JWTs with a private key, and exposes its public key as a JWK
+type testSigner struct {
+	alg string
+	kid string
+	key crypto.Signer
+}
+
+func newTestSigners(t *testing.T) []testSigner {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return []testSigner{
+		{alg: "RS256", kid: "rsa", key: rsaKey},
+		{alg: "PS256", kid: "rsa", key: rsaKey},
+		{alg: "ES256", kid: "ec", key: ecKey},
+		{alg: "EdDSA", kid: "ed", key: edKey},
+	}
+}
+
+func (s testSigner) jwk() map[string]any {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch key := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]any{"kty": "RSA", "kid": s.kid, "n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		return map[string]any{"kty": "EC", "kid": s.kid, "crv": "P-256", "x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32)))}
+	case ed25519.PublicKey:
+		return map[string]any{"kty": "OKP", "kid": s.kid, "crv": "Ed25519", "x": b64(key)}
+	}
+	return nil
+}
+
+func (s testSigner) sign(t *testing.T, claims map[string]any) string {
+	header, err := json.Marshal(map[string]any{"alg": s.alg, "kid": s.kid, "typ": "at+jwt"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		digest := crypto.SHA256.New()
+		digest.Write([]byte(signed))
+		if s.alg == "PS256" {
+			signature, err = rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest.Sum(nil), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest.Sum(nil))
+		}
+	case *ecdsa.PrivateKey:
+		digest := crypto.SHA256.New()
+		digest.Write([]byte(signed))
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(signed))
+	}
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// newTestJWKS returns the JWKS of the public keys of the signers
+func newTestJWKS(t *testing.T, signers []testSigner) *JSONWebKeySet {
+	var keys []map[string]any
+	for _, signer := range signers {
+		keys = append(keys, signer.jwk())
+	}
+	keys = append(keys, map[string]any{"kty": "oct", "k": "c2VjcmV0"})
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	jwks, err := ParseJWKS(data)
+	require.NoError(t, err)
+	return jwks
+}
+
+func TestJWTVerifier(t *testing.T) {
+	signers := newTestSigners(t)
+	now := time.Unix(1_700_000_000, 0)
+	verifier := NewJWTVerifier(JWTVerifierConfig{
+		Keys:     newTestJWKS(t, signers),
+		Issuer:   "https://auth.example.com",
+		Audience: "https://mcp.example.com/mcp",
+		Leeway:   time.Minute,
+	}).(*jwtVerifier)
+	verifier.now = func() time.Time { return now }
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss":       "https://auth.example.com",
+			"sub":       "alice",
+			"aud":       []string{"https://mcp.example.com/mcp"},
+			"scope":     "tools:read tools:call",
+			"client_id": "agent",
+			"exp":       now.Add(time.Hour).Unix(),
+			"nbf":       now.Unix(),
+		}
+	}
+
+	for _, signer := range signers {
+		t.Run(signer.alg, func(t *testing.T) {
+			claims, err := verifier.VerifyToken(context.Background(), signer.sign(t, validClaims()))
+			require.NoError(t, err)
+			assert.Equal(t, "alice", claims.Subject)
+			assert.Equal(t, "https://auth.example.com", claims.Issuer)
+			assert.Equal(t, []string{"https://mcp.example.com/mcp"}, claims.Audience)
+			assert.Equal(t, []string{"tools:read", "tools:call"}, claims.Scopes)
+			assert.Equal(t, "agent", claims.ClientID)
+			assert.Equal(t, now.Add(time.Hour), claims.ExpiresAt)
+			assert.True(t, claims.HasScope("tools:call"))
+		})
+	}
+
+	invalid := map[string]func(claims map[string]any) map[string]any{
+		"expired": func(c map[string]any) map[string]any {
+			c["exp"] = now.Add(-2 * time.Minute).Unix()
+			return c
+		},
+		"not valid yet": func(c map[string]any) map[string]any {
+			c["nbf"] = now.Add(2 * time.Minute).Unix()
+			return c
+		},
+		"no expiration": func(c map[string]any) map[string]any {
+			delete(c, "exp")
+			return c
+		},
+		"other issuer": func(c map[string]any) map[string]any {
+			c["iss"] = "https://evil.example.com"
+			return c
+		},
+		"other audience": func(c map[string]any) map[string]any {
+			c["aud"] = "https://other.example.com"
+			return c
+		},
+	}
+	for name, modify := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifier.VerifyToken(context.Background(), signers[0].sign(t, modify(validClaims())))
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	t.Run("bad signature", func(t *testing.T) {
+		token := signers[0].sign(t, validClaims())
+		other := newTestSigners(t)[0]
+		forged := other.sign(t, validClaims())
+		_, err := verifier.VerifyToken(context.Background(), token[:len(token)-10]+forged[len(forged)-10:])
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		_, err = verifier.VerifyToken(context.Background(), forged)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+		payload, _ := json.Marshal(validClaims())
+		_, err := verifier.VerifyToken(context.Background(), header+"."+base64.RawURLEncoding.EncodeToString(payload)+".")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("audience defaults to the resource", func(t *testing.T) {
+		config := verifier.config
+		config.Audience = ""
+		rs := &resourceServer{config: ResourceServerConfig{
+			Verifier: &jwtVerifier{config: config, now: verifier.now},
+			Resource: "https://mcp.example.com/mcp",
+		}}
+		for audience, status := range map[string]int{
+			"https://mcp.example.com/mcp": http.StatusOK,
+			"https://other.example.com":   http.StatusUnauthorized,
+		} {
+			claims := validClaims()
+			claims["aud"] = audience
+			r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			r.Header.Set("Authorization", "Bearer "+signers[0].sign(t, claims))
+			w := httptest.NewRecorder()
+			if _, ok := rs.authenticate(w, r); ok {
+				w.WriteHeader(http.StatusOK)
+			}
+			assert.Equal(t, status, w.Code, audience)
+		}
+	})
+
+	t.Run("algorithm of another key type", func(t *testing.T) {
+		// an ECDSA signature presented as RS256 with the ID of the RSA key
+		signer := signers[2]
+		signer.alg = "RS256"
+		signer.kid = "rsa"
+		_, err := verifier.VerifyToken(context.Background(), signer.sign(t, validClaims()))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestParseJWKS(t *testing.T) {
+	_, err := ParseJWKS([]byte(`{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`))
+	assert.Error(t, err, "symmetric keys aren't supported")
+	_, err = ParseJWKS([]byte(`{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`))
+	assert.Error(t, err, "points must be on the curve")
+	_, err = ParseJWKS([]byte(`not json`))
+	assert.Error(t, err)
+}
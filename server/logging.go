@@ -0,0 +1,260 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ClientLogger sends log messages to the client of a request as
+// notifications/message. Messages below the log level set by the client with
+// logging/setLevel are dropped, as are all messages if the server doesn't have
+// the logging capability (see WithLogging).
+//
+// A ClientLogger is bound to the request it was obtained for, and must not be
+// used once the request has been answered.
+type ClientLogger struct {
+	ctx     context.Context
+	server  *MCPServer
+	session ClientSession
+	name    string
+}
+
+// ClientLoggerFromContext returns a logger sending log messages to the client of
+// the request being handled with ctx. Outside of a request, the logger drops
+// every message.
+func ClientLoggerFromContext(ctx context.Context) *ClientLogger {
+	return &ClientLogger{
+		ctx:     ctx,
+		server:  ServerFromContext(ctx),
+		session: ClientSessionFromContext(ctx),
+	}
+}
+
+// Named returns a copy of the logger sending its messages with the given logger name
+func (l *ClientLogger) Named(name string) *ClientLogger {
+	named := *l
+	named.name = name
+	return &named
+}
+
+// Enabled reports whether messages of the given level are sent to the client
+func (l *ClientLogger) Enabled(level mcp.LoggingLevel) bool {
+	if l.server == nil || l.session == nil || !l.session.Initialized() {
+		return false
+	}
+	l.server.capabilitiesMu.RLock()
+	logging := l.server.capabilities.logging != nil && *l.server.capabilities.logging
+	l.server.capabilitiesMu.RUnlock()
+	if !logging {
+		return false
+	}
+	minLevel := mcp.LoggingLevelError
+	if session, ok := l.session.(SessionWithLogging); ok {
+		minLevel = session.GetLogLevel()
+	}
+	return level.ShouldSendTo(minLevel)
+}
+
+// Log sends data, which can be any JSON serializable value, to the client if the
+// level is enabled. It returns an error if the notification could not be sent.
+func (l *ClientLogger) Log(level mcp.LoggingLevel, data any) error {
+	if !l.Enabled(level) {
+		return nil
+	}
+	params := map[string]any{
+		"level": level,
+		"data":  data,
+	}
+	if l.name != "" {
+		params["logger"] = l.name
+	}
+	return l.server.SendNotificationToClient(l.ctx, mcp.MethodNotificationMessage, params)
+}
+
+// Debug sends a debug message to the client, see LogMessage
+func (l *ClientLogger) Debug(message string, args ...any) error {
+	return l.LogMessage(mcp.LoggingLevelDebug, message, args...)
+}
+
+// Info sends an informational message to the client, see LogMessage
+func (l *ClientLogger) Info(message string, args ...any) error {
+	return l.LogMessage(mcp.LoggingLevelInfo, message, args...)
+}
+
+// Notice sends a notice to the client, see LogMessage
+func (l *ClientLogger) Notice(message string, args ...any) error {
+	return l.LogMessage(mcp.LoggingLevelNotice, message, args...)
+}
+
+// Warning sends a warning to the client, see LogMessage
+func (l *ClientLogger) Warning(message string, args ...any) error {
+	return l.LogMessage(mcp.LoggingLevelWarning, message, args...)
+}
+
+// Error sends an error message to the client, see LogMessage
+func (l *ClientLogger) Error(message string, args ...any) error {
+	return l.LogMessage(mcp.LoggingLevelError, message, args...)
+}
+
+// LogMessage sends a message to the client, with fields given as alternating keys and
+// values like with log/slog. The message is sent alone if there are no fields, and
+// as an object holding the message and the fields otherwise, e.g.
+// {"message": "fetched page", "url": "https://example.com"}.
+func (l *ClientLogger) LogMessage(level mcp.LoggingLevel, message string, args ...any) error {
+	if len(args) == 0 {
+		return l.Log(level, message)
+	}
+	if !l.Enabled(level) {
+		return nil
+	}
+	record := slog.NewRecord(time.Time{}, slog.LevelInfo, message, 0)
+	record.Add(args...)
+	return l.Log(level, recordData(record, nil, nil))
+}
+
+// ClientLogHandler is a slog.Handler sending log records to the client of the
+// request in the context of the record, through ClientLoggerFromContext. It lets
+// slog-based code stream its logs to MCP clients, e.g.:
+//
+//	logger := slog.New(server.NewClientLogHandler("my-server"))
+//	logger.InfoContext(ctx, "fetched page", "url", url)
+//
+// Records logged without the context of a request are dropped, so the handler is
+// typically combined with another handler logging locally.
+type ClientLogHandler struct {
+	name   string
+	attrs  []slog.Attr
+	groups []string
+}
+
+var _ slog.Handler = (*ClientLogHandler)(nil)
+
+// NewClientLogHandler creates a slog.Handler sending records to the MCP client
+// with the given logger name, which can be empty.
+func NewClientLogHandler(name string) *ClientLogHandler {
+	return &ClientLogHandler{name: name}
+}
+
+// Enabled reports whether the client of the request in ctx accepts messages of the level
+func (h *ClientLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return ClientLoggerFromContext(ctx).Enabled(slogLevelToLoggingLevel(level))
+}
+
+// Handle sends the record to the client of the request in ctx
+func (h *ClientLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	return ClientLoggerFromContext(ctx).Named(h.name).Log(
+		slogLevelToLoggingLevel(record.Level),
+		recordData(record, h.attrs, h.groups),
+	)
+}
+
+// WithAttrs returns a handler adding the attributes to every record
+func (h *ClientLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	handler := *h
+	handler.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], groupAttrs(h.groups, attrs)...)
+	return &handler
+}
+
+// WithGroup returns a handler nesting the attributes of records in the group
+func (h *ClientLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	handler := *h
+	handler.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &handler
+}
+
+// slogLevelToLoggingLevel maps slog levels to the syslog severities of MCP. The
+// levels between the ones defined by slog map to the severities in between, e.g.
+// slog.LevelInfo+2 to notice and slog.LevelError+4 to critical.
+func slogLevelToLoggingLevel(level slog.Level) mcp.LoggingLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return mcp.LoggingLevelDebug
+	case level < slog.LevelInfo+2:
+		return mcp.LoggingLevelInfo
+	case level < slog.LevelWarn:
+		return mcp.LoggingLevelNotice
+	case level < slog.LevelError:
+		return mcp.LoggingLevelWarning
+	case level < slog.LevelError+4:
+		return mcp.LoggingLevelError
+	case level < slog.LevelError+8:
+		return mcp.LoggingLevelCritical
+	case level < slog.LevelError+12:
+		return mcp.LoggingLevelAlert
+	default:
+		return mcp.LoggingLevelEmergency
+	}
+}
+
+// groupAttrs nests attributes in the given groups
+func groupAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
+
+// recordData converts a record into the data of a log message: an object holding
+// the message and the attributes of the record
+func recordData(record slog.Record, attrs []slog.Attr, groups []string) map[string]any {
+	data := map[string]any{"message": record.Message}
+	recordAttrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		recordAttrs = append(recordAttrs, attr)
+		return true
+	})
+	for _, attr := range attrs {
+		addAttr(data, attr)
+	}
+	for _, attr := range groupAttrs(groups, recordAttrs) {
+		addAttr(data, attr)
+	}
+	return data
+}
+
+// addAttr adds an attribute to the data of a log message, as JSON serializable values
+func addAttr(data map[string]any, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		groupAttrs := value.Group()
+		if len(groupAttrs) == 0 {
+			return
+		}
+		if attr.Key == "" {
+			// inline the attributes of groups without a key, like slog handlers do
+			for _, groupAttr := range groupAttrs {
+				addAttr(data, groupAttr)
+			}
+			return
+		}
+		group, ok := data[attr.Key].(map[string]any)
+		if !ok {
+			group = make(map[string]any, len(groupAttrs))
+			data[attr.Key] = group
+		}
+		for _, groupAttr := range groupAttrs {
+			addAttr(group, groupAttr)
+		}
+	case slog.KindDuration:
+		data[attr.Key] = value.Duration().String()
+	default:
+		if attr.Key == "" {
+			return
+		}
+		if err, ok := value.Any().(error); ok {
+			data[attr.Key] = err.Error()
+			return
+		}
+		data[attr.Key] = value.Any()
+	}
+}
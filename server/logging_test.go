@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newLoggingTestContext returns the context of a request of an initialized session
+// with the given log level
+func newLoggingTestContext(server *MCPServer, level mcp.LoggingLevel) (context.Context, chan mcp.JSONRPCNotification) {
+	notifications := make(chan mcp.JSONRPCNotification, 10)
+	session := &sessionTestClientWithLogging{sessionID: "session-1", notificationChannel: notifications}
+	session.Initialize()
+	session.SetLogLevel(level)
+	ctx := context.WithValue(context.Background(), serverKey{}, server)
+	return server.WithContext(ctx, session), notifications
+}
+
+// receivedLogs returns the params of the log messages sent to the client
+func receivedLogs(notifications chan mcp.JSONRPCNotification) []map[string]any {
+	var logs []map[string]any
+	for {
+		select {
+		case notification := <-notifications:
+			if notification.Method == mcp.MethodNotificationMessage {
+				logs = append(logs, notification.Params.AdditionalFields)
+			}
+		default:
+			return logs
+		}
+	}
+}
+
+func TestClientLogger(t *testing.T) {
+	t.Run("messages are filtered by the session level", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithLogging())
+		ctx, notifications := newLoggingTestContext(server, mcp.LoggingLevelWarning)
+		logger := ClientLoggerFromContext(ctx)
+
+		require.NoError(t, logger.Info("dropped"))
+		require.NoError(t, logger.Warning("disk almost full"))
+		require.NoError(t, logger.Named("db").Error("query failed", "table", "users", "err", errors.New("timeout")))
+		require.NoError(t, logger.Log(mcp.LoggingLevelCritical, map[string]any{"code": 42}))
+
+		assert.Equal(t, []map[string]any{
+			{"level": mcp.LoggingLevelWarning, "data": "disk almost full"},
+			{"level": mcp.LoggingLevelError, "logger": "db", "data": map[string]any{
+				"message": "query failed",
+				"table":   "users",
+				"err":     "timeout",
+			}},
+			{"level": mcp.LoggingLevelCritical, "data": map[string]any{"code": 42}},
+		}, receivedLogs(notifications))
+	})
+
+	t.Run("nothing is sent without the logging capability", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		ctx, notifications := newLoggingTestContext(server, mcp.LoggingLevelDebug)
+		logger := ClientLoggerFromContext(ctx)
+
+		assert.False(t, logger.Enabled(mcp.LoggingLevelEmergency))
+		require.NoError(t, logger.Error("dropped"))
+		assert.Empty(t, receivedLogs(notifications))
+	})
+
+	t.Run("nothing is sent outside of a request", func(t *testing.T) {
+		logger := ClientLoggerFromContext(context.Background())
+		assert.False(t, logger.Enabled(mcp.LoggingLevelEmergency))
+		assert.NoError(t, logger.Error("dropped"))
+	})
+
+	t.Run("tool handlers log to the client", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithLogging())
+		server.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := ClientLoggerFromContext(ctx).Info("working"); err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText("done"), nil
+		})
+		ctx, notifications := newLoggingTestContext(server, mcp.LoggingLevelInfo)
+
+		response := server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "work"}}`))
+		require.IsType(t, mcp.JSONRPCResponse{}, response)
+		assert.Equal(t, []map[string]any{{"level": mcp.LoggingLevelInfo, "data": "working"}}, receivedLogs(notifications))
+	})
+}
+
+func TestClientLogHandler(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithLogging())
+	ctx, notifications := newLoggingTestContext(server, mcp.LoggingLevelInfo)
+	logger := slog.New(NewClientLogHandler("app")).With("request", 7).WithGroup("http")
+
+	logger.DebugContext(ctx, "dropped")
+	logger.InfoContext(ctx, "fetched page", "url", "https://example.com", slog.Duration("took", time.Second))
+	logger.Log(ctx, slog.LevelError+4, "out of memory", slog.Group("mem", "used", 1024))
+	// records without a request context are dropped
+	logger.Error("dropped")
+
+	assert.Equal(t, []map[string]any{
+		{"level": mcp.LoggingLevelInfo, "logger": "app", "data": map[string]any{
+			"message": "fetched page",
+			"request": int64(7),
+			"http":    map[string]any{"url": "https://example.com", "took": "1s"},
+		}},
+		{"level": mcp.LoggingLevelCritical, "logger": "app", "data": map[string]any{
+			"message": "out of memory",
+			"request": int64(7),
+			"http":    map[string]any{"mem": map[string]any{"used": int64(1024)}},
+		}},
+	}, receivedLogs(notifications))
+}
+
+func TestSlogLevelToLoggingLevel(t *testing.T) {
+	tests := map[slog.Level]mcp.LoggingLevel{
+		slog.LevelDebug:      mcp.LoggingLevelDebug,
+		slog.LevelInfo:       mcp.LoggingLevelInfo,
+		slog.LevelInfo + 2:   mcp.LoggingLevelNotice,
+		slog.LevelWarn:       mcp.LoggingLevelWarning,
+		slog.LevelError:      mcp.LoggingLevelError,
+		slog.LevelError + 4:  mcp.LoggingLevelCritical,
+		slog.LevelError + 8:  mcp.LoggingLevelAlert,
+		slog.LevelError + 12: mcp.LoggingLevelEmergency,
+	}
+	for level, expected := range tests {
+		assert.Equal(t, expected, slogLevelToLoggingLevel(level), level.String())
+	}
+}
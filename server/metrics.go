@@ -0,0 +1,170 @@
+package server
+
+import (
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/metrics"
+)
+
+// Statuses of the requests and tool calls counted by the server metrics
+const (
+	metricsStatusOK        = "ok"
+	metricsStatusError     = "error"
+	metricsStatusToolError = "tool_error"
+)
+
+// serverMetrics are the metrics recorded by a server. All methods are no-ops on
+// a nil *serverMetrics, which is what servers without metrics have.
+type serverMetrics struct {
+	requests          *metrics.Counter
+	requestDuration   *metrics.Histogram
+	toolCalls         *metrics.Counter
+	toolCallDuration  *metrics.Histogram
+	notificationDrops *metrics.Counter
+}
+
+// WithMetrics records the metrics of the server in the registry, which serves
+// them in the Prometheus text format and can be mounted next to the transport:
+//
+//	registry := metrics.NewRegistry()
+//	s := server.NewMCPServer("my-server", "1.0.0", server.WithMetrics(registry))
+//	http.Handle("/mcp", server.NewStreamableHTTPServer(s))
+//	http.Handle("/metrics", registry)
+//
+// The metrics are:
+//   - mcp_requests_total: requests per method and status (ok or error)
+//   - mcp_request_duration_seconds: latency of requests per method
+//   - mcp_tool_calls_total: tool calls per tool and status (ok, error, or
+//     tool_error when the tool returned a result flagged as an error)
+//   - mcp_tool_call_duration_seconds: latency of tool calls per tool
+//   - mcp_active_sessions: registered sessions per transport. Streamable HTTP
+//     sessions are registered while their client listens with a GET request.
+//   - mcp_notifications_dropped_total: notifications dropped because the
+//     notification channel of the session was full, per method
+//   - mcp_sse_queue_depth: events waiting to be written to SSE streams
+//
+// A registry holds the metrics of a single server.
+func WithMetrics(registry *metrics.Registry) ServerOption {
+	return func(s *MCPServer) {
+		s.metrics = &serverMetrics{
+			requests: registry.NewCounter(
+				"mcp_requests_total",
+				"Requests received, per method and status.",
+				"method", "status",
+			),
+			requestDuration: registry.NewHistogram(
+				"mcp_request_duration_seconds",
+				"Time taken to handle requests, per method.",
+				nil,
+				"method",
+			),
+			toolCalls: registry.NewCounter(
+				"mcp_tool_calls_total",
+				"Tool calls, per tool and status.",
+				"tool", "status",
+			),
+			toolCallDuration: registry.NewHistogram(
+				"mcp_tool_call_duration_seconds",
+				"Time taken by tool handlers, per tool.",
+				nil,
+				"tool",
+			),
+			notificationDrops: registry.NewCounter(
+				"mcp_notifications_dropped_total",
+				"Notifications dropped because the notification channel of the session was full, per method.",
+				"method",
+			),
+		}
+		registry.NewGaugeFunc(
+			"mcp_active_sessions",
+			"Sessions registered with the server, per transport.",
+			s.collectActiveSessions,
+			"transport",
+		)
+		registry.NewGaugeFunc(
+			"mcp_sse_queue_depth",
+			"Events waiting to be written to SSE streams.",
+			s.collectSSEQueueDepth,
+		)
+	}
+}
+
+// observeRequest records a request answered with response
+func (m *serverMetrics) observeRequest(method mcp.MCPMethod, response mcp.JSONRPCMessage, start time.Time) {
+	if m == nil {
+		return
+	}
+	status := metricsStatusOK
+	if response, ok := response.(mcp.JSONRPCError); ok {
+		status = metricsStatusError
+		if response.Error.Code == mcp.METHOD_NOT_FOUND {
+			// don't let clients create series with arbitrary method names
+			method = "unknown"
+		}
+	}
+	m.requests.Inc(string(method), status)
+	m.requestDuration.Observe(time.Since(start).Seconds(), string(method))
+}
+
+// observeToolCall records a call of a tool handler
+func (m *serverMetrics) observeToolCall(tool string, result *mcp.CallToolResult, err error, start time.Time) {
+	if m == nil {
+		return
+	}
+	status := metricsStatusOK
+	if err != nil {
+		status = metricsStatusError
+	} else if result != nil && result.IsError {
+		status = metricsStatusToolError
+	}
+	m.toolCalls.Inc(tool, status)
+	m.toolCallDuration.Observe(time.Since(start).Seconds(), tool)
+}
+
+// notificationDropped records a notification dropped because the notification
+// channel of a session was full
+func (m *serverMetrics) notificationDropped(method string) {
+	if m == nil {
+		return
+	}
+	m.notificationDrops.Inc(method)
+}
+
+// collectActiveSessions sets the number of registered sessions per transport
+func (s *MCPServer) collectActiveSessions(set func(value float64, labelValues ...string)) {
+	counts := map[string]int{"sse": 0, "stdio": 0, "streamable_http": 0}
+	s.sessions.Range(func(_, value any) bool {
+		counts[sessionTransport(value)]++
+		return true
+	})
+	for transport, count := range counts {
+		set(float64(count), transport)
+	}
+}
+
+// sessionTransport returns the name of the transport of a session
+func sessionTransport(session any) string {
+	switch session.(type) {
+	case *sseSession:
+		return "sse"
+	case *stdioSession:
+		return "stdio"
+	case *streamableHttpSession:
+		return "streamable_http"
+	default:
+		return "other"
+	}
+}
+
+// collectSSEQueueDepth sets the number of events queued by the SSE sessions
+func (s *MCPServer) collectSSEQueueDepth(set func(value float64, labelValues ...string)) {
+	depth := 0
+	s.sessions.Range(func(_, value any) bool {
+		if session, ok := value.(*sseSession); ok {
+			depth += len(session.eventQueue)
+		}
+		return true
+	})
+	set(float64(depth))
+}
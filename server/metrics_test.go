@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/metrics"
+)
+
+func TestMCPServer_Metrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	server := NewMCPServer("test-server", "1.0.0", WithMetrics(registry))
+	server.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("echo"), nil
+	})
+	server.AddTool(mcp.NewTool("invalid"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("invalid input"), nil
+	})
+	server.AddTool(mcp.NewTool("fail"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+
+	ctx := context.Background()
+	for _, message := range []string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "echo"}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "echo"}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "invalid"}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "tools/call", "params": {"name": "fail"}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "made/up"}`,
+	} {
+		server.HandleMessage(ctx, []byte(message))
+	}
+
+	// a session with a full notification channel, and an SSE session with queued events
+	blocked := &sessionTestClient{sessionID: "blocked", notificationChannel: make(chan mcp.JSONRPCNotification), initialized: true}
+	require.NoError(t, server.RegisterSession(ctx, blocked))
+	sse := &sseSession{sessionID: "sse", eventQueue: make(chan string, 10)}
+	sse.eventQueue <- "event"
+	sse.eventQueue <- "event"
+	require.NoError(t, server.RegisterSession(ctx, sse))
+	assert.ErrorIs(t, server.SendNotificationToSpecificClient("blocked", "notifications/custom", nil), ErrNotificationChannelBlocked)
+
+	var b strings.Builder
+	require.NoError(t, registry.WriteText(&b))
+	text := b.String()
+	for _, line := range []string{
+		`mcp_requests_total{method="ping",status="ok"} 1`,
+		`mcp_requests_total{method="tools/call",status="ok"} 3`,
+		`mcp_requests_total{method="tools/call",status="error"} 1`,
+		`mcp_requests_total{method="unknown",status="error"} 1`,
+		`mcp_request_duration_seconds_count{method="tools/call"} 4`,
+		`mcp_tool_calls_total{tool="echo",status="ok"} 2`,
+		`mcp_tool_calls_total{tool="invalid",status="tool_error"} 1`,
+		`mcp_tool_calls_total{tool="fail",status="error"} 1`,
+		`mcp_tool_call_duration_seconds_count{tool="echo"} 2`,
+		`mcp_notifications_dropped_total{method="notifications/custom"} 1`,
+		`mcp_active_sessions{transport="other"} 1`,
+		`mcp_active_sessions{transport="sse"} 1`,
+		`mcp_active_sessions{transport="stdio"} 0`,
+		`mcp_sse_queue_depth 2`,
+	} {
+		assert.Contains(t, text, line+"\n")
+	}
+
+	server.UnregisterSession(ctx, "sse")
+	b.Reset()
+	require.NoError(t, registry.WriteText(&b))
+	assert.Contains(t, b.String(), `mcp_active_sessions{transport="sse"} 0`+"\n")
+	assert.Contains(t, b.String(), "mcp_sse_queue_depth 0\n")
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RequestInfo describes a request received from the client, as seen by request middlewares.
+type RequestInfo struct {
+	// ID of the request
+	ID mcp.RequestId
+	// Method of the request, e.g. tools/call
+	Method mcp.MCPMethod
+	// Params of the request as sent by the client, or nil if there are none.
+	// Middlewares may replace them before calling the next handler.
+	Params json.RawMessage
+	// Session the request was received on, or nil if there is none
+	Session ClientSession
+
+	// message received from the client, with its method and params
+	message json.RawMessage
+	method  mcp.MCPMethod
+	params  json.RawMessage
+}
+
+// RequestHandlerFunc handles a request of any method. It returns the result of the
+// request, e.g. an mcp.CallToolResult for tools/call, or an error.
+type RequestHandlerFunc func(ctx context.Context, request *RequestInfo) (any, error)
+
+// RequestMiddleware is a middleware function that wraps the handling of requests
+// of every method. It can reject a request by returning an error without calling
+// next, or rewrite the result returned by next.
+type RequestMiddleware func(next RequestHandlerFunc) RequestHandlerFunc
+
+// WithRequestMiddleware adds a middleware that runs for every request, before the
+// hooks and the handler of the request method. Middlewares run in the order they
+// are added. Errors are answered with their code if they are a *RequestError, and
+// with an internal error otherwise.
+func WithRequestMiddleware(requestMiddleware RequestMiddleware) ServerOption {
+	return func(s *MCPServer) {
+		s.middlewareMu.Lock()
+		s.requestMiddlewares = append(s.requestMiddlewares, requestMiddleware)
+		s.middlewareMu.Unlock()
+	}
+}
+
+// requestHandler returns the handler of requests, wrapped by the request middlewares
+func (s *MCPServer) requestHandler() RequestHandlerFunc {
+	s.middlewareMu.RLock()
+	middlewares := s.requestMiddlewares
+	s.middlewareMu.RUnlock()
+
+	handler := s.dispatchRequest
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// requestMessage returns the message of a request, rebuilt from the method and
+// params of the request if middlewares replaced them
+func requestMessage(request *RequestInfo) (json.RawMessage, error) {
+	if request.message != nil && request.Method == request.method && bytes.Equal(request.Params, request.params) {
+		return request.message, nil
+	}
+	return json.Marshal(struct {
+		Method mcp.MCPMethod   `json:"method"`
+		Params json.RawMessage `json:"params,omitempty"`
+	}{
+		Method: request.Method,
+		Params: request.Params,
+	})
+}
+
+// requestErrorResponse converts an error returned by the request handler into a
+// JSON-RPC error
+func requestErrorResponse(id any, err error) mcp.JSONRPCMessage {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		return reqErr.ToJSONRPCError()
+	}
+	var rpcErr *RequestError
+	if errors.As(err, &rpcErr) {
+		response := createErrorResponse(id, rpcErr.Code, rpcErr.Message).(mcp.JSONRPCError)
+		response.Error.Data = rpcErr.Data
+		return response
+	}
+	return createErrorResponse(id, mcp.INTERNAL_ERROR, err.Error())
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newMiddlewareTestServer(opts ...ServerOption) *MCPServer {
+	server := NewMCPServer("test-server", "1.0.0", opts...)
+	server.AddTool(mcp.NewTool("echo", mcp.WithString("text")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(request.GetString("text", "")), nil
+	})
+	server.AddResource(mcp.NewResource("test://secret", "secret"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: "test://secret", Text: "secret"}}, nil
+	})
+	return server
+}
+
+func TestMCPServer_RequestMiddleware(t *testing.T) {
+	t.Run("middlewares see every request in order", func(t *testing.T) {
+		var calls []string
+		record := func(name string) RequestMiddleware {
+			return func(next RequestHandlerFunc) RequestHandlerFunc {
+				return func(ctx context.Context, request *RequestInfo) (any, error) {
+					calls = append(calls, name+" "+string(request.Method)+" "+request.ID.String())
+					return next(ctx, request)
+				}
+			}
+		}
+		server := newMiddlewareTestServer(WithRequestMiddleware(record("first")), WithRequestMiddleware(record("second")))
+
+		server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`))
+		server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": "a", "method": "resources/read", "params": {"uri": "test://secret"}}`))
+		assert.Equal(t, []string{
+			"first ping int64:1",
+			"second ping int64:1",
+			"first resources/read string:a",
+			"second resources/read string:a",
+		}, calls)
+	})
+
+	t.Run("middleware sees the session and params", func(t *testing.T) {
+		var info *RequestInfo
+		server := newMiddlewareTestServer(WithRequestMiddleware(func(next RequestHandlerFunc) RequestHandlerFunc {
+			return func(ctx context.Context, request *RequestInfo) (any, error) {
+				info = request
+				return next(ctx, request)
+			}
+		}))
+		session := &sessionTestClient{sessionID: "session-1"}
+		ctx := server.WithContext(context.Background(), session)
+
+		server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "echo", "arguments": {"text": "hi"}}}`))
+		require.NotNil(t, info)
+		assert.Equal(t, session, info.Session)
+		assert.JSONEq(t, `{"name": "echo", "arguments": {"text": "hi"}}`, string(info.Params))
+	})
+
+	t.Run("middleware rejects requests", func(t *testing.T) {
+		server := newMiddlewareTestServer(WithRequestMiddleware(func(next RequestHandlerFunc) RequestHandlerFunc {
+			return func(ctx context.Context, request *RequestInfo) (any, error) {
+				if request.Method == mcp.MethodResourcesRead {
+					return nil, &RequestError{Code: mcp.INVALID_PARAMS, Message: "access denied", Data: "test://secret"}
+				}
+				if request.Method == mcp.MethodPromptsList {
+					return nil, errors.New("prompts are unavailable")
+				}
+				return next(ctx, request)
+			}
+		}))
+
+		response, ok := server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": "test://secret"}}`,
+		)).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INVALID_PARAMS, response.Error.Code)
+		assert.Equal(t, "access denied", response.Error.Message)
+		assert.Equal(t, "test://secret", response.Error.Data)
+
+		response, ok = server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 2, "method": "prompts/list"}`,
+		)).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INTERNAL_ERROR, response.Error.Code)
+		assert.Equal(t, "prompts are unavailable", response.Error.Message)
+	})
+
+	t.Run("middleware rewrites params and results", func(t *testing.T) {
+		server := newMiddlewareTestServer(WithRequestMiddleware(func(next RequestHandlerFunc) RequestHandlerFunc {
+			return func(ctx context.Context, request *RequestInfo) (any, error) {
+				if request.Method != mcp.MethodToolsCall {
+					return next(ctx, request)
+				}
+				request.Params = json.RawMessage(`{"name": "echo", "arguments": {"text": "rewritten"}}`)
+				result, err := next(ctx, request)
+				if err != nil {
+					return nil, err
+				}
+				toolResult := result.(mcp.CallToolResult)
+				toolResult.Meta = map[string]any{"middleware": true}
+				return toolResult, nil
+			}
+		}))
+
+		response, ok := server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "echo", "arguments": {"text": "hi"}}}`,
+		)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		result := response.Result.(mcp.CallToolResult)
+		assert.Equal(t, "rewritten", result.Content[0].(mcp.TextContent).Text)
+		assert.Equal(t, map[string]any{"middleware": true}, result.Meta)
+	})
+
+	t.Run("handler errors reach the middleware", func(t *testing.T) {
+		var handlerErr error
+		server := newMiddlewareTestServer(WithRequestMiddleware(func(next RequestHandlerFunc) RequestHandlerFunc {
+			return func(ctx context.Context, request *RequestInfo) (any, error) {
+				result, err := next(ctx, request)
+				handlerErr = err
+				return result, err
+			}
+		}))
+
+		response, ok := server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "missing"}}`,
+		)).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INVALID_PARAMS, response.Error.Code)
+		assert.ErrorIs(t, handlerErr, ErrToolNotFound)
+	})
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// ID identifies the principal, e.g. the subject of an access token
+	ID string
+	// Scopes granted to the principal, e.g. the scopes of its access token or
+	// its roles. They are checked against the scopes required by tools,
+	// prompts and resources.
+	Scopes []string
+}
+
+// HasScope reports whether the principal was granted the scope
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && slices.Contains(p.Scopes, scope)
+}
+
+// PrincipalExtractor returns the principal of the request being handled with
+// ctx, or nil if the request isn't authenticated
+type PrincipalExtractor func(ctx context.Context) *Principal
+
+// WithPrincipalExtractor sets how the server finds the authenticated caller of
+// requests, e.g. from values set in the context by an HTTPContextFunc. The
+// principal is used by the per-principal rate limits, and its scopes by the
+// scopes required by tools, prompts and resources. Without an extractor, the
+// principal is the subject of the access token verified by the resource server
+// of the transport, if any, with the scopes of the token (see
+// TokenClaimsFromContext).
+func WithPrincipalExtractor(extractor PrincipalExtractor) ServerOption {
+	return func(s *MCPServer) {
+		s.principalExtractor = extractor
+	}
+}
+
+// principal returns the principal of the request being handled with ctx, or nil
+func (s *MCPServer) principal(ctx context.Context) *Principal {
+	if s.principalExtractor != nil {
+		return s.principalExtractor(ctx)
+	}
+	if claims, ok := TokenClaimsFromContext(ctx); ok && claims.Subject != "" {
+		return &Principal{ID: claims.Subject, Scopes: claims.Scopes}
+	}
+	return nil
+}
+
+// missingScopes returns the required scopes the principal wasn't granted.
+// Requests without a principal have no scopes.
+func missingScopes(principal *Principal, required []string) []string {
+	var missing []string
+	for _, scope := range required {
+		if !principal.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// authorize returns a *ForbiddenError if the principal of the request lacks
+// one of the scopes required by an item
+func (s *MCPServer) authorize(ctx context.Context, kind, name string, required []string) error {
+	if len(required) == 0 {
+		return nil
+	}
+	if missing := missingScopes(s.principal(ctx), required); len(missing) > 0 {
+		return &ForbiddenError{Kind: kind, Name: name, MissingScopes: missing}
+	}
+	return nil
+}
+
+// promptScopes returns the scopes required to use the prompt with the given
+// name, looked up like prompts/get does
+func (s *MCPServer) promptScopes(ctx context.Context, name string) []string {
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithPrompts); ok {
+		if prompt, ok := session.GetSessionPrompts()[name]; ok {
+			return prompt.RequiredScopes
+		}
+	}
+	s.promptsMu.RLock()
+	defer s.promptsMu.RUnlock()
+	return s.prompts[name].RequiredScopes
+}
+
+// resourceScopes returns the scopes required to use the resource with the given
+// URI, looked up like resources/read does. The URI may also be the one of a
+// resource template.
+func (s *MCPServer) resourceScopes(ctx context.Context, uri string) []string {
+	var sessionTemplates *templateIndex
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithResources); ok {
+		if resource, ok := session.GetSessionResources()[uri]; ok {
+			return resource.RequiredScopes
+		}
+		templates := session.GetSessionResourceTemplates()
+		if template, ok := templates[uri]; ok {
+			return template.RequiredScopes
+		}
+		sessionTemplates = sessionResourceTemplateIndex(session, templates)
+	}
+
+	s.resourcesMu.RLock()
+	defer s.resourcesMu.RUnlock()
+	if entry, ok := s.resources[uri]; ok {
+		return entry.requiredScopes
+	}
+	if sessionTemplates != nil {
+		if template, ok := sessionTemplates.match(uri); ok {
+			return template.RequiredScopes
+		}
+	}
+	if entry, ok := s.resourceTemplates[uri]; ok {
+		return entry.requiredScopes
+	}
+	if template, ok := s.resourceTemplateIndex().match(uri); ok {
+		return template.RequiredScopes
+	}
+	return nil
+}
+
+// scopeChecker returns a function reporting whether the principal of the
+// request has the required scopes, used to hide the items it can't use from
+// lists. The principal is only extracted if an item requires scopes.
+func (s *MCPServer) scopeChecker(ctx context.Context) func(required []string) bool {
+	var principal *Principal
+	var extracted bool
+	return func(required []string) bool {
+		if len(required) == 0 {
+			return true
+		}
+		if !extracted {
+			principal, extracted = s.principal(ctx), true
+		}
+		return len(missingScopes(principal, required)) == 0
+	}
+}
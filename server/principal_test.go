@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPServer_RequiredScopes(t *testing.T) {
+	principals := map[string]*Principal{
+		"reader": {ID: "reader", Scopes: []string{"records:read"}},
+		"admin":  {ID: "admin", Scopes: []string{"records:read", "records:write", "admin"}},
+	}
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(true, false), WithPrincipalExtractor(func(ctx context.Context) *Principal {
+		id, _ := ctx.Value(testPrincipalKey{}).(string)
+		return principals[id]
+	}))
+	toolHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	}
+	server.AddTools(
+		ServerTool{Tool: mcp.NewTool("echo"), Handler: toolHandler},
+		ServerTool{Tool: mcp.NewTool("get_record"), Handler: toolHandler, RequiredScopes: []string{"records:read"}},
+		ServerTool{Tool: mcp.NewTool("delete_record"), Handler: toolHandler, RequiredScopes: []string{"records:read", "records:write"}},
+	)
+	server.AddPrompts(ServerPrompt{
+		Prompt: mcp.NewPrompt("audit"),
+		Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return mcp.NewGetPromptResult("audit", nil), nil
+		},
+		RequiredScopes: []string{"admin"},
+	})
+	resourceHandler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "contents"}}, nil
+	}
+	server.AddResources(
+		ServerResource{Resource: mcp.NewResource("docs://readme", "readme"), Handler: resourceHandler},
+		ServerResource{Resource: mcp.NewResource("admin://config", "config"), Handler: resourceHandler, RequiredScopes: []string{"admin"}},
+	)
+	server.AddResourceTemplates(ServerResourceTemplate{
+		Template:       mcp.NewResourceTemplate("admin://users/{id}", "user"),
+		Handler:        resourceHandler,
+		RequiredScopes: []string{"admin"},
+	})
+
+	server.AddPromptCompletionProvider("audit", func(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		return &mcp.CompleteResult{}, nil
+	})
+
+	send := func(principal, method, params string) mcp.JSONRPCMessage {
+		ctx := context.WithValue(context.Background(), testPrincipalKey{}, principal)
+		return server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "`+method+`", "params": `+params+`}`))
+	}
+	list := func(principal, method, field string) []string {
+		response := send(principal, method, `{}`)
+		require.IsType(t, mcp.JSONRPCResponse{}, response)
+		data, err := json.Marshal(response.(mcp.JSONRPCResponse).Result)
+		require.NoError(t, err)
+		var result map[string][]struct {
+			Name string `json:"name"`
+		}
+		require.NoError(t, json.Unmarshal(data, &result))
+		names := make([]string, 0, len(result[field]))
+		for _, item := range result[field] {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	t.Run("lists hide the items the caller can't use", func(t *testing.T) {
+		assert.Equal(t, []string{"echo"}, list("", "tools/list", "tools"))
+		assert.Equal(t, []string{"echo", "get_record"}, list("reader", "tools/list", "tools"))
+		assert.Equal(t, []string{"delete_record", "echo", "get_record"}, list("admin", "tools/list", "tools"))
+
+		assert.Empty(t, list("reader", "prompts/list", "prompts"))
+		assert.Equal(t, []string{"audit"}, list("admin", "prompts/list", "prompts"))
+
+		assert.Equal(t, []string{"readme"}, list("reader", "resources/list", "resources"))
+		assert.Equal(t, []string{"config", "readme"}, list("admin", "resources/list", "resources"))
+
+		assert.Empty(t, list("reader", "resources/templates/list", "resourceTemplates"))
+		assert.Equal(t, []string{"user"}, list("admin", "resources/templates/list", "resourceTemplates"))
+	})
+
+	t.Run("calls are rejected", func(t *testing.T) {
+		response := send("reader", "tools/call", `{"name": "delete_record"}`)
+		require.IsType(t, mcp.JSONRPCError{}, response)
+		rpcErr := response.(mcp.JSONRPCError).Error
+		assert.Equal(t, mcp.FORBIDDEN, rpcErr.Code)
+		assert.Equal(t, "tool 'delete_record' requires scopes records:write", rpcErr.Message)
+		assert.Equal(t, map[string]any{"tool": "delete_record", "missingScopes": []string{"records:write"}}, rpcErr.Data)
+
+		assert.IsType(t, mcp.JSONRPCResponse{}, send("admin", "tools/call", `{"name": "delete_record"}`))
+		assert.IsType(t, mcp.JSONRPCResponse{}, send("", "tools/call", `{"name": "echo"}`))
+
+		for _, request := range []struct{ method, params string }{
+			{"prompts/get", `{"name": "audit"}`},
+			{"resources/read", `{"uri": "admin://config"}`},
+			{"resources/read", `{"uri": "admin://users/42"}`},
+		} {
+			response := send("reader", request.method, request.params)
+			require.IsType(t, mcp.JSONRPCError{}, response, request)
+			assert.Equal(t, mcp.FORBIDDEN, response.(mcp.JSONRPCError).Error.Code, request)
+			assert.IsType(t, mcp.JSONRPCResponse{}, send("admin", request.method, request.params), request)
+		}
+	})
+
+	t.Run("completions and subscriptions are rejected", func(t *testing.T) {
+		session := &sessionTestClient{sessionID: "session-1", initialized: true}
+		sendWithSession := func(principal, method, params string) mcp.JSONRPCMessage {
+			ctx := server.WithContext(context.WithValue(context.Background(), testPrincipalKey{}, principal), session)
+			return server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "`+method+`", "params": `+params+`}`))
+		}
+
+		for _, request := range []struct{ method, params string }{
+			{"completion/complete", `{"ref": {"type": "ref/prompt", "name": "audit"}, "argument": {"name": "a", "value": ""}}`},
+			{"completion/complete", `{"ref": {"type": "ref/resource", "uri": "admin://users/{id}"}, "argument": {"name": "id", "value": ""}}`},
+			{"completion/complete", `{"ref": {"type": "ref/resource", "uri": "admin://users/42"}, "argument": {"name": "id", "value": ""}}`},
+			{"resources/subscribe", `{"uri": "admin://config"}`},
+			{"resources/subscribe", `{"uri": "admin://users/{id}"}`},
+			{"resources/subscribe", `{"uri": "admin://users/42"}`},
+		} {
+			response := sendWithSession("reader", request.method, request.params)
+			require.IsType(t, mcp.JSONRPCError{}, response, request)
+			assert.Equal(t, mcp.FORBIDDEN, response.(mcp.JSONRPCError).Error.Code, request)
+			assert.IsType(t, mcp.JSONRPCResponse{}, sendWithSession("admin", request.method, request.params), request)
+		}
+		assert.IsType(t, mcp.JSONRPCResponse{}, sendWithSession("reader", "resources/subscribe", `{"uri": "docs://readme"}`))
+	})
+
+	t.Run("session items", func(t *testing.T) {
+		session := &sessionTestClientWithTools{sessionID: "session-1", initialized: true}
+		session.SetSessionTools(map[string]ServerTool{
+			// overrides the global tool
+			"echo": {Tool: mcp.NewTool("echo"), Handler: toolHandler, RequiredScopes: []string{"admin"}},
+		})
+
+		ctx := server.WithContext(context.WithValue(context.Background(), testPrincipalKey{}, "reader"), session)
+		response := server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "echo"}}`))
+		require.IsType(t, mcp.JSONRPCError{}, response)
+		assert.Equal(t, mcp.FORBIDDEN, response.(mcp.JSONRPCError).Error.Code)
+	})
+}
+
+func TestMCPServer_PrincipalScopesFromTokenClaims(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddTools(ServerTool{
+		Tool: mcp.NewTool("delete_record"),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("deleted"), nil
+		},
+		RequiredScopes: []string{"records:write"},
+	})
+	call := func(claims *TokenClaims) mcp.JSONRPCMessage {
+		ctx := context.WithValue(context.Background(), tokenClaimsKey{}, claims)
+		return server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "delete_record"}}`))
+	}
+
+	assert.IsType(t, mcp.JSONRPCError{}, call(&TokenClaims{Subject: "alice", Scopes: []string{"records:read"}}))
+	assert.IsType(t, mcp.JSONRPCResponse{}, call(&TokenClaims{Subject: "alice", Scopes: []string{"records:write"}}))
+}
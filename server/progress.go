@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultProgressInterval is the minimum interval between two progress
+// notifications of a request, unless set with WithProgressInterval
+const defaultProgressInterval = 100 * time.Millisecond
+
+// progressReporterKey is the context key for the progress reporter of a request
+type progressReporterKey struct{}
+
+// ProgressReporter sends notifications/progress to the client for the request
+// being handled. A nil ProgressReporter is valid and reports nothing, which is
+// what ProgressFromContext returns when the client didn't ask for progress.
+type ProgressReporter struct {
+	server   *MCPServer
+	ctx      context.Context
+	token    mcp.ProgressToken
+	interval time.Duration
+
+	mu           sync.Mutex
+	sent         bool
+	lastSent     time.Time
+	lastProgress float64
+}
+
+// ProgressFromContext returns the progress reporter of the request being handled.
+// It's meant to be called from within a tool, resource or prompt handler.
+//
+//	progress := server.ProgressFromContext(ctx)
+//	for i, item := range items {
+//		process(item)
+//		progress.Report(float64(i+1), float64(len(items)), "processing "+item.Name)
+//	}
+func ProgressFromContext(ctx context.Context) *ProgressReporter {
+	reporter, _ := ctx.Value(progressReporterKey{}).(*ProgressReporter)
+	return reporter
+}
+
+// Token returns the progress token sent by the client, or nil if the client
+// didn't ask for progress notifications
+func (p *ProgressReporter) Token() mcp.ProgressToken {
+	if p == nil {
+		return nil
+	}
+	return p.token
+}
+
+// Report sends the progress made so far to the client. total is the progress
+// required to complete the request, or 0 if unknown, and message an optional
+// human-readable description of the progress.
+//
+// It sends nothing if the client didn't ask for progress notifications, if the
+// progress didn't increase since the last notification, or if the last one was
+// sent less than the progress interval ago (see WithProgressInterval). The
+// notification completing the progress (progress >= total) is never dropped by
+// the rate limit.
+func (p *ProgressReporter) Report(progress, total float64, message string) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// progress must increase with each notification
+	if p.sent && progress <= p.lastProgress {
+		return nil
+	}
+	complete := total > 0 && progress >= total
+	now := time.Now()
+	if p.sent && !complete && now.Sub(p.lastSent) < p.interval {
+		return nil
+	}
+
+	params := map[string]any{
+		"progressToken": p.token,
+		"progress":      progress,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	if err := p.server.SendNotificationToClient(p.ctx, mcp.MethodNotificationProgress, params); err != nil {
+		return err
+	}
+
+	p.sent = true
+	p.lastSent = now
+	p.lastProgress = progress
+	return nil
+}
+
+// withProgressReporter adds a progress reporter to the context of a request if
+// its _meta holds a progress token
+func (s *MCPServer) withProgressReporter(ctx context.Context, message json.RawMessage) context.Context {
+	var request struct {
+		Params struct {
+			Meta struct {
+				ProgressToken mcp.ProgressToken `json:"progressToken"`
+			} `json:"_meta"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil || request.Params.Meta.ProgressToken == nil {
+		return ctx
+	}
+
+	reporter := &ProgressReporter{
+		server:   s,
+		token:    request.Params.Meta.ProgressToken,
+		interval: s.progressInterval,
+	}
+	ctx = context.WithValue(ctx, progressReporterKey{}, reporter)
+	reporter.ctx = ctx
+	return ctx
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newProgressTestServer creates a server with a tool that makes the given reports
+func newProgressTestServer(reports [][2]float64, opts ...ServerOption) *MCPServer {
+	server := NewMCPServer("test-server", "1.0.0", opts...)
+	server.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		progress := ProgressFromContext(ctx)
+		for _, report := range reports {
+			if err := progress.Report(report[0], report[1], fmt.Sprintf("step %v", report[0])); err != nil {
+				return nil, err
+			}
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	return server
+}
+
+func callWithProgress(t *testing.T, server *MCPServer, meta string) []mcp.JSONRPCNotification {
+	t.Helper()
+	session := &sessionTestClient{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
+		initialized:         true,
+	}
+	ctx := server.WithContext(context.Background(), session)
+	response := server.HandleMessage(ctx, []byte(fmt.Sprintf(
+		`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "work"%s}}`, meta,
+	)))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+	var notifications []mcp.JSONRPCNotification
+	for {
+		select {
+		case notification := <-session.notificationChannel:
+			require.Equal(t, mcp.MethodNotificationProgress, notification.Method)
+			notifications = append(notifications, notification)
+		default:
+			return notifications
+		}
+	}
+}
+
+func TestProgressReporter(t *testing.T) {
+	t.Run("nothing is sent without progress token", func(t *testing.T) {
+		server := newProgressTestServer([][2]float64{{1, 2}, {2, 2}})
+		assert.Empty(t, callWithProgress(t, server, ""))
+	})
+
+	t.Run("nil reporter is a no-op", func(t *testing.T) {
+		var progress *ProgressReporter
+		assert.Nil(t, ProgressFromContext(context.Background()))
+		assert.NoError(t, progress.Report(1, 2, "step"))
+		assert.Nil(t, progress.Token())
+	})
+
+	t.Run("progress is sent with the token of the request", func(t *testing.T) {
+		server := newProgressTestServer([][2]float64{{1, 3}, {2, 3}, {3, 3}}, WithProgressInterval(0))
+		notifications := callWithProgress(t, server, `, "_meta": {"progressToken": "token-1"}`)
+		require.Len(t, notifications, 3)
+		for i, notification := range notifications {
+			fields := notification.Params.AdditionalFields
+			assert.Equal(t, "token-1", fields["progressToken"])
+			assert.Equal(t, float64(i+1), fields["progress"])
+			assert.Equal(t, float64(3), fields["total"])
+			assert.Equal(t, fmt.Sprintf("step %d", i+1), fields["message"])
+		}
+	})
+
+	t.Run("unknown total and empty message are omitted", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		server.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("done"), ProgressFromContext(ctx).Report(1, 0, "")
+		})
+		notifications := callWithProgress(t, server, `, "_meta": {"progressToken": 42}`)
+		require.Len(t, notifications, 1)
+		assert.Equal(t, map[string]any{"progressToken": float64(42), "progress": float64(1)}, notifications[0].Params.AdditionalFields)
+	})
+
+	t.Run("progress must increase", func(t *testing.T) {
+		server := newProgressTestServer([][2]float64{{1, 0}, {1, 0}, {0.5, 0}, {2, 0}}, WithProgressInterval(0))
+		notifications := callWithProgress(t, server, `, "_meta": {"progressToken": 1}`)
+		require.Len(t, notifications, 2)
+		assert.Equal(t, float64(1), notifications[0].Params.AdditionalFields["progress"])
+		assert.Equal(t, float64(2), notifications[1].Params.AdditionalFields["progress"])
+	})
+
+	t.Run("reports are rate limited except for completion", func(t *testing.T) {
+		var reports [][2]float64
+		for i := 1; i <= 100; i++ {
+			reports = append(reports, [2]float64{float64(i), 100})
+		}
+		server := newProgressTestServer(reports, WithProgressInterval(time.Hour))
+		notifications := callWithProgress(t, server, `, "_meta": {"progressToken": 1}`)
+		require.Len(t, notifications, 2)
+		assert.Equal(t, float64(1), notifications[0].Params.AdditionalFields["progress"])
+		assert.Equal(t, float64(100), notifications[1].Params.AdditionalFields["progress"])
+	})
+}
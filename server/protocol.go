@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// protocolVersion20250618 introduced titles, _meta, resource links, tool output
+// schemas and structured tool results
+const protocolVersion20250618 = "2025-06-18"
+
+// clientProtocolVersion returns the protocol version used by the client of the
+// session in ctx, or the latest version if it is unknown
+func clientProtocolVersion(ctx context.Context) string {
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithProtocolVersion); ok {
+		if version := session.GetProtocolVersion(); version != "" {
+			return version
+		}
+	}
+	return mcp.LATEST_PROTOCOL_VERSION
+}
+
+// clientSupportsProtocolVersion reports whether the client of the session in ctx
+// uses the given protocol version or a later one. Protocol versions are dates,
+// so they sort lexically.
+func clientSupportsProtocolVersion(ctx context.Context, version string) bool {
+	return clientProtocolVersion(ctx) >= version
+}
+
+// The downgrade functions below remove the fields introduced in 2025-06-18 from
+// results sent to older clients. They copy the values they change, since the
+// originals are shared with other sessions.
+
+func downgradeTools(tools []mcp.Tool) []mcp.Tool {
+	downgraded := make([]mcp.Tool, len(tools))
+	for i, tool := range tools {
+		tool.Title = ""
+		tool.Meta = nil
+		tool.OutputSchema = mcp.ToolOutputSchema{}
+		tool.RawOutputSchema = nil
+		downgraded[i] = tool
+	}
+	return downgraded
+}
+
+func downgradeResources(resources []mcp.Resource) []mcp.Resource {
+	downgraded := make([]mcp.Resource, len(resources))
+	for i, resource := range resources {
+		resource.Title = ""
+		resource.Meta = nil
+		downgraded[i] = resource
+	}
+	return downgraded
+}
+
+func downgradeResourceTemplates(templates []mcp.ResourceTemplate) []mcp.ResourceTemplate {
+	downgraded := make([]mcp.ResourceTemplate, len(templates))
+	for i, template := range templates {
+		template.Title = ""
+		template.Meta = nil
+		downgraded[i] = template
+	}
+	return downgraded
+}
+
+func downgradePrompts(prompts []mcp.Prompt) []mcp.Prompt {
+	downgraded := make([]mcp.Prompt, len(prompts))
+	for i, prompt := range prompts {
+		prompt.Title = ""
+		prompt.Meta = nil
+		if prompt.Arguments != nil {
+			arguments := make([]mcp.PromptArgument, len(prompt.Arguments))
+			for j, argument := range prompt.Arguments {
+				argument.Title = ""
+				arguments[j] = argument
+			}
+			prompt.Arguments = arguments
+		}
+		downgraded[i] = prompt
+	}
+	return downgraded
+}
+
+func downgradeCallToolResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	downgraded := *result
+	downgraded.StructuredContent = nil
+	downgraded.Content = downgradeContent(result.Content)
+	return &downgraded
+}
+
+func downgradeGetPromptResult(result *mcp.GetPromptResult) *mcp.GetPromptResult {
+	downgraded := *result
+	downgraded.Messages = make([]mcp.PromptMessage, len(result.Messages))
+	for i, message := range result.Messages {
+		message.Content = downgradeContent([]mcp.Content{message.Content})[0]
+		downgraded.Messages[i] = message
+	}
+	return &downgraded
+}
+
+// downgradeContent replaces resource links, which older clients can't parse,
+// with text content holding the URI of the linked resource
+func downgradeContent(content []mcp.Content) []mcp.Content {
+	downgraded := make([]mcp.Content, len(content))
+	for i, c := range content {
+		if link, ok := mcp.AsResourceLink(c); ok {
+			c = mcp.TextContent{
+				Annotated: link.Annotated,
+				Type:      "text",
+				Text:      link.URI,
+			}
+		}
+		downgraded[i] = c
+	}
+	return downgraded
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sessionTestClientWithProtocolVersion implements SessionWithProtocolVersion for testing
+type sessionTestClientWithProtocolVersion struct {
+	sessionTestClient
+	mu              sync.Mutex
+	protocolVersion string
+}
+
+func (f *sessionTestClientWithProtocolVersion) GetProtocolVersion() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.protocolVersion
+}
+
+func (f *sessionTestClientWithProtocolVersion) SetProtocolVersion(version string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.protocolVersion = version
+}
+
+var _ SessionWithProtocolVersion = (*sessionTestClientWithProtocolVersion)(nil)
+
+// newProtocolTestServer creates a server using the fields introduced in 2025-06-18
+func newProtocolTestServer() *MCPServer {
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddTool(mcp.NewTool("weather",
+		mcp.WithTitle("Weather"),
+		mcp.WithOutputSchema(map[string]any{"temperature": map[string]any{"type": "number"}}),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultStructured(map[string]any{"temperature": 21}, "21°C")
+		result.Content = append(result.Content, mcp.NewResourceLink("weather://today", "today", "", "application/json"))
+		return result, nil
+	})
+	server.AddResource(mcp.NewResource("weather://today", "today", mcp.WithResourceTitle("Today")),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return nil, nil
+		})
+	server.AddPrompt(mcp.NewPrompt("forecast",
+		mcp.WithPromptTitle("Forecast"),
+		mcp.WithArgument("city", mcp.ArgumentTitle("City")),
+	), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult("forecast", []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewResourceLink("weather://today", "today", "", "")),
+		}), nil
+	})
+	return server
+}
+
+func TestMCPServer_ProtocolVersionGating(t *testing.T) {
+	handle := func(t *testing.T, server *MCPServer, ctx context.Context, message string) any {
+		t.Helper()
+		response, ok := server.HandleMessage(ctx, []byte(message)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		return response.Result
+	}
+	initialize := func(t *testing.T, server *MCPServer, version string) context.Context {
+		t.Helper()
+		session := &sessionTestClientWithProtocolVersion{}
+		ctx := server.WithContext(context.Background(), session)
+		result := handle(t, server, ctx, fmt.Sprintf(
+			`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": %q}}`, version,
+		)).(mcp.InitializeResult)
+		require.Equal(t, version, result.ProtocolVersion)
+		require.Equal(t, version, session.GetProtocolVersion())
+		return ctx
+	}
+
+	t.Run("2025-06-18 clients get every field", func(t *testing.T) {
+		server := newProtocolTestServer()
+		ctx := initialize(t, server, "2025-06-18")
+
+		tools := handle(t, server, ctx, `{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`).(mcp.ListToolsResult)
+		assert.Equal(t, "Weather", tools.Tools[0].Title)
+		assert.Equal(t, "object", tools.Tools[0].OutputSchema.Type)
+
+		result := handle(t, server, ctx, `{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "weather"}}`).(mcp.CallToolResult)
+		assert.NotNil(t, result.StructuredContent)
+		_, ok := mcp.AsResourceLink(result.Content[1])
+		assert.True(t, ok)
+
+		resources := handle(t, server, ctx, `{"jsonrpc": "2.0", "id": 4, "method": "resources/list"}`).(mcp.ListResourcesResult)
+		assert.Equal(t, "Today", resources.Resources[0].Title)
+
+		prompts := handle(t, server, ctx, `{"jsonrpc": "2.0", "id": 5, "method": "prompts/list"}`).(mcp.ListPromptsResult)
+		assert.Equal(t, "Forecast", prompts.Prompts[0].Title)
+		assert.Equal(t, "City", prompts.Prompts[0].Arguments[0].Title)
+	})
+
+	t.Run("older clients don't get the new fields", func(t *testing.T) {
+		server := newProtocolTestServer()
+		ctx := initialize(t, server, "2025-03-26")
+
+		tools := handle(t, server, ctx, `{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`).(mcp.ListToolsResult)
+		assert.Empty(t, tools.Tools[0].Title)
+		assert.Empty(t, tools.Tools[0].OutputSchema.Type)
+
+		result := handle(t, server, ctx, `{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "weather"}}`).(mcp.CallToolResult)
+		assert.Nil(t, result.StructuredContent)
+		text, ok := mcp.AsTextContent(result.Content[1])
+		require.True(t, ok, "resource links are downgraded to text")
+		assert.Equal(t, "weather://today", text.Text)
+
+		resources := handle(t, server, ctx, `{"jsonrpc": "2.0", "id": 4, "method": "resources/list"}`).(mcp.ListResourcesResult)
+		assert.Empty(t, resources.Resources[0].Title)
+
+		prompts := handle(t, server, ctx, `{"jsonrpc": "2.0", "id": 5, "method": "prompts/list"}`).(mcp.ListPromptsResult)
+		assert.Empty(t, prompts.Prompts[0].Title)
+		assert.Empty(t, prompts.Prompts[0].Arguments[0].Title)
+
+		prompt := handle(t, server, ctx, `{"jsonrpc": "2.0", "id": 6, "method": "prompts/get", "params": {"name": "forecast"}}`).(mcp.GetPromptResult)
+		_, ok = mcp.AsTextContent(prompt.Messages[0].Content)
+		assert.True(t, ok, "resource links are downgraded to text")
+
+		// the registered items are left untouched
+		newCtx := initialize(t, server, "2025-06-18")
+		tools = handle(t, server, newCtx, `{"jsonrpc": "2.0", "id": 7, "method": "tools/list"}`).(mcp.ListToolsResult)
+		assert.Equal(t, "Weather", tools.Tools[0].Title)
+	})
+
+	t.Run("unknown version defaults to latest", func(t *testing.T) {
+		assert.Equal(t, mcp.LATEST_PROTOCOL_VERSION, clientProtocolVersion(context.Background()))
+	})
+}
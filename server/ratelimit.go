@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitScope tells whose calls share a rate limit
+type RateLimitScope string
+
+const (
+	// RateLimitPerSession limits the calls of each session
+	RateLimitPerSession RateLimitScope = "session"
+	// RateLimitPerPrincipal limits the calls of each authenticated principal,
+	// across all its sessions (see WithPrincipalExtractor). Calls without a
+	// principal aren't limited.
+	RateLimitPerPrincipal RateLimitScope = "principal"
+	// RateLimitGlobal limits the calls of all clients together
+	RateLimitGlobal RateLimitScope = "global"
+)
+
+// RateLimit limits the tool calls of a scope with a token bucket rate, an
+// absolute quota, or both. Calls exceeding a limit are rejected with a
+// *RateLimitError.
+type RateLimit struct {
+	// Scope tells whose calls share the limit
+	Scope RateLimitScope
+	// Tool is the name of the tool whose calls are limited, or empty to limit
+	// the calls of all tools together
+	Tool string
+
+	// Rate is the number of calls allowed per second on average, or 0 for no
+	// rate limit
+	Rate float64
+	// Burst is the number of calls that can be made at once, at least 1
+	Burst int
+
+	// Quota is the number of calls allowed per QuotaPeriod, or 0 for no quota
+	Quota int64
+	// QuotaPeriod is the period after which the quota is reset, or 0 for a
+	// quota that is never reset
+	QuotaPeriod time.Duration
+}
+
+// RateLimitStore keeps the state of the rate limits. Implementations must be
+// safe for concurrent use, and can share the state between the replicas of a
+// server, e.g. in a database.
+type RateLimitStore interface {
+	// TakeToken takes a token from the bucket of key, which holds up to burst
+	// tokens and is refilled with rate tokens per second. If the bucket is
+	// empty, it returns false and the time until a token is available.
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+	// ConsumeQuota counts a call against the quota of key, which allows quota
+	// calls per period, or ever if period is 0. If the quota is exhausted, it
+	// returns false and the time until the quota is reset, or 0 if it never is.
+	ConsumeQuota(ctx context.Context, key string, quota int64, period time.Duration) (bool, time.Duration, error)
+	// ReturnToken puts back a token taken from the bucket of key, for a call
+	// refused by another limit
+	ReturnToken(ctx context.Context, key string, rate float64, burst int) error
+	// RefundQuota uncounts a call counted against the quota of key, for a call
+	// refused by another limit
+	RefundQuota(ctx context.Context, key string, quota int64, period time.Duration) error
+}
+
+// WithRateLimit adds a limit to the tool calls. Limits are checked in the order
+// they are added, and a call must be allowed by all of them.
+func WithRateLimit(limit RateLimit) ServerOption {
+	return func(s *MCPServer) {
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+		s.rateLimits = append(s.rateLimits, limit)
+	}
+}
+
+// WithRateLimitStore sets the store keeping the state of the rate limits,
+// instead of the memory of the server
+func WithRateLimitStore(store RateLimitStore) ServerOption {
+	return func(s *MCPServer) {
+		s.rateLimitStore = store
+	}
+}
+
+// checkRateLimits checks the call of a tool against the rate limits, and
+// returns a *RateLimitError if a limit is exceeded. The tokens and quotas
+// consumed by a refused call are given back, so that only allowed calls count.
+func (s *MCPServer) checkRateLimits(ctx context.Context, tool string) (err error) {
+	if len(s.rateLimits) == 0 {
+		return nil
+	}
+	var refunds []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(refunds) - 1; i >= 0; i-- {
+			if refundErr := refunds[i](); refundErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to refund rate limit: %w", refundErr))
+			}
+		}
+	}()
+
+	for i, limit := range s.rateLimits {
+		if limit.Tool != "" && limit.Tool != tool {
+			continue
+		}
+		key, ok := s.rateLimitKey(ctx, i, limit, tool)
+		if !ok {
+			continue
+		}
+		if limit.Rate > 0 {
+			allowed, retryAfter, err := s.rateLimitStore.TakeToken(ctx, key+"/rate", limit.Rate, limit.Burst)
+			if err != nil {
+				return fmt.Errorf("failed to check rate limit: %w", err)
+			}
+			if !allowed {
+				return &RateLimitError{Scope: limit.Scope, Tool: limit.Tool, RetryAfter: retryAfter}
+			}
+			refunds = append(refunds, func() error {
+				return s.rateLimitStore.ReturnToken(ctx, key+"/rate", limit.Rate, limit.Burst)
+			})
+		}
+		if limit.Quota > 0 {
+			allowed, retryAfter, err := s.rateLimitStore.ConsumeQuota(ctx, key+"/quota", limit.Quota, limit.QuotaPeriod)
+			if err != nil {
+				return fmt.Errorf("failed to check quota: %w", err)
+			}
+			if !allowed {
+				return &RateLimitError{Scope: limit.Scope, Tool: limit.Tool, Quota: true, RetryAfter: retryAfter}
+			}
+			refunds = append(refunds, func() error {
+				return s.rateLimitStore.RefundQuota(ctx, key+"/quota", limit.Quota, limit.QuotaPeriod)
+			})
+		}
+	}
+	return nil
+}
+
+// rateLimitKey returns the key of the state of the i-th limit for the caller of
+// the request, or false if the limit doesn't apply to the caller
+func (s *MCPServer) rateLimitKey(ctx context.Context, i int, limit RateLimit, tool string) (string, bool) {
+	key := fmt.Sprintf("mcp/%d/%s", i, limit.Scope)
+	switch limit.Scope {
+	case RateLimitPerSession:
+		session := ClientSessionFromContext(ctx)
+		if session == nil || session.SessionID() == "" {
+			return "", false
+		}
+		key += "/" + session.SessionID()
+	case RateLimitPerPrincipal:
+		principal := s.principal(ctx)
+		if principal == nil || principal.ID == "" {
+			return "", false
+		}
+		key += "/" + principal.ID
+	}
+	if limit.Tool != "" {
+		key += "/" + tool
+	}
+	return key, true
+}
+
+// MemoryRateLimitStore is a RateLimitStore keeping the state of the rate limits
+// in memory. It's the store of servers without WithRateLimitStore.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	quotas  map[string]*quotaCounter
+	calls   int
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket is full again, and can be forgotten
+	full time.Time
+}
+
+type quotaCounter struct {
+	count int64
+	// reset is when the period ends, or zero if it never does
+	reset time.Time
+}
+
+// memoryStoreSweepInterval is the number of calls after which the state that
+// is back to its initial value is dropped from a MemoryRateLimitStore
+const memoryStoreSweepInterval = 1024
+
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+// NewMemoryRateLimitStore creates an empty in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		quotas:  make(map[string]*quotaCounter),
+		now:     time.Now,
+	}
+}
+
+// TakeToken implements RateLimitStore
+func (m *MemoryRateLimitStore) TakeToken(_ context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.sweep(now)
+
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(burst), updated: now}
+		m.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(float64(burst), bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+	if bucket.tokens < 1 {
+		return false, secondsToDuration((1 - bucket.tokens) / rate), nil
+	}
+	bucket.tokens--
+	bucket.full = now.Add(secondsToDuration((float64(burst) - bucket.tokens) / rate))
+	return true, 0, nil
+}
+
+// ConsumeQuota implements RateLimitStore
+func (m *MemoryRateLimitStore) ConsumeQuota(_ context.Context, key string, quota int64, period time.Duration) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.sweep(now)
+
+	counter, ok := m.quotas[key]
+	if !ok || (!counter.reset.IsZero() && !now.Before(counter.reset)) {
+		counter = &quotaCounter{}
+		if period > 0 {
+			counter.reset = now.Add(period)
+		}
+		m.quotas[key] = counter
+	}
+	if counter.count >= quota {
+		if counter.reset.IsZero() {
+			return false, 0, nil
+		}
+		return false, counter.reset.Sub(now), nil
+	}
+	counter.count++
+	return true, 0, nil
+}
+
+// ReturnToken implements RateLimitStore
+func (m *MemoryRateLimitStore) ReturnToken(_ context.Context, key string, rate float64, burst int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket, ok := m.buckets[key]
+	if !ok {
+		return nil
+	}
+	bucket.tokens = math.Min(float64(burst), bucket.tokens+1)
+	bucket.full = bucket.updated.Add(secondsToDuration((float64(burst) - bucket.tokens) / rate))
+	return nil
+}
+
+// RefundQuota implements RateLimitStore
+func (m *MemoryRateLimitStore) RefundQuota(_ context.Context, key string, _ int64, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if counter, ok := m.quotas[key]; ok && counter.count > 0 {
+		counter.count--
+	}
+	return nil
+}
+
+// sweep periodically drops the buckets that are full and the quotas that are
+// reset, as they are equivalent to missing ones
+func (m *MemoryRateLimitStore) sweep(now time.Time) {
+	m.calls++
+	if m.calls < memoryStoreSweepInterval {
+		return
+	}
+	m.calls = 0
+	for key, bucket := range m.buckets {
+		if !now.Before(bucket.full) {
+			delete(m.buckets, key)
+		}
+	}
+	for key, counter := range m.quotas {
+		if !counter.reset.IsZero() && !now.Before(counter.reset) {
+			delete(m.quotas, key)
+		}
+	}
+}
+
+// secondsToDuration converts seconds to a duration, rounded up to the millisecond
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds*1000)) * time.Millisecond
+}
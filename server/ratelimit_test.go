@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMemoryRateLimitStore_TakeToken(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+
+	// 2 calls per second, up to 3 at once
+	for range 3 {
+		allowed, _, err := store.TakeToken(ctx, "key", 2, 3)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, retryAfter, err := store.TakeToken(ctx, "key", 2, 3)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	allowed, _, _ = store.TakeToken(ctx, "other", 2, 3)
+	assert.True(t, allowed, "keys have their own bucket")
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, _, _ = store.TakeToken(ctx, "key", 2, 3)
+	assert.True(t, allowed)
+	allowed, _, _ = store.TakeToken(ctx, "key", 2, 3)
+	assert.False(t, allowed)
+}
+
+func TestMemoryRateLimitStore_ConsumeQuota(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+
+	for range 2 {
+		allowed, _, err := store.ConsumeQuota(ctx, "hourly", 2, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	now = now.Add(15 * time.Minute)
+	allowed, retryAfter, err := store.ConsumeQuota(ctx, "hourly", 2, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 45*time.Minute, retryAfter)
+
+	now = now.Add(45 * time.Minute)
+	allowed, _, _ = store.ConsumeQuota(ctx, "hourly", 2, time.Hour)
+	assert.True(t, allowed, "the quota is reset after the period")
+
+	allowed, _, _ = store.ConsumeQuota(ctx, "total", 1, 0)
+	assert.True(t, allowed)
+	now = now.Add(24 * time.Hour)
+	allowed, retryAfter, _ = store.ConsumeQuota(ctx, "total", 1, 0)
+	assert.False(t, allowed)
+	assert.Zero(t, retryAfter, "absolute quotas are never reset")
+}
+
+func TestMemoryRateLimitStore_Refunds(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return time.Unix(0, 0) }
+
+	allowed, _, _ := store.TakeToken(ctx, "bucket", 1, 1)
+	assert.True(t, allowed)
+	require.NoError(t, store.ReturnToken(ctx, "bucket", 1, 1))
+	allowed, _, _ = store.TakeToken(ctx, "bucket", 1, 1)
+	assert.True(t, allowed, "returned tokens can be taken again")
+	require.NoError(t, store.ReturnToken(ctx, "bucket", 1, 1))
+	require.NoError(t, store.ReturnToken(ctx, "bucket", 1, 1))
+	assert.Equal(t, 1.0, store.buckets["bucket"].tokens, "buckets hold at most burst tokens")
+
+	allowed, _, _ = store.ConsumeQuota(ctx, "quota", 1, 0)
+	assert.True(t, allowed)
+	require.NoError(t, store.RefundQuota(ctx, "quota", 1, 0))
+	allowed, _, _ = store.ConsumeQuota(ctx, "quota", 1, 0)
+	assert.True(t, allowed, "refunded calls can be made again")
+	allowed, _, _ = store.ConsumeQuota(ctx, "quota", 1, 0)
+	assert.False(t, allowed)
+}
+
+func TestMemoryRateLimitStore_Sweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+
+	_, _, _ = store.TakeToken(ctx, "bucket", 1, 1)
+	_, _, _ = store.ConsumeQuota(ctx, "periodic", 1, time.Second)
+	_, _, _ = store.ConsumeQuota(ctx, "absolute", 1, 0)
+	now = now.Add(time.Minute)
+	for range memoryStoreSweepInterval {
+		_, _, _ = store.TakeToken(ctx, "active", 1000, 1000)
+	}
+
+	assert.NotContains(t, store.buckets, "bucket")
+	assert.NotContains(t, store.quotas, "periodic")
+	assert.Contains(t, store.quotas, "absolute")
+}
+
+func TestMCPServer_RateLimits(t *testing.T) {
+	callTool := func(server *MCPServer, session ClientSession, principal string, tool string) mcp.JSONRPCMessage {
+		ctx := context.WithValue(context.Background(), testPrincipalKey{}, principal)
+		ctx = server.WithContext(ctx, session)
+		return server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "`+tool+`"}}`))
+	}
+	newServer := func(opts ...ServerOption) *MCPServer {
+		server := NewMCPServer("test-server", "1.0.0", append(opts, WithPrincipalExtractor(func(ctx context.Context) *Principal {
+			if id, _ := ctx.Value(testPrincipalKey{}).(string); id != "" {
+				return &Principal{ID: id}
+			}
+			return nil
+		}))...)
+		for _, name := range []string{"cheap", "expensive"} {
+			server.AddTool(mcp.NewTool(name), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("done"), nil
+			})
+		}
+		return server
+	}
+	session1 := &sessionTestClient{sessionID: "session-1", initialized: true}
+	session2 := &sessionTestClient{sessionID: "session-2", initialized: true}
+
+	t.Run("per session rate", func(t *testing.T) {
+		server := newServer(WithRateLimit(RateLimit{Scope: RateLimitPerSession, Rate: 0.001, Burst: 2}))
+
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session1, "", "cheap"))
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session1, "", "expensive"))
+		response := callTool(server, session1, "", "cheap")
+		require.IsType(t, mcp.JSONRPCError{}, response)
+		rpcErr := response.(mcp.JSONRPCError).Error
+		assert.Equal(t, mcp.RATE_LIMITED, rpcErr.Code)
+		data := rpcErr.Data.(map[string]any)
+		assert.Equal(t, RateLimitPerSession, data["scope"])
+		assert.Equal(t, "rate", data["limit"])
+		assert.InDelta(t, 1000, data["retryAfter"], 1)
+
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session2, "", "cheap"), "sessions have their own limit")
+	})
+
+	t.Run("per tool quota", func(t *testing.T) {
+		server := newServer(WithRateLimit(RateLimit{Scope: RateLimitGlobal, Tool: "expensive", Quota: 1}))
+
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session1, "", "expensive"))
+		response := callTool(server, session2, "", "expensive")
+		require.IsType(t, mcp.JSONRPCError{}, response)
+		assert.Equal(t, map[string]any{
+			"scope": RateLimitGlobal,
+			"limit": "quota",
+			"tool":  "expensive",
+		}, response.(mcp.JSONRPCError).Error.Data)
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session2, "", "cheap"), "other tools aren't limited")
+	})
+
+	t.Run("per principal quota", func(t *testing.T) {
+		server := newServer(WithRateLimit(RateLimit{Scope: RateLimitPerPrincipal, Quota: 1, QuotaPeriod: time.Hour}))
+
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session1, "alice", "cheap"))
+		assert.IsType(t, mcp.JSONRPCError{}, callTool(server, session2, "alice", "cheap"), "principals share their limit across sessions")
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session2, "bob", "cheap"))
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session2, "", "cheap"), "anonymous calls aren't limited")
+	})
+
+	t.Run("refused calls don't count", func(t *testing.T) {
+		server := newServer(
+			WithRateLimit(RateLimit{Scope: RateLimitGlobal, Quota: 2}),
+			WithRateLimit(RateLimit{Scope: RateLimitPerSession, Rate: 0.001, Burst: 1}),
+		)
+		session3 := &sessionTestClient{sessionID: "session-3", initialized: true}
+
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session1, "", "cheap"))
+		response := callTool(server, session1, "", "cheap")
+		require.IsType(t, mcp.JSONRPCError{}, response)
+		assert.Equal(t, "rate", response.(mcp.JSONRPCError).Error.Data.(map[string]any)["limit"])
+
+		// the call refused by the rate limit is refunded to the quota
+		assert.IsType(t, mcp.JSONRPCResponse{}, callTool(server, session2, "", "cheap"))
+		response = callTool(server, session3, "", "cheap")
+		require.IsType(t, mcp.JSONRPCError{}, response)
+		assert.Equal(t, "quota", response.(mcp.JSONRPCError).Error.Data.(map[string]any)["limit"])
+	})
+}
+
+type testPrincipalKey struct{}
+
+func TestRateLimitError(t *testing.T) {
+	err := &RateLimitError{Scope: RateLimitPerSession, Tool: "search", RetryAfter: 1500 * time.Millisecond}
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.EqualError(t, err, "session rate limit exceeded for tool 'search', retry after 1.5s")
+	assert.EqualError(t, &RateLimitError{Scope: RateLimitGlobal, Quota: true}, "global quota exceeded")
+}
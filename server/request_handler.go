@@ -5,25 +5,38 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
-// HandleMessage processes an incoming JSON-RPC message and returns an appropriate response
+// HandleMessage processes an incoming JSON-RPC message or batch and returns an appropriate response
 func (s *MCPServer) HandleMessage(
 	ctx context.Context,
 	message json.RawMessage,
+) mcp.JSONRPCMessage {
+	if isBatch(message) {
+		return s.handleBatch(ctx, message)
+	}
+	return s.handleMessage(ctx, message)
+}
+
+// handleMessage processes a single JSON-RPC message and returns an appropriate response
+func (s *MCPServer) handleMessage(
+	ctx context.Context,
+	message json.RawMessage,
 ) mcp.JSONRPCMessage {
 	// Add server to context
 	ctx = context.WithValue(ctx, serverKey{}, s)
-	var err *requestError
 
 	var baseMessage struct {
 		JSONRPC string        `json:"jsonrpc"`
 		Method  mcp.MCPMethod `json:"method"`
 		ID      any           `json:"id,omitempty"`
 		Result  any           `json:"result,omitempty"`
+		Error   any           `json:"error,omitempty"`
 	}
 
 	if err := json.Unmarshal(message, &baseMessage); err != nil {
@@ -43,6 +56,9 @@ func (s *MCPServer) HandleMessage(
 		)
 	}
 
+	// Continue the trace of the client, if it sent its trace context
+	ctx = extractTraceContext(ctx, message)
+
 	if baseMessage.ID == nil {
 		var notification mcp.JSONRPCNotification
 		if err := json.Unmarshal(message, &notification); err != nil {
@@ -56,9 +72,10 @@ func (s *MCPServer) HandleMessage(
 		return nil // Return nil for notifications
 	}
 
-	if baseMessage.Result != nil {
+	if baseMessage.Result != nil || baseMessage.Error != nil {
 		// this is a response to a request sent by the server (e.g. from a ping
-		// sent due to WithKeepAlive option)
+		// sent due to WithKeepAlive option, or a sampling request)
+		s.handleClientResponse(ctx, message)
 		return nil
 	}
 
@@ -71,250 +88,383 @@ func (s *MCPServer) HandleMessage(
 		)
 	}
 
-	switch baseMessage.Method {
+	// Track the request so that it can be cancelled by the client
+	ctx, done := s.trackRequest(ctx, baseMessage.ID, baseMessage.Method)
+	defer done()
+	// Let handlers report progress if the client asked for it
+	ctx = s.withProgressReporter(ctx, message)
+
+	start := time.Now()
+	ctx, span := s.startRequestSpan(ctx, baseMessage.ID, baseMessage.Method)
+	response := s.handleRequest(ctx, baseMessage.ID, baseMessage.Method, message)
+	endRequestSpan(span, response)
+	s.metrics.observeRequest(baseMessage.Method, response, start)
+	if errors.Is(context.Cause(ctx), ErrRequestCancelled) {
+		// the client is no longer interested in the response
+		return nil
+	}
+	return response
+}
+
+// handleRequest runs a request through the request middlewares and the handler
+// of its method, and returns the response to send to the client
+func (s *MCPServer) handleRequest(
+	ctx context.Context,
+	id any,
+	method mcp.MCPMethod,
+	message json.RawMessage,
+) mcp.JSONRPCMessage {
+	var baseMessage struct {
+		Params json.RawMessage `json:"params,omitempty"`
+	}
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		return createErrorResponse(id, mcp.PARSE_ERROR, "Failed to parse message")
+	}
+
+	result, err := s.requestHandler()(ctx, &RequestInfo{
+		ID:      mcp.NewRequestId(id),
+		Method:  method,
+		Params:  baseMessage.Params,
+		Session: ClientSessionFromContext(ctx),
+		message: message,
+		method:  method,
+		params:  baseMessage.Params,
+	})
+	if err != nil {
+		return requestErrorResponse(id, err)
+	}
+	return createResponse(id, result)
+}
+
+// dispatchRequest dispatches a request to the handler of its method
+func (s *MCPServer) dispatchRequest(ctx context.Context, info *RequestInfo) (any, error) {
+	id := info.ID.Value()
+	method := info.Method
+	message, marshalErr := requestMessage(info)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	var err *requestError
+
+	switch method {
 	case mcp.MethodInitialize:
 		var request mcp.InitializeRequest
 		var result *mcp.InitializeResult
 		if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforeInitialize(ctx, baseMessage.ID, &request)
-			result, err = s.handleInitialize(ctx, baseMessage.ID, request)
+			s.hooks.beforeInitialize(ctx, id, &request)
+			result, err = s.handleInitialize(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
 		}
-		s.hooks.afterInitialize(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterInitialize(ctx, id, &request, result)
+		return *result, nil
 	case mcp.MethodPing:
 		var request mcp.PingRequest
 		var result *mcp.EmptyResult
 		if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforePing(ctx, baseMessage.ID, &request)
-			result, err = s.handlePing(ctx, baseMessage.ID, request)
+			s.hooks.beforePing(ctx, id, &request)
+			result, err = s.handlePing(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
 		}
-		s.hooks.afterPing(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterPing(ctx, id, &request, result)
+		return *result, nil
 	case mcp.MethodSetLogLevel:
 		var request mcp.SetLevelRequest
 		var result *mcp.EmptyResult
 		if s.capabilities.logging == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("logging %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforeSetLevel(ctx, baseMessage.ID, &request)
-			result, err = s.handleSetLevel(ctx, baseMessage.ID, request)
+			s.hooks.beforeSetLevel(ctx, id, &request)
+			result, err = s.handleSetLevel(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
 		}
-		s.hooks.afterSetLevel(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterSetLevel(ctx, id, &request, result)
+		return *result, nil
 	case mcp.MethodResourcesList:
 		var request mcp.ListResourcesRequest
 		var result *mcp.ListResourcesResult
 		if s.capabilities.resources == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("resources %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforeListResources(ctx, baseMessage.ID, &request)
-			result, err = s.handleListResources(ctx, baseMessage.ID, request)
+			s.hooks.beforeListResources(ctx, id, &request)
+			result, err = s.handleListResources(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
 		}
-		s.hooks.afterListResources(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterListResources(ctx, id, &request, result)
+		return *result, nil
 	case mcp.MethodResourcesTemplatesList:
 		var request mcp.ListResourceTemplatesRequest
 		var result *mcp.ListResourceTemplatesResult
 		if s.capabilities.resources == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("resources %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforeListResourceTemplates(ctx, baseMessage.ID, &request)
-			result, err = s.handleListResourceTemplates(ctx, baseMessage.ID, request)
+			s.hooks.beforeListResourceTemplates(ctx, id, &request)
+			result, err = s.handleListResourceTemplates(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
 		}
-		s.hooks.afterListResourceTemplates(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterListResourceTemplates(ctx, id, &request, result)
+		return *result, nil
 	case mcp.MethodResourcesRead:
 		var request mcp.ReadResourceRequest
 		var result *mcp.ReadResourceResult
 		if s.capabilities.resources == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("resources %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
+			}
+		} else {
+			s.hooks.beforeReadResource(ctx, id, &request)
+			result, err = s.handleReadResource(ctx, id, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
+		}
+		s.hooks.afterReadResource(ctx, id, &request, result)
+		return *result, nil
+	case mcp.MethodResourcesSubscribe:
+		var request mcp.SubscribeRequest
+		var result *mcp.EmptyResult
+		if s.capabilities.resources == nil {
+			err = &requestError{
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("resources %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforeReadResource(ctx, baseMessage.ID, &request)
-			result, err = s.handleReadResource(ctx, baseMessage.ID, request)
+			s.hooks.beforeSubscribe(ctx, id, &request)
+			result, err = s.handleSubscribe(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
+		}
+		s.hooks.afterSubscribe(ctx, id, &request, result)
+		return *result, nil
+	case mcp.MethodResourcesUnsubscribe:
+		var request mcp.UnsubscribeRequest
+		var result *mcp.EmptyResult
+		if s.capabilities.resources == nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("resources %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
+			}
+		} else {
+			s.hooks.beforeUnsubscribe(ctx, id, &request)
+			result, err = s.handleUnsubscribe(ctx, id, request)
 		}
-		s.hooks.afterReadResource(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		if err != nil {
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
+		}
+		s.hooks.afterUnsubscribe(ctx, id, &request, result)
+		return *result, nil
 	case mcp.MethodPromptsList:
 		var request mcp.ListPromptsRequest
 		var result *mcp.ListPromptsResult
 		if s.capabilities.prompts == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("prompts %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforeListPrompts(ctx, baseMessage.ID, &request)
-			result, err = s.handleListPrompts(ctx, baseMessage.ID, request)
+			s.hooks.beforeListPrompts(ctx, id, &request)
+			result, err = s.handleListPrompts(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
 		}
-		s.hooks.afterListPrompts(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterListPrompts(ctx, id, &request, result)
+		return *result, nil
 	case mcp.MethodPromptsGet:
 		var request mcp.GetPromptRequest
 		var result *mcp.GetPromptResult
 		if s.capabilities.prompts == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("prompts %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforeGetPrompt(ctx, baseMessage.ID, &request)
-			result, err = s.handleGetPrompt(ctx, baseMessage.ID, request)
+			s.hooks.beforeGetPrompt(ctx, id, &request)
+			result, err = s.handleGetPrompt(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
 		}
-		s.hooks.afterGetPrompt(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterGetPrompt(ctx, id, &request, result)
+		return *result, nil
 	case mcp.MethodToolsList:
 		var request mcp.ListToolsRequest
 		var result *mcp.ListToolsResult
 		if s.capabilities.tools == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("tools %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforeListTools(ctx, baseMessage.ID, &request)
-			result, err = s.handleListTools(ctx, baseMessage.ID, request)
+			s.hooks.beforeListTools(ctx, id, &request)
+			result, err = s.handleListTools(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
 		}
-		s.hooks.afterListTools(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterListTools(ctx, id, &request, result)
+		return *result, nil
 	case mcp.MethodToolsCall:
 		var request mcp.CallToolRequest
 		var result *mcp.CallToolResult
 		if s.capabilities.tools == nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.METHOD_NOT_FOUND,
 				err:  fmt.Errorf("tools %w", ErrUnsupported),
 			}
 		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
 			err = &requestError{
-				id:   baseMessage.ID,
+				id:   id,
 				code: mcp.INVALID_REQUEST,
-				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
 			}
 		} else {
-			s.hooks.beforeCallTool(ctx, baseMessage.ID, &request)
-			result, err = s.handleToolCall(ctx, baseMessage.ID, request)
+			s.hooks.beforeCallTool(ctx, id, &request)
+			result, err = s.handleToolCall(ctx, id, request)
 		}
 		if err != nil {
-			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
 		}
-		s.hooks.afterCallTool(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		s.hooks.afterCallTool(ctx, id, &request, result)
+		return *result, nil
+	case mcp.MethodCompletionComplete:
+		var request mcp.CompleteRequest
+		var result *mcp.CompleteResult
+		if s.capabilities.completions == nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("completions %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   id,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
+			}
+		} else {
+			s.hooks.beforeComplete(ctx, id, &request)
+			result, err = s.handleComplete(ctx, id, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, id, method, &request, err)
+			return nil, err
+		}
+		s.hooks.afterComplete(ctx, id, &request, result)
+		return *result, nil
 	default:
-		return createErrorResponse(
-			baseMessage.ID,
-			mcp.METHOD_NOT_FOUND,
-			fmt.Sprintf("Method %s not found", baseMessage.Method),
-		)
+		return nil, &requestError{
+			id:   id,
+			code: mcp.METHOD_NOT_FOUND,
+			err:  fmt.Errorf("Method %s not found", method),
+		}
 	}
 }
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// rootsRefreshTimeout bounds the roots/list request sent when the client
+// notifies that its roots have changed
+const rootsRefreshTimeout = 30 * time.Second
+
+// ListRoots returns the roots of the client of the session in ctx.
+// The roots are requested from the client with roots/list the first time, and
+// cached in the session afterwards. The cache is refreshed when the client sends
+// notifications/roots/list_changed.
+//
+// It fails with ErrRootsNotSupported if the client did not advertise the roots
+// capability during initialization.
+func (s *MCPServer) ListRoots(ctx context.Context) ([]mcp.Root, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoActiveSession
+	}
+
+	if sessionWithRoots, ok := session.(SessionWithRoots); ok {
+		if roots, ok := sessionWithRoots.GetRoots(); ok {
+			return roots, nil
+		}
+	}
+
+	return s.fetchRoots(ctx, session)
+}
+
+// RootsFromContext returns the roots of the client of the current request.
+// It's meant to be called from within a handler, and is a shortcut for
+// ServerFromContext(ctx).ListRoots(ctx).
+func RootsFromContext(ctx context.Context) ([]mcp.Root, error) {
+	server := ServerFromContext(ctx)
+	if server == nil {
+		return nil, ErrNoActiveSession
+	}
+	return server.ListRoots(ctx)
+}
+
+// fetchRoots requests the roots from the client, and caches them in the session
+func (s *MCPServer) fetchRoots(ctx context.Context, session ClientSession) ([]mcp.Root, error) {
+	capabilities, ok := clientCapabilitiesFromSession(session)
+	if !ok || capabilities.Roots == nil {
+		return nil, ErrRootsNotSupported
+	}
+
+	// roots invalidated while the request is pending are stale
+	cache, hasCache := session.(sessionWithRootsCache)
+	var generation uint64
+	if hasCache {
+		generation = cache.rootsCache().generation()
+	}
+
+	response, err := s.sendClientRequest(ctx, mcp.MethodListRoots, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.ListRootsResult
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal roots: %w", err)
+	}
+	if result.Roots == nil {
+		result.Roots = []mcp.Root{}
+	}
+
+	if hasCache {
+		cache.rootsCache().setIfCurrent(generation, result.Roots)
+	} else if sessionWithRoots, ok := session.(SessionWithRoots); ok {
+		sessionWithRoots.SetRoots(result.Roots)
+	}
+	return result.Roots, nil
+}
+
+// handleRootsListChanged invalidates the cached roots of the session and
+// requests the new ones in the background. If the refresh fails, the roots
+// are requested again the next time they are needed.
+func (s *MCPServer) handleRootsListChanged(ctx context.Context) {
+	session := ClientSessionFromContext(ctx)
+	sessionWithRoots, ok := session.(SessionWithRoots)
+	if !ok {
+		return
+	}
+	sessionWithRoots.SetRoots(nil)
+
+	// The response to roots/list arrives on the same connection as the
+	// notification, so don't block its handling.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rootsRefreshTimeout)
+		defer cancel()
+		_, _ = s.fetchRoots(ctx, session)
+	}()
+}
+
+// sessionWithRootsCache is implemented by the sessions of the built-in
+// transports, whose roots cache drops the roots of outdated refreshes
+type sessionWithRootsCache interface {
+	rootsCache() *rootsCache
+}
+
+// rootsCache caches the roots of a client. Each invalidation starts a new
+// generation, so that refreshes started before it don't store stale roots.
+type rootsCache struct {
+	mu    sync.Mutex
+	roots []mcp.Root
+	valid bool
+	gen   uint64
+}
+
+func (c *rootsCache) get() ([]mcp.Root, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.roots, c.valid
+}
+
+// set caches roots, or invalidates the cache if roots is nil
+func (c *rootsCache) set(roots []mcp.Root) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if roots == nil {
+		c.gen++
+	}
+	c.roots, c.valid = roots, roots != nil
+}
+
+// generation returns the current generation of the cache
+func (c *rootsCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// setIfCurrent caches roots fetched during a generation, unless the cache was
+// invalidated since
+func (c *rootsCache) setIfCurrent(generation uint64, roots []mcp.Root) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.gen {
+		return false
+	}
+	c.roots, c.valid = roots, true
+	return true
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sessionTestClientWithRoots implements SessionWithRoots on top of a session that
+// can send requests to the client
+type sessionTestClientWithRoots struct {
+	*sessionTestClientWithRequests
+	roots rootsCache
+}
+
+func (f *sessionTestClientWithRoots) GetRoots() ([]mcp.Root, bool) {
+	return f.roots.get()
+}
+
+func (f *sessionTestClientWithRoots) SetRoots(roots []mcp.Root) {
+	f.roots.set(roots)
+}
+
+func (f *sessionTestClientWithRoots) rootsCache() *rootsCache {
+	return &f.roots
+}
+
+var _ SessionWithRoots = (*sessionTestClientWithRoots)(nil)
+
+// answerRoots answers every roots/list request sent to the session with the given roots
+// and counts the requests
+func answerRoots(t *testing.T, server *MCPServer, ctx context.Context, session *sessionTestClientWithRoots, roots func() string, count *atomic.Int32) {
+	go func() {
+		for {
+			select {
+			case request := <-session.requests:
+				assert.Equal(t, string(mcp.MethodListRoots), request.Method)
+				count.Add(1)
+				response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": {"roots": %s}}`, mustMarshal(t, request.ID), roots())
+				server.HandleMessage(ctx, []byte(response))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func TestMCPServer_ListRoots(t *testing.T) {
+	rootsCapabilities := mcp.ClientCapabilities{
+		Roots: &struct {
+			ListChanged bool `json:"listChanged,omitempty"`
+		}{ListChanged: true},
+	}
+
+	t.Run("no session in context", func(t *testing.T) {
+		_, err := RootsFromContext(context.Background())
+		assert.ErrorIs(t, err, ErrNoActiveSession)
+	})
+
+	t.Run("client does not support roots", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := &sessionTestClientWithRoots{sessionTestClientWithRequests: newSessionTestClientWithRequests(mcp.ClientCapabilities{})}
+		ctx := server.WithContext(context.Background(), session)
+
+		_, err := server.ListRoots(ctx)
+		assert.ErrorIs(t, err, ErrRootsNotSupported)
+	})
+
+	t.Run("roots are cached and refreshed on list_changed", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := &sessionTestClientWithRoots{sessionTestClientWithRequests: newSessionTestClientWithRequests(rootsCapabilities)}
+		ctx, cancel := context.WithCancel(server.WithContext(context.Background(), session))
+		defer cancel()
+
+		var currentRoots atomic.Value
+		currentRoots.Store(`[{"uri": "file:///project", "name": "project"}]`)
+		var count atomic.Int32
+		answerRoots(t, server, ctx, session, func() string { return currentRoots.Load().(string) }, &count)
+
+		roots, err := server.ListRoots(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []mcp.Root{{URI: "file:///project", Name: "project"}}, roots)
+
+		// served from the cache
+		roots, err = server.ListRoots(ctx)
+		require.NoError(t, err)
+		assert.Len(t, roots, 1)
+		assert.Equal(t, int32(1), count.Load())
+
+		// the client changes its roots
+		currentRoots.Store(`[{"uri": "file:///project"}, {"uri": "file:///other"}]`)
+		response := server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "method": "notifications/roots/list_changed"}`))
+		assert.Nil(t, response)
+
+		require.Eventually(t, func() bool {
+			cached, ok := session.GetRoots()
+			return ok && len(cached) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		// handlers get the server in their context
+		roots, err = RootsFromContext(context.WithValue(ctx, serverKey{}, server))
+		require.NoError(t, err)
+		assert.Equal(t, []mcp.Root{{URI: "file:///project"}, {URI: "file:///other"}}, roots)
+		assert.Equal(t, int32(2), count.Load())
+	})
+
+	t.Run("refreshes started before list_changed don't store stale roots", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := &sessionTestClientWithRoots{sessionTestClientWithRequests: newSessionTestClientWithRequests(rootsCapabilities)}
+		ctx := server.WithContext(context.Background(), session)
+		answer := func(request mcp.JSONRPCRequest, roots string) {
+			response := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": {"roots": %s}}`, mustMarshal(t, request.ID), roots)
+			server.HandleMessage(ctx, []byte(response))
+		}
+
+		stale := make(chan []mcp.Root, 1)
+		go func() {
+			roots, err := server.ListRoots(ctx)
+			assert.NoError(t, err)
+			stale <- roots
+		}()
+		staleRequest := <-session.requests
+
+		// the roots change while the first request is pending
+		server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "method": "notifications/roots/list_changed"}`))
+		refreshRequest := <-session.requests
+		answer(refreshRequest, `[{"uri": "file:///new"}]`)
+		require.Eventually(t, func() bool {
+			_, ok := session.GetRoots()
+			return ok
+		}, time.Second, 10*time.Millisecond)
+
+		// the caller of the outdated request gets its answer, but it isn't cached
+		answer(staleRequest, `[{"uri": "file:///old"}]`)
+		assert.Equal(t, []mcp.Root{{URI: "file:///old"}}, <-stale)
+		cached, ok := session.GetRoots()
+		assert.True(t, ok)
+		assert.Equal(t, []mcp.Root{{URI: "file:///new"}}, cached)
+	})
+
+	t.Run("list_changed still reaches notification handlers", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := &sessionTestClientWithRoots{sessionTestClientWithRequests: newSessionTestClientWithRequests(mcp.ClientCapabilities{})}
+		ctx := server.WithContext(context.Background(), session)
+
+		called := make(chan struct{}, 1)
+		server.AddNotificationHandler(mcp.MethodNotificationRootsListChanged, func(ctx context.Context, notification mcp.JSONRPCNotification) {
+			called <- struct{}{}
+		})
+
+		server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "method": "notifications/roots/list_changed"}`))
+		select {
+		case <-called:
+		case <-time.After(time.Second):
+			t.Fatal("notification handler not called")
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RequestSampling sends a sampling/createMessage request to the client of the
+// session in ctx and waits for the sampled message. It is meant to be called
+// from within a handler, for example:
+//
+//	result, err := server.ServerFromContext(ctx).RequestSampling(ctx, request)
+//
+// It fails with ErrSamplingNotSupported if the client did not advertise the
+// sampling capability during initialization.
+func (s *MCPServer) RequestSampling(
+	ctx context.Context,
+	request mcp.CreateMessageRequest,
+) (*mcp.CreateMessageResult, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoActiveSession
+	}
+
+	capabilities, ok := clientCapabilitiesFromSession(session)
+	if !ok || capabilities.Sampling == nil {
+		return nil, ErrSamplingNotSupported
+	}
+
+	response, err := s.sendClientRequest(ctx, mcp.MethodSamplingCreateMessage, request.CreateMessageParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.ParseCreateMessageResult(&response)
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sessionTestClientWithRequests implements SessionWithRequests for testing.
+// Requests sent to the client are published on the requests channel.
+type sessionTestClientWithRequests struct {
+	sessionID           string
+	notificationChannel chan mcp.JSONRPCNotification
+	requests            chan mcp.JSONRPCRequest
+	capabilities        mcp.ClientCapabilities
+	requestID           atomic.Int64
+	pendingRequests     pendingRequests
+}
+
+func newSessionTestClientWithRequests(capabilities mcp.ClientCapabilities) *sessionTestClientWithRequests {
+	return &sessionTestClientWithRequests{
+		sessionID:           "session-with-requests",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		requests:            make(chan mcp.JSONRPCRequest, 10),
+		capabilities:        capabilities,
+	}
+}
+
+func (f *sessionTestClientWithRequests) SessionID() string {
+	return f.sessionID
+}
+
+func (f *sessionTestClientWithRequests) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return f.notificationChannel
+}
+
+func (f *sessionTestClientWithRequests) Initialize() {}
+
+func (f *sessionTestClientWithRequests) Initialized() bool {
+	return true
+}
+
+func (f *sessionTestClientWithRequests) GetClientCapabilities() mcp.ClientCapabilities {
+	return f.capabilities
+}
+
+func (f *sessionTestClientWithRequests) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	f.capabilities = capabilities
+}
+
+func (f *sessionTestClientWithRequests) SendRequest(
+	ctx context.Context,
+	method mcp.MCPMethod,
+	params any,
+) (json.RawMessage, error) {
+	request := newClientRequest(f.requestID.Add(1), method, params)
+	return f.pendingRequests.send(ctx, request, func(request mcp.JSONRPCRequest) error {
+		f.requests <- request
+		return nil
+	})
+}
+
+func (f *sessionTestClientWithRequests) HandleResponse(id mcp.RequestId, result json.RawMessage, err error) bool {
+	return f.pendingRequests.deliver(id, result, err)
+}
+
+var (
+	_ SessionWithClientCapabilities = (*sessionTestClientWithRequests)(nil)
+	_ SessionWithRequests           = (*sessionTestClientWithRequests)(nil)
+)
+
+func newTestSamplingRequest() mcp.CreateMessageRequest {
+	return mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{
+				{Role: mcp.RoleUser, Content: mcp.NewTextContent("hello")},
+			},
+			MaxTokens: 10,
+		},
+	}
+}
+
+func TestMCPServer_RequestSampling(t *testing.T) {
+	t.Run("no session in context", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		_, err := server.RequestSampling(context.Background(), newTestSamplingRequest())
+		assert.ErrorIs(t, err, ErrNoActiveSession)
+	})
+
+	t.Run("client does not support sampling", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(mcp.ClientCapabilities{})
+		ctx := server.WithContext(context.Background(), session)
+
+		_, err := server.RequestSampling(ctx, newTestSamplingRequest())
+		assert.ErrorIs(t, err, ErrSamplingNotSupported)
+		assert.Empty(t, session.requests, "no request should be sent to the client")
+	})
+
+	t.Run("session does not keep client capabilities", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := &sessionTestClient{sessionID: "basic", notificationChannel: make(chan mcp.JSONRPCNotification, 1)}
+		ctx := server.WithContext(context.Background(), session)
+
+		_, err := server.RequestSampling(ctx, newTestSamplingRequest())
+		assert.ErrorIs(t, err, ErrSamplingNotSupported)
+	})
+
+	t.Run("response is routed through HandleMessage", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(mcp.ClientCapabilities{Sampling: &struct{}{}})
+		ctx := server.WithContext(context.Background(), session)
+
+		go func() {
+			request := <-session.requests
+			assert.Equal(t, string(mcp.MethodSamplingCreateMessage), request.Method)
+			response := fmt.Sprintf(`{
+				"jsonrpc": "2.0",
+				"id": %s,
+				"result": {
+					"role": "assistant",
+					"content": {"type": "text", "text": "hi there"},
+					"model": "test-model",
+					"stopReason": "endTurn"
+				}
+			}`, mustMarshal(t, request.ID))
+			assert.Nil(t, server.HandleMessage(ctx, []byte(response)))
+		}()
+
+		result, err := server.RequestSampling(ctx, newTestSamplingRequest())
+		require.NoError(t, err)
+		assert.Equal(t, "test-model", result.Model)
+		assert.Equal(t, "endTurn", result.StopReason)
+		assert.Equal(t, mcp.RoleAssistant, result.Role)
+		text, ok := mcp.AsTextContent(result.Content)
+		require.True(t, ok)
+		assert.Equal(t, "hi there", text.Text)
+	})
+
+	t.Run("client error is returned", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(mcp.ClientCapabilities{Sampling: &struct{}{}})
+		ctx := server.WithContext(context.Background(), session)
+
+		go func() {
+			request := <-session.requests
+			response := fmt.Sprintf(
+				`{"jsonrpc": "2.0", "id": %s, "error": {"code": -1, "message": "user rejected sampling"}}`,
+				mustMarshal(t, request.ID),
+			)
+			server.HandleMessage(ctx, []byte(response))
+		}()
+
+		_, err := server.RequestSampling(ctx, newTestSamplingRequest())
+		var clientErr *ClientRequestError
+		require.ErrorAs(t, err, &clientErr)
+		assert.Equal(t, -1, clientErr.Code)
+		assert.Equal(t, "user rejected sampling", clientErr.Message)
+	})
+
+	t.Run("context is cancelled while waiting", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		session := newSessionTestClientWithRequests(mcp.ClientCapabilities{Sampling: &struct{}{}})
+		ctx, cancel := context.WithTimeout(server.WithContext(context.Background(), session), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := server.RequestSampling(ctx, newTestSamplingRequest())
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
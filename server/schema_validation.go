@@ -0,0 +1,349 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolSchemas are the compiled input and output schemas of a tool, nil if the
+// tool has none. They are compiled when the tool is registered, so that calls
+// don't decode the schemas and compile their patterns again.
+type toolSchemas struct {
+	input, output       *compiledSchema
+	inputErr, outputErr error
+}
+
+// compileToolSchemas compiles the input and output schemas of a tool
+func compileToolSchemas(tool mcp.Tool) *toolSchemas {
+	schemas := &toolSchemas{}
+	switch {
+	case tool.RawInputSchema != nil:
+		schemas.input, schemas.inputErr = compileSchema(tool.RawInputSchema)
+	case tool.InputSchema.Type != "":
+		schemas.input, schemas.inputErr = compileSchema(tool.InputSchema)
+	}
+	switch {
+	case tool.RawOutputSchema != nil:
+		schemas.output, schemas.outputErr = compileSchema(tool.RawOutputSchema)
+	case tool.OutputSchema.Type != "":
+		schemas.output, schemas.outputErr = compileSchema(tool.OutputSchema)
+	}
+	return schemas
+}
+
+// withCompiledSchemas returns the tool with its schemas compiled
+func withCompiledSchemas(tool ServerTool) ServerTool {
+	tool.schemas = compileToolSchemas(tool.Tool)
+	return tool
+}
+
+// compiledSchemas returns the compiled schemas of a tool, compiling them if the
+// tool wasn't registered through the server, e.g. set by a session itself
+func (t ServerTool) compiledSchemas() *toolSchemas {
+	if t.schemas != nil {
+		return t.schemas
+	}
+	return compileToolSchemas(t.Tool)
+}
+
+// validateToolInput checks that the arguments of a tool call conform to the
+// input schema of the tool
+func validateToolInput(tool ServerTool, request mcp.CallToolRequest) error {
+	schemas := tool.compiledSchemas()
+	if schemas.inputErr != nil {
+		return schemas.inputErr
+	}
+	if schemas.input == nil {
+		return nil
+	}
+	arguments := request.Params.Arguments
+	if arguments == nil {
+		// omitted arguments are validated like an empty object
+		arguments = map[string]any{}
+	}
+	return validateAgainstSchema(schemas.input, arguments)
+}
+
+// validateToolOutput checks that the structured content of a tool result
+// conforms to the output schema of the tool, if it has one
+func validateToolOutput(tool ServerTool, result *mcp.CallToolResult) error {
+	schemas := tool.compiledSchemas()
+	if schemas.output == nil && schemas.outputErr == nil {
+		return nil
+	}
+	if result.StructuredContent == nil {
+		return fmt.Errorf("structured content is missing")
+	}
+	if schemas.outputErr != nil {
+		return schemas.outputErr
+	}
+	return validateAgainstSchema(schemas.output, result.StructuredContent)
+}
+
+// compiledSchema is a JSON Schema decoded into its generic form, with the
+// regular expressions of its pattern keywords
+type compiledSchema struct {
+	schema map[string]any
+	// pattern -> compiled regular expression, or the error compiling it
+	patterns map[string]*regexp.Regexp
+	errs     map[string]error
+}
+
+// compileSchema decodes a JSON Schema into its generic form, and compiles its
+// patterns
+func compileSchema(schema any) (*compiledSchema, error) {
+	var data []byte
+	switch schema := schema.(type) {
+	case json.RawMessage:
+		data = schema
+	default:
+		var err error
+		if data, err = json.Marshal(schema); err != nil {
+			return nil, err
+		}
+	}
+	compiled := &compiledSchema{patterns: map[string]*regexp.Regexp{}, errs: map[string]error{}}
+	if err := json.Unmarshal(data, &compiled.schema); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	compiled.compilePatterns(compiled.schema)
+	return compiled, nil
+}
+
+// compilePatterns compiles the patterns of a schema and its subschemas
+func (c *compiledSchema) compilePatterns(value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		if pattern, ok := value["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err != nil {
+				c.errs[pattern] = err
+			} else {
+				c.patterns[pattern] = re
+			}
+		}
+		for _, v := range value {
+			c.compilePatterns(v)
+		}
+	case []any:
+		for _, v := range value {
+			c.compilePatterns(v)
+		}
+	}
+}
+
+// validateAgainstSchema checks that value conforms to the schema, and returns a
+// *SchemaValidationError listing every violation otherwise. The value is
+// normalized through JSON first, so Go structs are validated like the JSON the
+// client receives.
+func validateAgainstSchema(schema *compiledSchema, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return err
+	}
+
+	var violations []SchemaViolation
+	schema.validateValue(schema.schema, normalized, "$", &violations)
+	if len(violations) > 0 {
+		return &SchemaValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validateValue appends the violations of the value at path to violations.
+// It supports the keywords commonly used in tool schemas: type, enum,
+// properties, required, additionalProperties, items, minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, minLength, maxLength, pattern, minItems
+// and maxItems.
+func (c *compiledSchema) validateValue(schema map[string]any, value any, path string, violations *[]SchemaViolation) {
+	violation := func(format string, args ...any) {
+		*violations = append(*violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		if !slices.ContainsFunc(types, func(t string) bool { return matchesSchemaType(t, value) }) {
+			violation("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(allowed any) bool { return reflect.DeepEqual(allowed, value) }) {
+			violation("value %v is not one of %v", value, enum)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		c.validateObject(schema, value, path, violations)
+	case []any:
+		if minItems, ok := schemaNumber(schema, "minItems"); ok && float64(len(value)) < minItems {
+			violation("expected at least %v items, got %d", minItems, len(value))
+		}
+		if maxItems, ok := schemaNumber(schema, "maxItems"); ok && float64(len(value)) > maxItems {
+			violation("expected at most %v items, got %d", maxItems, len(value))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				c.validateValue(items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if minLength, ok := schemaNumber(schema, "minLength"); ok && float64(length) < minLength {
+			violation("expected at least %v characters, got %d", minLength, length)
+		}
+		if maxLength, ok := schemaNumber(schema, "maxLength"); ok && float64(length) > maxLength {
+			violation("expected at most %v characters, got %d", maxLength, length)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if err := c.errs[pattern]; err != nil {
+				violation("invalid pattern %q in schema: %v", pattern, err)
+			} else if re := c.patterns[pattern]; re != nil && !re.MatchString(value) {
+				violation("value %q does not match pattern %q", value, pattern)
+			}
+		}
+	case float64:
+		if minimum, ok := schemaNumber(schema, "minimum"); ok && value < minimum {
+			violation("value %v is less than the minimum %v", value, minimum)
+		}
+		if maximum, ok := schemaNumber(schema, "maximum"); ok && value > maximum {
+			violation("value %v is greater than the maximum %v", value, maximum)
+		}
+		if minimum, ok := schemaNumber(schema, "exclusiveMinimum"); ok && value <= minimum {
+			violation("value %v must be greater than %v", value, minimum)
+		}
+		if maximum, ok := schemaNumber(schema, "exclusiveMaximum"); ok && value >= maximum {
+			violation("value %v must be less than %v", value, maximum)
+		}
+	}
+}
+
+// schemaNumber returns the value of a numeric keyword of the schema
+func schemaNumber(schema map[string]any, keyword string) (float64, bool) {
+	n, ok := schema[keyword].(float64)
+	return n, ok
+}
+
+func (c *compiledSchema) validateObject(schema map[string]any, object map[string]any, path string, violations *[]SchemaViolation) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			name, _ := name.(string)
+			if _, ok := object[name]; !ok {
+				*violations = append(*violations, SchemaViolation{
+					Path:    propertyPath(path, name),
+					Message: "required property is missing",
+				})
+			}
+		}
+	}
+
+	// iterate in a stable order so that violations are reported deterministically
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if propertySchema, ok := properties[name].(map[string]any); ok {
+			c.validateValue(propertySchema, object[name], propertyPath(path, name), violations)
+			continue
+		}
+		if _, ok := properties[name]; ok {
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*violations = append(*violations, SchemaViolation{
+					Path:    propertyPath(path, name),
+					Message: "additional property is not allowed",
+				})
+			}
+		case map[string]any:
+			c.validateValue(additional, object[name], propertyPath(path, name), violations)
+		}
+	}
+}
+
+// schemaTypes returns the types allowed by the type keyword, which is either
+// a single type or an array of types
+func schemaTypes(typ any) []string {
+	switch typ := typ.(type) {
+	case string:
+		return []string{typ}
+	case []any:
+		types := make([]string, 0, len(typ))
+		for _, t := range typ {
+			if t, ok := t.(string); ok {
+				types = append(types, t)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func matchesSchemaType(typ string, value any) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	// unknown types are not validated
+	return true
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func propertyPath(path, name string) string {
+	return path + "." + name
+}
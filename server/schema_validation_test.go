@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidateAgainstSchema(t *testing.T) {
+	schema, err := compileSchema(json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"count": {"type": "integer"},
+			"status": {"type": "string", "enum": ["ok", "failed"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"owner": {
+				"type": "object",
+				"properties": {"id": {"type": ["string", "null"]}},
+				"required": ["id"],
+				"additionalProperties": false
+			}
+		},
+		"required": ["name", "count"]
+	}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		value      string
+		violations []SchemaViolation
+	}{
+		{
+			name:  "valid value",
+			value: `{"name": "a", "count": 2, "status": "ok", "tags": ["x"], "owner": {"id": null}, "extra": true}`,
+		},
+		{
+			name:  "wrong root type",
+			value: `[]`,
+			violations: []SchemaViolation{
+				{Path: "$", Message: "expected object, got array"},
+			},
+		},
+		{
+			name:  "missing required and wrong types",
+			value: `{"count": 1.5, "status": "unknown"}`,
+			violations: []SchemaViolation{
+				{Path: "$.name", Message: "required property is missing"},
+				{Path: "$.count", Message: "expected integer, got number"},
+				{Path: "$.status", Message: "value unknown is not one of [ok failed]"},
+			},
+		},
+		{
+			name:  "nested values",
+			value: `{"name": "a", "count": 1, "tags": ["x", 2], "owner": {"name": "b"}}`,
+			violations: []SchemaViolation{
+				{Path: "$.owner.id", Message: "required property is missing"},
+				{Path: "$.owner.name", Message: "additional property is not allowed"},
+				{Path: "$.tags[1]", Message: "expected string, got number"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			require.NoError(t, json.Unmarshal([]byte(tt.value), &value))
+			err := validateAgainstSchema(schema, value)
+			if tt.violations == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *SchemaValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.violations, validationErr.Violations)
+		})
+	}
+}
+
+func TestValidateAgainstSchema_Constraints(t *testing.T) {
+	schema, err := compileSchema(json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"age": {"type": "integer", "minimum": 0, "maximum": 150},
+			"ratio": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1},
+			"code": {"type": "string", "minLength": 2, "maxLength": 3, "pattern": "^\\p{Lu}+$"},
+			"tags": {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string"}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		value      string
+		violations []SchemaViolation
+	}{
+		{
+			name:  "within bounds",
+			value: `{"age": 0, "ratio": 0.5, "code": "ÉTÉ", "tags": ["a", "b"]}`,
+		},
+		{
+			name:  "below bounds",
+			value: `{"age": -1, "ratio": 0, "code": "A", "tags": []}`,
+			violations: []SchemaViolation{
+				{Path: "$.age", Message: "value -1 is less than the minimum 0"},
+				{Path: "$.code", Message: "expected at least 2 characters, got 1"},
+				{Path: "$.ratio", Message: "value 0 must be greater than 0"},
+				{Path: "$.tags", Message: "expected at least 1 items, got 0"},
+			},
+		},
+		{
+			name:  "above bounds",
+			value: `{"age": 151, "ratio": 1, "code": "ab12", "tags": ["a", "b", "c"]}`,
+			violations: []SchemaViolation{
+				{Path: "$.age", Message: "value 151 is greater than the maximum 150"},
+				{Path: "$.code", Message: "expected at most 3 characters, got 4"},
+				{Path: "$.code", Message: `value "ab12" does not match pattern "^\\p{Lu}+$"`},
+				{Path: "$.ratio", Message: "value 1 must be less than 1"},
+				{Path: "$.tags", Message: "expected at most 2 items, got 3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			require.NoError(t, json.Unmarshal([]byte(tt.value), &value))
+			err := validateAgainstSchema(schema, value)
+			if tt.violations == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *SchemaValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.violations, validationErr.Violations)
+		})
+	}
+}
+
+func TestMCPServer_InputSchemaValidation(t *testing.T) {
+	newServer := func(tool mcp.Tool, opts ...ServerOption) (*MCPServer, *int) {
+		calls := 0
+		server := NewMCPServer("test-server", "1.0.0", opts...)
+		server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls++
+			return mcp.NewToolResultText("ok"), nil
+		})
+		return server, &calls
+	}
+	callTool := func(server *MCPServer, arguments string) mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "search", "arguments": `+arguments+`}}`,
+		))
+	}
+	searchTool := mcp.NewTool("search",
+		mcp.WithString("query", mcp.Required(), mcp.MinLength(1)),
+		mcp.WithNumber("limit", mcp.Min(1), mcp.Max(100)),
+		mcp.WithString("order", mcp.Enum("asc", "desc")),
+	)
+
+	t.Run("valid arguments", func(t *testing.T) {
+		server, calls := newServer(searchTool, WithInputSchemaValidation())
+		_, ok := callTool(server, `{"query": "mcp", "limit": 10, "order": "asc"}`).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		server, calls := newServer(searchTool, WithInputSchemaValidation())
+		response, ok := callTool(server, `{"limit": 1000, "order": "random"}`).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INVALID_PARAMS, response.Error.Code)
+		assert.Contains(t, response.Error.Message, "invalid arguments for tool 'search'")
+		assert.Equal(t, map[string]any{"violations": []SchemaViolation{
+			{Path: "$.query", Message: "required property is missing"},
+			{Path: "$.limit", Message: "value 1000 is greater than the maximum 100"},
+			{Path: "$.order", Message: "value random is not one of [asc desc]"},
+		}}, response.Error.Data)
+		assert.Zero(t, *calls, "the handler must not be called")
+	})
+
+	t.Run("missing arguments", func(t *testing.T) {
+		server, _ := newServer(searchTool, WithInputSchemaValidation())
+		response, ok := server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "search"}}`,
+		)).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Contains(t, response.Error.Message, "$.query: required property is missing")
+	})
+
+	t.Run("raw input schema", func(t *testing.T) {
+		tool := mcp.NewToolWithRawSchema("search", "", json.RawMessage(`{
+			"type": "object",
+			"properties": {"ids": {"type": "array", "items": {"type": "integer"}}},
+			"additionalProperties": false
+		}`))
+		server, _ := newServer(tool, WithInputSchemaValidation())
+		response, ok := callTool(server, `{"ids": [1, "2"], "extra": true}`).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Contains(t, response.Error.Message, "$.extra: additional property is not allowed")
+		assert.Contains(t, response.Error.Message, "$.ids[1]: expected integer, got string")
+	})
+
+	t.Run("schemas are compiled when the tool is registered", func(t *testing.T) {
+		patternTool := func(pattern string) mcp.Tool {
+			return mcp.NewTool("search", mcp.WithString("query", mcp.Pattern(pattern)))
+		}
+		server, calls := newServer(patternTool("^[a-z]+$"), WithInputSchemaValidation())
+		schemas := server.tools["search"].schemas
+		require.NotNil(t, schemas)
+		require.Contains(t, schemas.input.patterns, "^[a-z]+$")
+
+		for range 2 {
+			_, ok := callTool(server, `{"query": "MCP"}`).(mcp.JSONRPCError)
+			assert.True(t, ok)
+		}
+		assert.Same(t, schemas, server.tools["search"].schemas, "the schemas are compiled once")
+
+		// registering the tool again compiles its new schema
+		server.AddTool(patternTool("^[A-Z]+$"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			*calls++
+			return mcp.NewToolResultText("ok"), nil
+		})
+		_, ok := callTool(server, `{"query": "MCP"}`).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+		assert.Equal(t, 1, *calls)
+
+		server.SetTools(ServerTool{Tool: patternTool("^[0-9]+$"), Handler: server.tools["search"].Handler})
+		_, ok = callTool(server, `{"query": "MCP"}`).(mcp.JSONRPCError)
+		assert.True(t, ok)
+	})
+
+	t.Run("validation is disabled by default", func(t *testing.T) {
+		server, calls := newServer(searchTool)
+		_, ok := callTool(server, `{"limit": 1000}`).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+		assert.Equal(t, 1, *calls)
+	})
+}
+
+func TestMCPServer_OutputSchemaValidation(t *testing.T) {
+	type weather struct {
+		Temperature float64 `json:"temperature"`
+		Conditions  string  `json:"conditions"`
+	}
+
+	newServer := func(result *mcp.CallToolResult, opts ...ServerOption) *MCPServer {
+		server := NewMCPServer("test-server", "1.0.0", opts...)
+		server.AddTool(
+			mcp.NewTool("weather", mcp.WithOutputSchema(map[string]any{
+				"temperature": map[string]any{"type": "number"},
+				"conditions":  map[string]any{"type": "string"},
+			}, "temperature", "conditions")),
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return result, nil
+			},
+		)
+		return server
+	}
+	callTool := func(server *MCPServer) mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "weather"}}`,
+		))
+	}
+
+	t.Run("conforming output", func(t *testing.T) {
+		server := newServer(mcp.NewToolResultStructuredOnly(weather{Temperature: 21, Conditions: "sunny"}), WithOutputSchemaValidation())
+		response, ok := callTool(server).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		result := response.Result.(mcp.CallToolResult)
+		assert.Equal(t, weather{Temperature: 21, Conditions: "sunny"}, result.StructuredContent)
+	})
+
+	t.Run("non-conforming output", func(t *testing.T) {
+		server := newServer(mcp.NewToolResultStructuredOnly(map[string]any{"temperature": "warm"}), WithOutputSchemaValidation())
+		response, ok := callTool(server).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INTERNAL_ERROR, response.Error.Code)
+		assert.Contains(t, response.Error.Message, "$.temperature: expected number, got string")
+		assert.Contains(t, response.Error.Message, "$.conditions: required property is missing")
+	})
+
+	t.Run("missing structured content", func(t *testing.T) {
+		server := newServer(mcp.NewToolResultText("21°C"), WithOutputSchemaValidation())
+		response, ok := callTool(server).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Contains(t, response.Error.Message, "structured content is missing")
+	})
+
+	t.Run("tool errors are not validated", func(t *testing.T) {
+		server := newServer(mcp.NewToolResultError("service unavailable"), WithOutputSchemaValidation())
+		_, ok := callTool(server).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+
+	t.Run("validation is disabled by default", func(t *testing.T) {
+		server := newServer(mcp.NewToolResultStructuredOnly(map[string]any{"temperature": "warm"}))
+		_, ok := callTool(server).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+}
@@ -5,24 +5,31 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/tracing"
 )
 
 // resourceEntry holds both a resource and its handler
 type resourceEntry struct {
-	resource mcp.Resource
-	handler  ResourceHandlerFunc
+	resource       mcp.Resource
+	handler        ResourceHandlerFunc
+	requiredScopes []string
 }
 
 // resourceTemplateEntry holds both a template and its handler
 type resourceTemplateEntry struct {
-	template mcp.ResourceTemplate
-	handler  ResourceTemplateHandlerFunc
+	template       mcp.ResourceTemplate
+	handler        ResourceTemplateHandlerFunc
+	priority       int
+	requiredScopes []string
 }
 
 // ServerOption is a function that configures an MCPServer.
@@ -50,18 +57,44 @@ type ToolFilterFunc func(ctx context.Context, tools []mcp.Tool) []mcp.Tool
 type ServerTool struct {
 	Tool    mcp.Tool
 	Handler ToolHandlerFunc
+	// RequiredScopes are the scopes the principal of a request must have to
+	// list and call the tool (see WithPrincipalExtractor)
+	RequiredScopes []string
+
+	// schemas are compiled when the tool is registered
+	schemas *toolSchemas
 }
 
 // ServerPrompt combines a Prompt with its handler function.
 type ServerPrompt struct {
 	Prompt  mcp.Prompt
 	Handler PromptHandlerFunc
+	// RequiredScopes are the scopes the principal of a request must have to
+	// list and get the prompt (see WithPrincipalExtractor)
+	RequiredScopes []string
 }
 
 // ServerResource combines a Resource with its handler function.
 type ServerResource struct {
 	Resource mcp.Resource
 	Handler  ResourceHandlerFunc
+	// RequiredScopes are the scopes the principal of a request must have to
+	// list and read the resource (see WithPrincipalExtractor)
+	RequiredScopes []string
+}
+
+// ServerResourceTemplate combines a ResourceTemplate with its handler function.
+type ServerResourceTemplate struct {
+	Template mcp.ResourceTemplate
+	Handler  ResourceTemplateHandlerFunc
+	// Priority of the template when several templates match a URI. Templates with
+	// a higher priority win; templates with the same priority are ranked by
+	// specificity, literal text winning over variables.
+	Priority int
+	// RequiredScopes are the scopes the principal of a request must have to
+	// list the template and read the resources matching it (see
+	// WithPrincipalExtractor)
+	RequiredScopes []string
 }
 
 // serverKey is the context key for storing the server instance
@@ -112,7 +145,7 @@ func (e *requestError) Error() string {
 }
 
 func (e *requestError) ToJSONRPCError() mcp.JSONRPCError {
-	return mcp.JSONRPCError{
+	jsonrpcError := mcp.JSONRPCError{
 		JSONRPC: mcp.JSONRPC_VERSION,
 		ID:      mcp.NewRequestId(e.id),
 		Error: struct {
@@ -124,6 +157,20 @@ func (e *requestError) ToJSONRPCError() mcp.JSONRPCError {
 			Message: e.err.Error(),
 		},
 	}
+	// let clients tell the offending values apart without parsing the message
+	var validationErr *SchemaValidationError
+	if errors.As(e.err, &validationErr) {
+		jsonrpcError.Error.Data = map[string]any{"violations": validationErr.Violations}
+	}
+	var rateLimitErr *RateLimitError
+	if errors.As(e.err, &rateLimitErr) {
+		jsonrpcError.Error.Data = rateLimitErr.data()
+	}
+	var forbiddenErr *ForbiddenError
+	if errors.As(e.err, &forbiddenErr) {
+		jsonrpcError.Error.Data = forbiddenErr.data()
+	}
+	return jsonrpcError
 }
 
 func (e *requestError) Unwrap() error {
@@ -144,21 +191,37 @@ type MCPServer struct {
 	notificationHandlersMu sync.RWMutex
 	capabilitiesMu         sync.RWMutex
 	toolFiltersMu          sync.RWMutex
+	completionsMu          sync.RWMutex
 
 	name                   string
 	version                string
 	instructions           string
 	resources              map[string]resourceEntry
 	resourceTemplates      map[string]resourceTemplateEntry
-	prompts                map[string]mcp.Prompt
-	promptHandlers         map[string]PromptHandlerFunc
+	templateIndex          atomic.Pointer[templateIndex] // built on demand, reset when templates change
+	prompts                map[string]ServerPrompt
 	tools                  map[string]ServerTool
 	toolHandlerMiddlewares []ToolHandlerMiddleware
+	requestMiddlewares     []RequestMiddleware
 	toolFilters            []ToolFilterFunc
 	notificationHandlers   map[string]NotificationHandlerFunc
+	promptCompletions      map[string]CompletionProviderFunc
+	resourceCompletions    map[string]resourceCompletion
+	completionIndex        atomic.Pointer[templateIndex] // built on demand, reset when resource completions change
 	capabilities           serverCapabilities
 	paginationLimit        *int
+	progressInterval       time.Duration
+	validateToolInput      bool
+	validateToolOutput     bool
 	sessions               sync.Map
+	sessionItemsLocks      sync.Map // sessionID -> *sync.Mutex serializing updates of the session's items
+	subscriptions          resourceSubscriptions
+	inFlight               inFlightRequests
+	tracer                 tracing.Tracer
+	metrics                *serverMetrics
+	principalExtractor     PrincipalExtractor
+	rateLimits             []RateLimit
+	rateLimitStore         RateLimitStore
 	hooks                  *Hooks
 }
 
@@ -171,10 +234,11 @@ func WithPaginationLimit(limit int) ServerOption {
 
 // serverCapabilities defines the supported features of the MCP server
 type serverCapabilities struct {
-	tools     *toolCapabilities
-	resources *resourceCapabilities
-	prompts   *promptCapabilities
-	logging   *bool
+	tools       *toolCapabilities
+	resources   *resourceCapabilities
+	prompts     *promptCapabilities
+	logging     *bool
+	completions *bool
 }
 
 // resourceCapabilities defines the supported resource-related features
@@ -281,6 +345,42 @@ func WithLogging() ServerOption {
 	}
 }
 
+// WithCompletions enables argument completion capabilities for the server.
+// It's enabled implicitly when a completion provider is added.
+func WithCompletions() ServerOption {
+	return func(s *MCPServer) {
+		s.capabilities.completions = mcp.ToBoolPtr(true)
+	}
+}
+
+// WithProgressInterval sets the minimum interval between two progress
+// notifications sent for the same request. Reports made in between are dropped,
+// except for the one completing the progress. Zero disables the rate limit.
+func WithProgressInterval(interval time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.progressInterval = interval
+	}
+}
+
+// WithInputSchemaValidation enables the validation of the arguments of tool calls
+// against the input schema of the tool, before the call reaches middlewares and
+// the handler. Calls with invalid arguments are answered with an invalid params
+// error listing every offending argument.
+func WithInputSchemaValidation() ServerOption {
+	return func(s *MCPServer) {
+		s.validateToolInput = true
+	}
+}
+
+// WithOutputSchemaValidation enables the validation of the structured content
+// returned by tools with an output schema. A tool result that doesn't conform to
+// the schema of its tool is answered with an internal error instead.
+func WithOutputSchemaValidation() ServerOption {
+	return func(s *MCPServer) {
+		s.validateToolOutput = true
+	}
+}
+
 // WithInstructions sets the server instructions for the client returned in the initialize response
 func WithInstructions(instructions string) ServerOption {
 	return func(s *MCPServer) {
@@ -296,17 +396,21 @@ func NewMCPServer(
 	s := &MCPServer{
 		resources:            make(map[string]resourceEntry),
 		resourceTemplates:    make(map[string]resourceTemplateEntry),
-		prompts:              make(map[string]mcp.Prompt),
-		promptHandlers:       make(map[string]PromptHandlerFunc),
+		prompts:              make(map[string]ServerPrompt),
 		tools:                make(map[string]ServerTool),
 		name:                 name,
 		version:              version,
 		notificationHandlers: make(map[string]NotificationHandlerFunc),
+		promptCompletions:    make(map[string]CompletionProviderFunc),
+		resourceCompletions:  make(map[string]resourceCompletion),
+		progressInterval:     defaultProgressInterval,
+		rateLimitStore:       NewMemoryRateLimitStore(),
 		capabilities: serverCapabilities{
-			tools:     nil,
-			resources: nil,
-			prompts:   nil,
-			logging:   nil,
+			tools:       nil,
+			resources:   nil,
+			prompts:     nil,
+			logging:     nil,
+			completions: nil,
 		},
 	}
 
@@ -324,8 +428,9 @@ func (s *MCPServer) AddResources(resources ...ServerResource) {
 	s.resourcesMu.Lock()
 	for _, entry := range resources {
 		s.resources[entry.Resource.URI] = resourceEntry{
-			resource: entry.Resource,
-			handler:  entry.Handler,
+			resource:       entry.Resource,
+			handler:        entry.Handler,
+			requiredScopes: entry.RequiredScopes,
 		}
 	}
 	s.resourcesMu.Unlock()
@@ -360,18 +465,20 @@ func (s *MCPServer) RemoveResource(uri string) {
 	}
 }
 
-// AddResourceTemplate registers a new resource template and its handler
-func (s *MCPServer) AddResourceTemplate(
-	template mcp.ResourceTemplate,
-	handler ResourceTemplateHandlerFunc,
-) {
+// AddResourceTemplates registers multiple resource templates at once
+func (s *MCPServer) AddResourceTemplates(templates ...ServerResourceTemplate) {
 	s.implicitlyRegisterResourceCapabilities()
 
 	s.resourcesMu.Lock()
-	s.resourceTemplates[template.URITemplate.Raw()] = resourceTemplateEntry{
-		template: template,
-		handler:  handler,
+	for _, entry := range templates {
+		s.resourceTemplates[entry.Template.URITemplate.Raw()] = resourceTemplateEntry{
+			template:       entry.Template,
+			handler:        entry.Handler,
+			priority:       entry.Priority,
+			requiredScopes: entry.RequiredScopes,
+		}
 	}
+	s.templateIndex.Store(nil)
 	s.resourcesMu.Unlock()
 
 	// When the list of available resources changes, servers that declared the listChanged capability SHOULD send a notification
@@ -381,14 +488,44 @@ func (s *MCPServer) AddResourceTemplate(
 	}
 }
 
+// AddResourceTemplate registers a new resource template and its handler
+func (s *MCPServer) AddResourceTemplate(
+	template mcp.ResourceTemplate,
+	handler ResourceTemplateHandlerFunc,
+) {
+	s.AddResourceTemplates(ServerResourceTemplate{Template: template, Handler: handler})
+}
+
+// resourceTemplateIndex returns the index of the resource templates, building it
+// if the templates changed since it was last built. It must be called with
+// resourcesMu held.
+func (s *MCPServer) resourceTemplateIndex() *templateIndex {
+	if index := s.templateIndex.Load(); index != nil {
+		return index
+	}
+	templates := make([]ServerResourceTemplate, 0, len(s.resourceTemplates))
+	for _, entry := range s.resourceTemplates {
+		templates = append(templates, ServerResourceTemplate{
+			Template:       entry.template,
+			Handler:        entry.handler,
+			Priority:       entry.priority,
+			RequiredScopes: entry.requiredScopes,
+		})
+	}
+	index := newTemplateIndex(templates)
+	// templates can't change while resourcesMu is held, so the index built by
+	// concurrent readers is the same
+	s.templateIndex.Store(index)
+	return index
+}
+
 // AddPrompts registers multiple prompts at once
 func (s *MCPServer) AddPrompts(prompts ...ServerPrompt) {
 	s.implicitlyRegisterPromptCapabilities()
 
 	s.promptsMu.Lock()
 	for _, entry := range prompts {
-		s.prompts[entry.Prompt.Name] = entry.Prompt
-		s.promptHandlers[entry.Prompt.Name] = entry.Handler
+		s.prompts[entry.Prompt.Name] = entry
 	}
 	s.promptsMu.Unlock()
 
@@ -411,7 +548,6 @@ func (s *MCPServer) DeletePrompts(names ...string) {
 	for _, name := range names {
 		if _, ok := s.prompts[name]; ok {
 			delete(s.prompts, name)
-			delete(s.promptHandlers, name)
 			exists = true
 		}
 	}
@@ -453,6 +589,13 @@ func (s *MCPServer) implicitlyRegisterPromptCapabilities() {
 	)
 }
 
+func (s *MCPServer) implicitlyRegisterCompletionCapabilities() {
+	s.implicitlyRegisterCapabilities(
+		func() bool { return s.capabilities.completions != nil },
+		func() { s.capabilities.completions = mcp.ToBoolPtr(true) },
+	)
+}
+
 func (s *MCPServer) implicitlyRegisterCapabilities(check func() bool, register func()) {
 	s.capabilitiesMu.RLock()
 	if check() {
@@ -474,7 +617,7 @@ func (s *MCPServer) AddTools(tools ...ServerTool) {
 
 	s.toolsMu.Lock()
 	for _, entry := range tools {
-		s.tools[entry.Tool.Name] = entry
+		s.tools[entry.Tool.Name] = withCompiledSchemas(entry)
 	}
 	s.toolsMu.Unlock()
 
@@ -562,6 +705,10 @@ func (s *MCPServer) handleInitialize(
 		capabilities.Logging = &struct{}{}
 	}
 
+	if s.capabilities.completions != nil && *s.capabilities.completions {
+		capabilities.Completions = &struct{}{}
+	}
+
 	result := mcp.InitializeResult{
 		ProtocolVersion: s.protocolVersion(request.Params.ProtocolVersion),
 		ServerInfo: mcp.Implementation{
@@ -579,6 +726,21 @@ func (s *MCPServer) handleInitialize(
 		if sessionWithClientInfo, ok := session.(SessionWithClientInfo); ok {
 			sessionWithClientInfo.SetClientInfo(request.Params.ClientInfo)
 		}
+
+		// Store client capabilities if the session supports it
+		if sessionWithCapabilities, ok := session.(SessionWithClientCapabilities); ok {
+			sessionWithCapabilities.SetClientCapabilities(request.Params.Capabilities)
+		}
+
+		// Store the negotiated protocol version if the session supports it
+		if sessionWithVersion, ok := session.(SessionWithProtocolVersion); ok {
+			sessionWithVersion.SetProtocolVersion(result.ProtocolVersion)
+		}
+
+		// Roots cached for a previous client are stale
+		if sessionWithRoots, ok := session.(SessionWithRoots); ok {
+			sessionWithRoots.SetRoots(nil)
+		}
 	}
 	return &result, nil
 }
@@ -684,12 +846,28 @@ func (s *MCPServer) handleListResources(
 	request mcp.ListResourcesRequest,
 ) (*mcp.ListResourcesResult, *requestError) {
 	s.resourcesMu.RLock()
-	resources := make([]mcp.Resource, 0, len(s.resources))
-	for _, entry := range s.resources {
-		resources = append(resources, entry.resource)
+	resourceMap := make(map[string]ServerResource, len(s.resources))
+	for uri, entry := range s.resources {
+		resourceMap[uri] = ServerResource{Resource: entry.resource, RequiredScopes: entry.requiredScopes}
 	}
 	s.resourcesMu.RUnlock()
 
+	// Session-specific resources override global ones with the same URI
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithResources); ok {
+		for uri, serverResource := range session.GetSessionResources() {
+			resourceMap[uri] = serverResource
+		}
+	}
+
+	// Hide the resources the caller can't read
+	authorized := s.scopeChecker(ctx)
+	resources := make([]mcp.Resource, 0, len(resourceMap))
+	for _, entry := range resourceMap {
+		if authorized(entry.RequiredScopes) {
+			resources = append(resources, entry.Resource)
+		}
+	}
+
 	// Sort the resources by name
 	sort.Slice(resources, func(i, j int) bool {
 		return resources[i].Name < resources[j].Name
@@ -707,6 +885,9 @@ func (s *MCPServer) handleListResources(
 			err:  err,
 		}
 	}
+	if !clientSupportsProtocolVersion(ctx, protocolVersion20250618) {
+		resourcesToReturn = downgradeResources(resourcesToReturn)
+	}
 	result := mcp.ListResourcesResult{
 		Resources: resourcesToReturn,
 		PaginatedResult: mcp.PaginatedResult{
@@ -722,11 +903,27 @@ func (s *MCPServer) handleListResourceTemplates(
 	request mcp.ListResourceTemplatesRequest,
 ) (*mcp.ListResourceTemplatesResult, *requestError) {
 	s.resourcesMu.RLock()
-	templates := make([]mcp.ResourceTemplate, 0, len(s.resourceTemplates))
-	for _, entry := range s.resourceTemplates {
-		templates = append(templates, entry.template)
+	templateMap := make(map[string]ServerResourceTemplate, len(s.resourceTemplates))
+	for uriTemplate, entry := range s.resourceTemplates {
+		templateMap[uriTemplate] = ServerResourceTemplate{Template: entry.template, RequiredScopes: entry.requiredScopes}
 	}
 	s.resourcesMu.RUnlock()
+
+	// Session-specific templates override global ones with the same URI template
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithResources); ok {
+		for uriTemplate, serverTemplate := range session.GetSessionResourceTemplates() {
+			templateMap[uriTemplate] = serverTemplate
+		}
+	}
+
+	// Hide the templates the caller can't read
+	authorized := s.scopeChecker(ctx)
+	templates := make([]mcp.ResourceTemplate, 0, len(templateMap))
+	for _, entry := range templateMap {
+		if authorized(entry.RequiredScopes) {
+			templates = append(templates, entry.Template)
+		}
+	}
 	sort.Slice(templates, func(i, j int) bool {
 		return templates[i].Name < templates[j].Name
 	})
@@ -743,6 +940,9 @@ func (s *MCPServer) handleListResourceTemplates(
 			err:  err,
 		}
 	}
+	if !clientSupportsProtocolVersion(ctx, protocolVersion20250618) {
+		templatesToReturn = downgradeResourceTemplates(templatesToReturn)
+	}
 	result := mcp.ListResourceTemplatesResult{
 		ResourceTemplates: templatesToReturn,
 		PaginatedResult: mcp.PaginatedResult{
@@ -757,12 +957,35 @@ func (s *MCPServer) handleReadResource(
 	id any,
 	request mcp.ReadResourceRequest,
 ) (*mcp.ReadResourceResult, *requestError) {
+	var sessionResources map[string]ServerResource
+	var sessionTemplates *templateIndex
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithResources); ok {
+		sessionResources = session.GetSessionResources()
+		sessionTemplates = sessionResourceTemplateIndex(session, nil)
+	}
+
+	// First try direct resource handlers, session-specific ones first
+	var resourceHandler ResourceHandlerFunc
+	var requiredScopes []string
+	if serverResource, ok := sessionResources[request.Params.URI]; ok {
+		resourceHandler = serverResource.Handler
+		requiredScopes = serverResource.RequiredScopes
+	}
 	s.resourcesMu.RLock()
-	// First try direct resource handlers
-	if entry, ok := s.resources[request.Params.URI]; ok {
-		handler := entry.handler
+	if entry, ok := s.resources[request.Params.URI]; ok && resourceHandler == nil {
+		resourceHandler = entry.handler
+		requiredScopes = entry.requiredScopes
+	}
+	if resourceHandler != nil {
 		s.resourcesMu.RUnlock()
-		contents, err := handler(ctx, request)
+		if err := s.authorize(ctx, "resource", request.Params.URI, requiredScopes); err != nil {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.FORBIDDEN,
+				err:  err,
+			}
+		}
+		contents, err := resourceHandler(ctx, request)
 		if err != nil {
 			return nil, &requestError{
 				id:   id,
@@ -774,26 +997,34 @@ func (s *MCPServer) handleReadResource(
 	}
 
 	// If no direct handler found, try matching against templates
-	var matchedHandler ResourceTemplateHandlerFunc
+	// Session-specific templates take precedence over global ones. Among them, the
+	// most specific template matching the URI wins.
+	var matchedTemplate ServerResourceTemplate
 	var matched bool
-	for _, entry := range s.resourceTemplates {
-		template := entry.template
-		if matchesTemplate(request.Params.URI, template.URITemplate) {
-			matchedHandler = entry.handler
-			matched = true
-			matchedVars := template.URITemplate.Match(request.Params.URI)
-			// Convert matched variables to a map
-			request.Params.Arguments = make(map[string]any, len(matchedVars))
-			for name, value := range matchedVars {
-				request.Params.Arguments[name] = value.V
-			}
-			break
-		}
+	if sessionTemplates != nil {
+		matchedTemplate, matched = sessionTemplates.match(request.Params.URI)
+	}
+	if !matched {
+		matchedTemplate, matched = s.resourceTemplateIndex().match(request.Params.URI)
 	}
 	s.resourcesMu.RUnlock()
 
 	if matched {
-		contents, err := matchedHandler(ctx, request)
+		if err := s.authorize(ctx, "resource", request.Params.URI, matchedTemplate.RequiredScopes); err != nil {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.FORBIDDEN,
+				err:  err,
+			}
+		}
+		matchedVars := matchedTemplate.Template.URITemplate.Match(request.Params.URI)
+		// Convert matched variables to a map
+		request.Params.Arguments = make(map[string]any, len(matchedVars))
+		for name, value := range matchedVars {
+			request.Params.Arguments[name] = value.V
+		}
+
+		contents, err := matchedTemplate.Handler(ctx, request)
 		if err != nil {
 			return nil, &requestError{
 				id:   id,
@@ -826,12 +1057,28 @@ func (s *MCPServer) handleListPrompts(
 	request mcp.ListPromptsRequest,
 ) (*mcp.ListPromptsResult, *requestError) {
 	s.promptsMu.RLock()
-	prompts := make([]mcp.Prompt, 0, len(s.prompts))
-	for _, prompt := range s.prompts {
-		prompts = append(prompts, prompt)
+	promptMap := make(map[string]ServerPrompt, len(s.prompts))
+	for name, prompt := range s.prompts {
+		promptMap[name] = prompt
 	}
 	s.promptsMu.RUnlock()
 
+	// Session-specific prompts override global ones with the same name
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithPrompts); ok {
+		for name, serverPrompt := range session.GetSessionPrompts() {
+			promptMap[name] = serverPrompt
+		}
+	}
+
+	// Hide the prompts the caller can't get
+	authorized := s.scopeChecker(ctx)
+	prompts := make([]mcp.Prompt, 0, len(promptMap))
+	for _, entry := range promptMap {
+		if authorized(entry.RequiredScopes) {
+			prompts = append(prompts, entry.Prompt)
+		}
+	}
+
 	// sort prompts by name
 	sort.Slice(prompts, func(i, j int) bool {
 		return prompts[i].Name < prompts[j].Name
@@ -849,6 +1096,9 @@ func (s *MCPServer) handleListPrompts(
 			err:  err,
 		}
 	}
+	if !clientSupportsProtocolVersion(ctx, protocolVersion20250618) {
+		promptsToReturn = downgradePrompts(promptsToReturn)
+	}
 	result := mcp.ListPromptsResult{
 		Prompts: promptsToReturn,
 		PaginatedResult: mcp.PaginatedResult{
@@ -863,9 +1113,17 @@ func (s *MCPServer) handleGetPrompt(
 	id any,
 	request mcp.GetPromptRequest,
 ) (*mcp.GetPromptResult, *requestError) {
-	s.promptsMu.RLock()
-	handler, ok := s.promptHandlers[request.Params.Name]
-	s.promptsMu.RUnlock()
+	var prompt ServerPrompt
+	var ok bool
+	// Session-specific prompts take precedence over global ones
+	if session, isSessionWithPrompts := ClientSessionFromContext(ctx).(SessionWithPrompts); isSessionWithPrompts {
+		prompt, ok = session.GetSessionPrompts()[request.Params.Name]
+	}
+	if !ok {
+		s.promptsMu.RLock()
+		prompt, ok = s.prompts[request.Params.Name]
+		s.promptsMu.RUnlock()
+	}
 
 	if !ok {
 		return nil, &requestError{
@@ -875,7 +1133,15 @@ func (s *MCPServer) handleGetPrompt(
 		}
 	}
 
-	result, err := handler(ctx, request)
+	if err := s.authorize(ctx, "prompt", request.Params.Name, prompt.RequiredScopes); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.FORBIDDEN,
+			err:  err,
+		}
+	}
+
+	result, err := prompt.Handler(ctx, request)
 	if err != nil {
 		return nil, &requestError{
 			id:   id,
@@ -884,6 +1150,9 @@ func (s *MCPServer) handleGetPrompt(
 		}
 	}
 
+	if result != nil && !clientSupportsProtocolVersion(ctx, protocolVersion20250618) {
+		result = downgradeGetPromptResult(result)
+	}
 	return result, nil
 }
 
@@ -894,56 +1163,33 @@ func (s *MCPServer) handleListTools(
 ) (*mcp.ListToolsResult, *requestError) {
 	// Get the base tools from the server
 	s.toolsMu.RLock()
-	tools := make([]mcp.Tool, 0, len(s.tools))
-
-	// Get all tool names for consistent ordering
-	toolNames := make([]string, 0, len(s.tools))
-	for name := range s.tools {
-		toolNames = append(toolNames, name)
-	}
-
-	// Sort the tool names for consistent ordering
-	sort.Strings(toolNames)
-
-	// Add tools in sorted order
-	for _, name := range toolNames {
-		tools = append(tools, s.tools[name].Tool)
+	toolMap := make(map[string]ServerTool, len(s.tools))
+	for name, tool := range s.tools {
+		toolMap[name] = tool
 	}
 	s.toolsMu.RUnlock()
 
-	// Check if there are session-specific tools
-	session := ClientSessionFromContext(ctx)
-	if session != nil {
-		if sessionWithTools, ok := session.(SessionWithTools); ok {
-			if sessionTools := sessionWithTools.GetSessionTools(); sessionTools != nil {
-				// Override or add session-specific tools
-				// We need to create a map first to merge the tools properly
-				toolMap := make(map[string]mcp.Tool)
-
-				// Add global tools first
-				for _, tool := range tools {
-					toolMap[tool.Name] = tool
-				}
-
-				// Then override with session-specific tools
-				for name, serverTool := range sessionTools {
-					toolMap[name] = serverTool.Tool
-				}
-
-				// Convert back to slice
-				tools = make([]mcp.Tool, 0, len(toolMap))
-				for _, tool := range toolMap {
-					tools = append(tools, tool)
-				}
+	// Session-specific tools override global ones with the same name
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithTools); ok {
+		for name, serverTool := range session.GetSessionTools() {
+			toolMap[name] = serverTool
+		}
+	}
 
-				// Sort again to maintain consistent ordering
-				sort.Slice(tools, func(i, j int) bool {
-					return tools[i].Name < tools[j].Name
-				})
-			}
+	// Hide the tools the caller can't call
+	authorized := s.scopeChecker(ctx)
+	tools := make([]mcp.Tool, 0, len(toolMap))
+	for _, entry := range toolMap {
+		if authorized(entry.RequiredScopes) {
+			tools = append(tools, entry.Tool)
 		}
 	}
 
+	// Sort the tools by name for consistent ordering
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+
 	// Apply tool filters if any are defined
 	s.toolFiltersMu.RLock()
 	if len(s.toolFilters) > 0 {
@@ -968,6 +1214,9 @@ func (s *MCPServer) handleListTools(
 		}
 	}
 
+	if !clientSupportsProtocolVersion(ctx, protocolVersion20250618) {
+		toolsToReturn = downgradeTools(toolsToReturn)
+	}
 	result := mcp.ListToolsResult{
 		Tools: toolsToReturn,
 		PaginatedResult: mcp.PaginatedResult{
@@ -1014,6 +1263,36 @@ func (s *MCPServer) handleToolCall(
 		}
 	}
 
+	if err := s.authorize(ctx, "tool", request.Params.Name, tool.RequiredScopes); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.FORBIDDEN,
+			err:  err,
+		}
+	}
+
+	if err := s.checkRateLimits(ctx, request.Params.Name); err != nil {
+		code := mcp.INTERNAL_ERROR
+		if errors.Is(err, ErrRateLimited) {
+			code = mcp.RATE_LIMITED
+		}
+		return nil, &requestError{
+			id:   id,
+			code: code,
+			err:  err,
+		}
+	}
+
+	if s.validateToolInput {
+		if err := validateToolInput(tool, request); err != nil {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INVALID_PARAMS,
+				err:  fmt.Errorf("invalid arguments for tool '%s': %w", request.Params.Name, err),
+			}
+		}
+	}
+
 	finalHandler := tool.Handler
 
 	s.middlewareMu.RLock()
@@ -1025,7 +1304,16 @@ func (s *MCPServer) handleToolCall(
 		finalHandler = mw[i](finalHandler)
 	}
 
-	result, err := finalHandler(ctx, request)
+	toolCtx, span := s.startSpan(ctx, "execute_tool "+request.Params.Name, tracing.SpanKindInternal)
+	span.SetAttribute(spanAttributeToolName, request.Params.Name)
+	start := time.Now()
+	result, err := finalHandler(toolCtx, request)
+	s.metrics.observeToolCall(request.Params.Name, result, err, start)
+	span.RecordError(err)
+	if result != nil {
+		span.SetAttribute(spanAttributeToolIsError, result.IsError)
+	}
+	span.End()
 	if err != nil {
 		return nil, &requestError{
 			id:   id,
@@ -1034,6 +1322,19 @@ func (s *MCPServer) handleToolCall(
 		}
 	}
 
+	if s.validateToolOutput && result != nil && !result.IsError {
+		if err := validateToolOutput(tool, result); err != nil {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INTERNAL_ERROR,
+				err:  fmt.Errorf("invalid output of tool '%s': %w", request.Params.Name, err),
+			}
+		}
+	}
+
+	if result != nil && !clientSupportsProtocolVersion(ctx, protocolVersion20250618) {
+		result = downgradeCallToolResult(result)
+	}
 	return result, nil
 }
 
@@ -1041,6 +1342,17 @@ func (s *MCPServer) handleNotification(
 	ctx context.Context,
 	notification mcp.JSONRPCNotification,
 ) mcp.JSONRPCMessage {
+	ctx, span := s.startSpan(ctx, notification.Method, tracing.SpanKindConsumer)
+	span.SetAttribute(spanAttributeMethod, notification.Method)
+	defer span.End()
+
+	switch notification.Method {
+	case mcp.MethodNotificationRootsListChanged:
+		s.handleRootsListChanged(ctx)
+	case mcp.MethodNotificationCancelled:
+		s.handleCancelled(ctx, notification)
+	}
+
 	s.notificationHandlersMu.RLock()
 	handler, ok := s.notificationHandlers[notification.Method]
 	s.notificationHandlersMu.RUnlock()
@@ -2,7 +2,11 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -39,6 +43,36 @@ type SessionWithTools interface {
 	SetSessionTools(tools map[string]ServerTool)
 }
 
+// SessionWithPrompts is an extension of ClientSession that can store session-specific prompts
+type SessionWithPrompts interface {
+	ClientSession
+	// GetSessionPrompts returns the prompts specific to this session, if any, by name
+	// This method must be thread-safe for concurrent access
+	GetSessionPrompts() map[string]ServerPrompt
+	// SetSessionPrompts sets prompts specific to this session
+	// This method must be thread-safe for concurrent access
+	SetSessionPrompts(prompts map[string]ServerPrompt)
+}
+
+// SessionWithResources is an extension of ClientSession that can store session-specific
+// resources and resource templates
+type SessionWithResources interface {
+	ClientSession
+	// GetSessionResources returns the resources specific to this session, if any, by URI
+	// This method must be thread-safe for concurrent access
+	GetSessionResources() map[string]ServerResource
+	// SetSessionResources sets resources specific to this session
+	// This method must be thread-safe for concurrent access
+	SetSessionResources(resources map[string]ServerResource)
+	// GetSessionResourceTemplates returns the resource templates specific to this session,
+	// if any, by URI template
+	// This method must be thread-safe for concurrent access
+	GetSessionResourceTemplates() map[string]ServerResourceTemplate
+	// SetSessionResourceTemplates sets resource templates specific to this session
+	// This method must be thread-safe for concurrent access
+	SetSessionResourceTemplates(templates map[string]ServerResourceTemplate)
+}
+
 // SessionWithClientInfo is an extension of ClientSession that can store client info
 type SessionWithClientInfo interface {
 	ClientSession
@@ -48,6 +82,49 @@ type SessionWithClientInfo interface {
 	SetClientInfo(clientInfo mcp.Implementation)
 }
 
+// SessionWithClientCapabilities is an extension of ClientSession that can store the capabilities
+// the client advertised during initialization
+type SessionWithClientCapabilities interface {
+	ClientSession
+	// GetClientCapabilities returns the capabilities of the client for this session
+	GetClientCapabilities() mcp.ClientCapabilities
+	// SetClientCapabilities sets the capabilities of the client for this session
+	SetClientCapabilities(capabilities mcp.ClientCapabilities)
+}
+
+// SessionWithProtocolVersion is an extension of ClientSession that can store the protocol
+// version negotiated during initialization
+type SessionWithProtocolVersion interface {
+	ClientSession
+	// GetProtocolVersion returns the negotiated protocol version, or an empty string
+	// if the session has not been initialized yet
+	GetProtocolVersion() string
+	// SetProtocolVersion sets the negotiated protocol version for this session
+	SetProtocolVersion(version string)
+}
+
+// SessionWithRoots is an extension of ClientSession that caches the roots of the client
+type SessionWithRoots interface {
+	ClientSession
+	// GetRoots returns the cached roots of the client, and false if they have
+	// not been fetched yet or have been invalidated
+	GetRoots() ([]mcp.Root, bool)
+	// SetRoots caches the roots of the client. Setting nil invalidates the cache.
+	SetRoots(roots []mcp.Root)
+}
+
+// SessionWithRequests is an extension of ClientSession that can send requests to the client
+// and correlate the responses the client sends back
+type SessionWithRequests interface {
+	ClientSession
+	// SendRequest sends a JSON-RPC request to the client and blocks until the matching
+	// response arrives or ctx is done
+	SendRequest(ctx context.Context, method mcp.MCPMethod, params any) (json.RawMessage, error)
+	// HandleResponse delivers a response received from the client to the request waiting for it.
+	// It returns false if no request with the given ID is pending.
+	HandleResponse(id mcp.RequestId, result json.RawMessage, err error) bool
+}
+
 // SessionWithStreamableHTTPConfig extends ClientSession to support streamable HTTP transport configurations
 type SessionWithStreamableHTTPConfig interface {
 	ClientSession
@@ -101,10 +178,19 @@ func (s *MCPServer) UnregisterSession(
 	ctx context.Context,
 	sessionID string,
 ) {
+	s.subscriptions.removeSession(sessionID)
+	s.unregisterSession(ctx, sessionID)
+}
+
+// unregisterSession unregisters a session that may be registered again, like
+// the listening stream of a streamable HTTP session reconnecting, keeping its
+// resource subscriptions
+func (s *MCPServer) unregisterSession(ctx context.Context, sessionID string) {
 	sessionValue, ok := s.sessions.LoadAndDelete(sessionID)
 	if !ok {
 		return
 	}
+	s.sessionItemsLocks.Delete(sessionID)
 	if session, ok := sessionValue.(ClientSession); ok {
 		s.hooks.UnregisterSession(ctx, session)
 	}
@@ -115,15 +201,8 @@ func (s *MCPServer) SendNotificationToAllClients(
 	method string,
 	params map[string]any,
 ) {
-	notification := mcp.JSONRPCNotification{
-		JSONRPC: mcp.JSONRPC_VERSION,
-		Notification: mcp.Notification{
-			Method: method,
-			Params: mcp.NotificationParams{
-				AdditionalFields: params,
-			},
-		},
-	}
+	notification, span := s.newNotification(context.Background(), method, params)
+	defer span.End()
 
 	s.sessions.Range(func(k, v any) bool {
 		if session, ok := v.(ClientSession); ok && session.Initialized() {
@@ -131,6 +210,8 @@ func (s *MCPServer) SendNotificationToAllClients(
 			case session.NotificationChannel() <- notification:
 				// Successfully sent notification
 			default:
+				s.metrics.notificationDropped(method)
+				span.RecordError(fmt.Errorf("session %s: %w", session.SessionID(), ErrNotificationChannelBlocked))
 				// Channel is blocked, if there's an error hook, use it
 				if s.hooks != nil && len(s.hooks.OnError) > 0 {
 					err := ErrNotificationChannelBlocked
@@ -167,20 +248,15 @@ func (s *MCPServer) SendNotificationToClient(
 		sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 	}
 
-	notification := mcp.JSONRPCNotification{
-		JSONRPC: mcp.JSONRPC_VERSION,
-		Notification: mcp.Notification{
-			Method: method,
-			Params: mcp.NotificationParams{
-				AdditionalFields: params,
-			},
-		},
-	}
+	notification, span := s.newNotification(ctx, method, params)
+	defer span.End()
 
 	select {
 	case session.NotificationChannel() <- notification:
 		return nil
 	default:
+		s.metrics.notificationDropped(method)
+		span.RecordError(ErrNotificationChannelBlocked)
 		// Channel is blocked, if there's an error hook, use it
 		if s.hooks != nil && len(s.hooks.OnError) > 0 {
 			err := ErrNotificationChannelBlocked
@@ -219,20 +295,16 @@ func (s *MCPServer) SendNotificationToSpecificClient(
 		sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 	}
 
-	notification := mcp.JSONRPCNotification{
-		JSONRPC: mcp.JSONRPC_VERSION,
-		Notification: mcp.Notification{
-			Method: method,
-			Params: mcp.NotificationParams{
-				AdditionalFields: params,
-			},
-		},
-	}
+	notification, span := s.newNotification(context.Background(), method, params)
+	span.SetAttribute(spanAttributeSessionID, sessionID)
+	defer span.End()
 
 	select {
 	case session.NotificationChannel() <- notification:
 		return nil
 	default:
+		s.metrics.notificationDropped(method)
+		span.RecordError(ErrNotificationChannelBlocked)
 		// Channel is blocked, if there's an error hook, use it
 		if s.hooks != nil && len(s.hooks.OnError) > 0 {
 			err := ErrNotificationChannelBlocked
@@ -283,7 +355,7 @@ func (s *MCPServer) AddSessionTools(sessionID string, tools ...ServerTool) error
 
 	// Add new tools
 	for _, tool := range tools {
-		newSessionTools[tool.Tool.Name] = tool
+		newSessionTools[tool.Tool.Name] = withCompiledSchemas(tool)
 	}
 
 	// Set the tools (this should be thread-safe)
@@ -378,3 +450,314 @@ func (s *MCPServer) DeleteSessionTools(sessionID string, names ...string) error
 
 	return nil
 }
+
+// sessionItems stores the items of one kind (prompts, resources...) specific to a
+// session. It is safe for concurrent use.
+type sessionItems[T any] struct {
+	mu    sync.RWMutex
+	items map[string]T
+}
+
+func (s *sessionItems[T]) get() map[string]T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.items)
+}
+
+func (s *sessionItems[T]) set(items map[string]T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = items
+}
+
+// sessionResourceTemplates stores the resource templates specific to a session,
+// with their index built on demand. It is safe for concurrent use.
+type sessionResourceTemplates struct {
+	sessionItems[ServerResourceTemplate]
+	index *templateIndex // built on demand, reset when the templates change
+}
+
+func (s *sessionResourceTemplates) set(templates map[string]ServerResourceTemplate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = templates
+	s.index = nil
+}
+
+// templateIndex returns the index of the templates, building it if the templates
+// changed since it was last built
+func (s *sessionResourceTemplates) templateIndex() *templateIndex {
+	s.mu.RLock()
+	index := s.index
+	s.mu.RUnlock()
+	if index != nil {
+		return index
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index == nil {
+		s.index = newTemplateIndex(slices.Collect(maps.Values(s.items)))
+	}
+	return s.index
+}
+
+// sessionWithResourceTemplateIndex is implemented by the sessions of the built-in
+// transports, which cache the index of their resource templates
+type sessionWithResourceTemplateIndex interface {
+	resourceTemplateIndex() *templateIndex
+}
+
+// sessionResourceTemplateIndex returns the index of the resource templates of a
+// session, or nil if it has none. templates are the templates of the session if
+// the caller already got them.
+func sessionResourceTemplateIndex(session SessionWithResources, templates map[string]ServerResourceTemplate) *templateIndex {
+	if cached, ok := session.(sessionWithResourceTemplateIndex); ok {
+		return cached.resourceTemplateIndex()
+	}
+	if templates == nil {
+		templates = session.GetSessionResourceTemplates()
+	}
+	if len(templates) == 0 {
+		return nil
+	}
+	return newTemplateIndex(slices.Collect(maps.Values(templates)))
+}
+
+// loadSession returns the registered session with the given ID, or unsupported if
+// it doesn't implement T
+func loadSession[T ClientSession](s *MCPServer, sessionID string, unsupported error) (T, error) {
+	var session T
+	sessionValue, ok := s.sessions.Load(sessionID)
+	if !ok {
+		return session, ErrSessionNotFound
+	}
+	session, ok = sessionValue.(T)
+	if !ok {
+		return session, unsupported
+	}
+	return session, nil
+}
+
+// lockSessionItems locks the session items of the session with the given ID, so
+// that concurrent updates don't overwrite each other. It returns the unlock function.
+func (s *MCPServer) lockSessionItems(sessionID string) func() {
+	mu, _ := s.sessionItemsLocks.LoadOrStore(sessionID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+func (s *MCPServer) promptsListChanged() bool {
+	s.capabilitiesMu.RLock()
+	defer s.capabilitiesMu.RUnlock()
+	return s.capabilities.prompts != nil && s.capabilities.prompts.listChanged
+}
+
+func (s *MCPServer) resourcesListChanged() bool {
+	s.capabilitiesMu.RLock()
+	defer s.capabilitiesMu.RUnlock()
+	return s.capabilities.resources != nil && s.capabilities.resources.listChanged
+}
+
+// notifySessionListChanged sends a list changed notification to a session, if it is
+// initialized. Failures are reported to the error hooks.
+func (s *MCPServer) notifySessionListChanged(session ClientSession, method string) {
+	// It only makes sense to send list notifications to initialized sessions --
+	// if we're not initialized yet the client can't possibly have listed the items.
+	if !session.Initialized() {
+		return
+	}
+	if err := s.SendNotificationToSpecificClient(session.SessionID(), method, nil); err != nil {
+		if s.hooks != nil && len(s.hooks.OnError) > 0 {
+			hooks := s.hooks
+			go func(sID string, hooks *Hooks) {
+				ctx := context.Background()
+				hooks.onError(ctx, nil, "notification", map[string]any{
+					"method":    method,
+					"sessionID": sID,
+				}, fmt.Errorf("failed to send notification after updating session items: %w", err))
+			}(session.SessionID(), hooks)
+		}
+	}
+}
+
+// AddSessionPrompt adds a prompt for a specific session
+func (s *MCPServer) AddSessionPrompt(sessionID string, prompt mcp.Prompt, handler PromptHandlerFunc) error {
+	return s.AddSessionPrompts(sessionID, ServerPrompt{Prompt: prompt, Handler: handler})
+}
+
+// AddSessionPrompts adds prompts for a specific session. They override global
+// prompts with the same name for this session.
+func (s *MCPServer) AddSessionPrompts(sessionID string, prompts ...ServerPrompt) error {
+	session, err := loadSession[SessionWithPrompts](s, sessionID, ErrSessionDoesNotSupportPrompts)
+	if err != nil {
+		return err
+	}
+
+	s.implicitlyRegisterPromptCapabilities()
+
+	unlock := s.lockSessionItems(sessionID)
+	// copied, as sessions may return the map they read from
+	newSessionPrompts := maps.Clone(session.GetSessionPrompts())
+	if newSessionPrompts == nil {
+		newSessionPrompts = make(map[string]ServerPrompt, len(prompts))
+	}
+	for _, prompt := range prompts {
+		newSessionPrompts[prompt.Prompt.Name] = prompt
+	}
+	session.SetSessionPrompts(newSessionPrompts)
+	unlock()
+
+	if s.promptsListChanged() {
+		s.notifySessionListChanged(session, mcp.MethodNotificationPromptsListChanged)
+	}
+	return nil
+}
+
+// DeleteSessionPrompts removes prompts from a specific session
+func (s *MCPServer) DeleteSessionPrompts(sessionID string, names ...string) error {
+	session, err := loadSession[SessionWithPrompts](s, sessionID, ErrSessionDoesNotSupportPrompts)
+	if err != nil {
+		return err
+	}
+
+	unlock := s.lockSessionItems(sessionID)
+	// copied, as sessions may return the map they read from
+	newSessionPrompts := maps.Clone(session.GetSessionPrompts())
+	if newSessionPrompts == nil {
+		unlock()
+		return nil
+	}
+	for _, name := range names {
+		delete(newSessionPrompts, name)
+	}
+	session.SetSessionPrompts(newSessionPrompts)
+	unlock()
+
+	if s.promptsListChanged() {
+		s.notifySessionListChanged(session, mcp.MethodNotificationPromptsListChanged)
+	}
+	return nil
+}
+
+// AddSessionResource adds a resource for a specific session
+func (s *MCPServer) AddSessionResource(sessionID string, resource mcp.Resource, handler ResourceHandlerFunc) error {
+	return s.AddSessionResources(sessionID, ServerResource{Resource: resource, Handler: handler})
+}
+
+// AddSessionResources adds resources for a specific session. They override global
+// resources with the same URI for this session.
+func (s *MCPServer) AddSessionResources(sessionID string, resources ...ServerResource) error {
+	session, err := loadSession[SessionWithResources](s, sessionID, ErrSessionDoesNotSupportResources)
+	if err != nil {
+		return err
+	}
+
+	s.implicitlyRegisterResourceCapabilities()
+
+	unlock := s.lockSessionItems(sessionID)
+	// copied, as sessions may return the map they read from
+	newSessionResources := maps.Clone(session.GetSessionResources())
+	if newSessionResources == nil {
+		newSessionResources = make(map[string]ServerResource, len(resources))
+	}
+	for _, resource := range resources {
+		newSessionResources[resource.Resource.URI] = resource
+	}
+	session.SetSessionResources(newSessionResources)
+	unlock()
+
+	if s.resourcesListChanged() {
+		s.notifySessionListChanged(session, mcp.MethodNotificationResourcesListChanged)
+	}
+	return nil
+}
+
+// DeleteSessionResources removes resources from a specific session
+func (s *MCPServer) DeleteSessionResources(sessionID string, uris ...string) error {
+	session, err := loadSession[SessionWithResources](s, sessionID, ErrSessionDoesNotSupportResources)
+	if err != nil {
+		return err
+	}
+
+	unlock := s.lockSessionItems(sessionID)
+	// copied, as sessions may return the map they read from
+	newSessionResources := maps.Clone(session.GetSessionResources())
+	if newSessionResources == nil {
+		unlock()
+		return nil
+	}
+	for _, uri := range uris {
+		delete(newSessionResources, uri)
+	}
+	session.SetSessionResources(newSessionResources)
+	unlock()
+
+	if s.resourcesListChanged() {
+		s.notifySessionListChanged(session, mcp.MethodNotificationResourcesListChanged)
+	}
+	return nil
+}
+
+// AddSessionResourceTemplate adds a resource template for a specific session
+func (s *MCPServer) AddSessionResourceTemplate(
+	sessionID string,
+	template mcp.ResourceTemplate,
+	handler ResourceTemplateHandlerFunc,
+) error {
+	return s.AddSessionResourceTemplates(sessionID, ServerResourceTemplate{Template: template, Handler: handler})
+}
+
+// AddSessionResourceTemplates adds resource templates for a specific session. They
+// override global resource templates with the same URI template for this session.
+func (s *MCPServer) AddSessionResourceTemplates(sessionID string, templates ...ServerResourceTemplate) error {
+	session, err := loadSession[SessionWithResources](s, sessionID, ErrSessionDoesNotSupportResources)
+	if err != nil {
+		return err
+	}
+
+	s.implicitlyRegisterResourceCapabilities()
+
+	unlock := s.lockSessionItems(sessionID)
+	// copied, as sessions may return the map they read from
+	newSessionTemplates := maps.Clone(session.GetSessionResourceTemplates())
+	if newSessionTemplates == nil {
+		newSessionTemplates = make(map[string]ServerResourceTemplate, len(templates))
+	}
+	for _, template := range templates {
+		newSessionTemplates[template.Template.URITemplate.Raw()] = template
+	}
+	session.SetSessionResourceTemplates(newSessionTemplates)
+	unlock()
+
+	if s.resourcesListChanged() {
+		s.notifySessionListChanged(session, mcp.MethodNotificationResourcesListChanged)
+	}
+	return nil
+}
+
+// DeleteSessionResourceTemplates removes resource templates from a specific session
+func (s *MCPServer) DeleteSessionResourceTemplates(sessionID string, uriTemplates ...string) error {
+	session, err := loadSession[SessionWithResources](s, sessionID, ErrSessionDoesNotSupportResources)
+	if err != nil {
+		return err
+	}
+
+	unlock := s.lockSessionItems(sessionID)
+	// copied, as sessions may return the map they read from
+	newSessionTemplates := maps.Clone(session.GetSessionResourceTemplates())
+	if newSessionTemplates == nil {
+		unlock()
+		return nil
+	}
+	for _, uriTemplate := range uriTemplates {
+		delete(newSessionTemplates, uriTemplate)
+	}
+	session.SetSessionResourceTemplates(newSessionTemplates)
+	unlock()
+
+	if s.resourcesListChanged() {
+		s.notifySessionListChanged(session, mcp.MethodNotificationResourcesListChanged)
+	}
+	return nil
+}
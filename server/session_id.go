@@ -0,0 +1,304 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionIdManagerWithPrincipal is an extension of SessionIdManager binding the
+// session IDs to the principal of the requests (see WithPrincipalExtractor).
+// StreamableHTTPServer calls these methods instead of the ones of
+// SessionIdManager, with the principal extracted from the context of the HTTP
+// request, after the HTTPContextFunc if any. The principal is nil for
+// anonymous requests.
+type SessionIdManagerWithPrincipal interface {
+	SessionIdManager
+	// GenerateFor generates a session ID for the principal of an initialize request
+	GenerateFor(principal *Principal) string
+	// ValidateFor is Validate for a request of the principal
+	ValidateFor(sessionID string, principal *Principal) (isTerminated bool, err error)
+	// TerminateFor is Terminate for a request of the principal
+	TerminateFor(sessionID string, principal *Principal) (isNotAllowed bool, err error)
+}
+
+// SessionIdKey is a secret key signing session IDs
+type SessionIdKey struct {
+	// ID identifies the key in the session IDs it signs. It can't contain dots.
+	ID string
+	// Secret is the HMAC-SHA256 secret, at least 32 bytes long
+	Secret []byte
+}
+
+// SessionRevocationList keeps the terminated session IDs of a
+// SignedSessionIdManager. Implementations must be safe for concurrent use, and
+// can share the list between the replicas of a server, e.g. in a database.
+type SessionRevocationList interface {
+	// Revoke adds a session to the list, until it expires, or for ever if
+	// expiresAt is zero
+	Revoke(id string, expiresAt time.Time) error
+	// IsRevoked reports whether a session is in the list
+	IsRevoked(id string) (bool, error)
+}
+
+// SignedSessionIdConfig configures a SignedSessionIdManager
+type SignedSessionIdConfig struct {
+	// Keys sign and verify the session IDs. The first key signs the new IDs,
+	// and the others only verify the IDs they signed (see RotateKey).
+	Keys []SessionIdKey
+	// TTL is how long a session ID is valid after it's generated, or 0 for no
+	// expiration. Clients using an expired session ID get 404 Not Found, and
+	// must start a new session.
+	TTL time.Duration
+	// BindPrincipal binds the session IDs to the principal of the initialize
+	// request: requests of other principals with the session ID are rejected.
+	BindPrincipal bool
+	// Revocations keeps the terminated sessions. It defaults to a list in the
+	// memory of the server.
+	Revocations SessionRevocationList
+}
+
+// SignedSessionIdManager is a SessionIdManager generating session IDs signed
+// with HMAC-SHA256, which can't be forged without the key. The IDs can be
+// bound to the principal that initialized the session and can expire, and
+// terminated sessions are kept in a revocation list until they expire.
+//
+// Session IDs have the form mcp-session-{key ID}.{nonce}.{expiration}.{signature}.
+type SignedSessionIdManager struct {
+	mu     sync.RWMutex
+	keys   []SessionIdKey
+	config SignedSessionIdConfig
+	now    func() time.Time
+}
+
+var (
+	_ SessionIdManager              = (*SignedSessionIdManager)(nil)
+	_ SessionIdManagerWithPrincipal = (*SignedSessionIdManager)(nil)
+)
+
+// minSessionIdSecretSize is the minimum size of the secrets signing session IDs
+const minSessionIdSecretSize = 32
+
+// NewSignedSessionIdManager creates a session ID manager signing the IDs with the
+// keys of the config
+func NewSignedSessionIdManager(config SignedSessionIdConfig) (*SignedSessionIdManager, error) {
+	if len(config.Keys) == 0 {
+		return nil, errors.New("no session ID key")
+	}
+	for i, key := range config.Keys {
+		if err := validateSessionIdKey(key, config.Keys[:i]); err != nil {
+			return nil, err
+		}
+	}
+	if config.Revocations == nil {
+		config.Revocations = NewMemorySessionRevocationList()
+	}
+	return &SignedSessionIdManager{
+		keys:   slices.Clone(config.Keys),
+		config: config,
+		now:    time.Now,
+	}, nil
+}
+
+func validateSessionIdKey(key SessionIdKey, others []SessionIdKey) error {
+	if key.ID == "" || strings.Contains(key.ID, ".") {
+		return fmt.Errorf("invalid session ID key ID %q", key.ID)
+	}
+	if len(key.Secret) < minSessionIdSecretSize {
+		return fmt.Errorf("session ID key %q must be at least %d bytes long", key.ID, minSessionIdSecretSize)
+	}
+	if slices.ContainsFunc(others, func(other SessionIdKey) bool { return other.ID == key.ID }) {
+		return fmt.Errorf("duplicate session ID key %q", key.ID)
+	}
+	return nil
+}
+
+// RotateKey makes key the key signing new session IDs. The previous keys keep
+// verifying the IDs they signed, so active sessions go on: remove them with
+// RemoveKey once these sessions are over, e.g. after the TTL.
+func (m *SignedSessionIdManager) RotateKey(key SessionIdKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := validateSessionIdKey(key, m.keys); err != nil {
+		return err
+	}
+	m.keys = append([]SessionIdKey{key}, m.keys...)
+	return nil
+}
+
+// RemoveKey removes a key that doesn't sign new session IDs anymore, ending
+// the sessions whose ID it signed
+func (m *SignedSessionIdManager) RemoveKey(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := slices.IndexFunc(m.keys, func(key SessionIdKey) bool { return key.ID == id })
+	if i < 0 {
+		return fmt.Errorf("unknown session ID key %q", id)
+	}
+	if i == 0 {
+		return fmt.Errorf("session ID key %q signs new session IDs", id)
+	}
+	m.keys = slices.Delete(m.keys, i, i+1)
+	return nil
+}
+
+// Generate implements SessionIdManager, for anonymous requests
+func (m *SignedSessionIdManager) Generate() string {
+	return m.GenerateFor(nil)
+}
+
+// Validate implements SessionIdManager, for anonymous requests
+func (m *SignedSessionIdManager) Validate(sessionID string) (isTerminated bool, err error) {
+	return m.ValidateFor(sessionID, nil)
+}
+
+// Terminate implements SessionIdManager, for anonymous requests
+func (m *SignedSessionIdManager) Terminate(sessionID string) (isNotAllowed bool, err error) {
+	return m.TerminateFor(sessionID, nil)
+}
+
+// GenerateFor implements SessionIdManagerWithPrincipal
+func (m *SignedSessionIdManager) GenerateFor(principal *Principal) string {
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	var expiration int64
+	if m.config.TTL > 0 {
+		expiration = m.now().Add(m.config.TTL).Unix()
+	}
+	m.mu.RLock()
+	key := m.keys[0]
+	m.mu.RUnlock()
+
+	payload := key.ID + "." + base64.RawURLEncoding.EncodeToString(nonce) + "." + strconv.FormatInt(expiration, 10)
+	return idPrefix + payload + "." + m.sign(key, payload, principal)
+}
+
+// ValidateFor implements SessionIdManagerWithPrincipal. Expired and revoked
+// session IDs are terminated.
+func (m *SignedSessionIdManager) ValidateFor(sessionID string, principal *Principal) (isTerminated bool, err error) {
+	nonce, expiresAt, err := m.verify(sessionID, principal)
+	if err != nil {
+		return false, err
+	}
+	if !expiresAt.IsZero() && !m.now().Before(expiresAt) {
+		return true, nil
+	}
+	revoked, err := m.config.Revocations.IsRevoked(nonce)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+// TerminateFor implements SessionIdManagerWithPrincipal, adding the session to
+// the revocation list
+func (m *SignedSessionIdManager) TerminateFor(sessionID string, principal *Principal) (isNotAllowed bool, err error) {
+	nonce, expiresAt, err := m.verify(sessionID, principal)
+	if err != nil {
+		return false, err
+	}
+	if err := m.config.Revocations.Revoke(nonce, expiresAt); err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return false, nil
+}
+
+// verify checks the signature of a session ID, and returns its nonce and
+// expiration time
+func (m *SignedSessionIdManager) verify(sessionID string, principal *Principal) (string, time.Time, error) {
+	invalid := fmt.Errorf("invalid session id: %s", sessionID)
+	parts := strings.Split(strings.TrimPrefix(sessionID, idPrefix), ".")
+	if !strings.HasPrefix(sessionID, idPrefix) || len(parts) != 4 {
+		return "", time.Time{}, invalid
+	}
+	expiration, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", time.Time{}, invalid
+	}
+
+	m.mu.RLock()
+	i := slices.IndexFunc(m.keys, func(key SessionIdKey) bool { return key.ID == parts[0] })
+	var key SessionIdKey
+	if i >= 0 {
+		key = m.keys[i]
+	}
+	m.mu.RUnlock()
+	if i < 0 {
+		return "", time.Time{}, invalid
+	}
+	payload := parts[0] + "." + parts[1] + "." + parts[2]
+	if !hmac.Equal([]byte(parts[3]), []byte(m.sign(key, payload, principal))) {
+		return "", time.Time{}, invalid
+	}
+
+	var expiresAt time.Time
+	if expiration != 0 {
+		expiresAt = time.Unix(expiration, 0)
+	}
+	return parts[1], expiresAt, nil
+}
+
+// sign returns the signature of the payload of a session ID, bound to the
+// principal if the config says so
+func (m *SignedSessionIdManager) sign(key SessionIdKey, payload string, principal *Principal) string {
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte(payload))
+	if m.config.BindPrincipal {
+		// the principal ID is length-prefixed, so that it can't be confused
+		// with the payload
+		principalID := ""
+		if principal != nil {
+			principalID = principal.ID
+		}
+		mac.Write([]byte("\x00" + strconv.Itoa(len(principalID)) + ":" + principalID))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// MemorySessionRevocationList is a SessionRevocationList kept in memory. It's
+// the list of SignedSessionIdManagers without one.
+type MemorySessionRevocationList struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	now     func() time.Time
+}
+
+var _ SessionRevocationList = (*MemorySessionRevocationList)(nil)
+
+// NewMemorySessionRevocationList creates an empty in-memory revocation list
+func NewMemorySessionRevocationList() *MemorySessionRevocationList {
+	return &MemorySessionRevocationList{
+		revoked: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Revoke implements SessionRevocationList, dropping the expired sessions
+func (l *MemorySessionRevocationList) Revoke(id string, expiresAt time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for revokedID, revokedUntil := range l.revoked {
+		if !revokedUntil.IsZero() && !now.Before(revokedUntil) {
+			delete(l.revoked, revokedID)
+		}
+	}
+	l.revoked[id] = expiresAt
+	return nil
+}
+
+// IsRevoked implements SessionRevocationList
+func (l *MemorySessionRevocationList) IsRevoked(id string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.revoked[id]
+	return ok, nil
+}
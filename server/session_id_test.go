@@ -0,0 +1,240 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSessionIdKey(id string) SessionIdKey {
+	return SessionIdKey{ID: id, Secret: bytes.Repeat([]byte(id), 32)}
+}
+
+func TestNewSignedSessionIdManager(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []SessionIdKey
+	}{
+		{name: "no key"},
+		{name: "empty key ID", keys: []SessionIdKey{{Secret: make([]byte, 32)}}},
+		{name: "key ID with a dot", keys: []SessionIdKey{{ID: "a.b", Secret: make([]byte, 32)}}},
+		{name: "short secret", keys: []SessionIdKey{{ID: "a", Secret: make([]byte, 16)}}},
+		{name: "duplicate key", keys: []SessionIdKey{testSessionIdKey("a"), testSessionIdKey("a")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSignedSessionIdManager(SignedSessionIdConfig{Keys: tt.keys})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSignedSessionIdManager(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	newManager := func(t *testing.T, config SignedSessionIdConfig) *SignedSessionIdManager {
+		config.Keys = []SessionIdKey{testSessionIdKey("k1")}
+		manager, err := NewSignedSessionIdManager(config)
+		require.NoError(t, err)
+		manager.now = func() time.Time { return now }
+		return manager
+	}
+
+	t.Run("validates generated IDs", func(t *testing.T) {
+		manager := newManager(t, SignedSessionIdConfig{})
+		sessionID := manager.Generate()
+		assert.True(t, strings.HasPrefix(sessionID, idPrefix))
+		assert.NotEqual(t, sessionID, manager.Generate())
+
+		isTerminated, err := manager.Validate(sessionID)
+		require.NoError(t, err)
+		assert.False(t, isTerminated)
+	})
+
+	t.Run("rejects forged IDs", func(t *testing.T) {
+		manager := newManager(t, SignedSessionIdConfig{})
+		sessionID := manager.Generate()
+		other, err := NewSignedSessionIdManager(SignedSessionIdConfig{Keys: []SessionIdKey{
+			{ID: "k1", Secret: bytes.Repeat([]byte("x"), 32)},
+		}})
+		require.NoError(t, err)
+
+		for _, forged := range []string{
+			"",
+			"mcp-session-" + "a6d8f6b1-0a0c-4d4b-9a59-0f8a5a3f3c11",
+			sessionID[:len(sessionID)-2],
+			strings.Replace(sessionID, ".0.", ".1.", 1),
+			other.Generate(),
+		} {
+			_, err := manager.Validate(forged)
+			assert.Error(t, err, forged)
+			_, err = manager.Terminate(forged)
+			assert.Error(t, err, forged)
+		}
+	})
+
+	t.Run("expires IDs", func(t *testing.T) {
+		manager := newManager(t, SignedSessionIdConfig{TTL: time.Hour})
+		sessionID := manager.Generate()
+
+		now = now.Add(59 * time.Minute)
+		isTerminated, err := manager.Validate(sessionID)
+		require.NoError(t, err)
+		assert.False(t, isTerminated)
+
+		now = now.Add(time.Minute)
+		isTerminated, err = manager.Validate(sessionID)
+		require.NoError(t, err)
+		assert.True(t, isTerminated)
+	})
+
+	t.Run("binds IDs to principals", func(t *testing.T) {
+		manager := newManager(t, SignedSessionIdConfig{BindPrincipal: true})
+		alice, bob := &Principal{ID: "alice"}, &Principal{ID: "bob"}
+		sessionID := manager.GenerateFor(alice)
+
+		_, err := manager.ValidateFor(sessionID, alice)
+		assert.NoError(t, err)
+		_, err = manager.ValidateFor(sessionID, bob)
+		assert.Error(t, err)
+		_, err = manager.Validate(sessionID)
+		assert.Error(t, err)
+		_, err = manager.TerminateFor(sessionID, bob)
+		assert.Error(t, err)
+
+		// anonymous sessions are only valid for anonymous requests
+		anonymous := manager.Generate()
+		_, err = manager.ValidateFor(anonymous, nil)
+		assert.NoError(t, err)
+		_, err = manager.ValidateFor(anonymous, alice)
+		assert.Error(t, err)
+	})
+
+	t.Run("revokes terminated IDs", func(t *testing.T) {
+		manager := newManager(t, SignedSessionIdConfig{})
+		sessionID, otherID := manager.Generate(), manager.Generate()
+
+		isNotAllowed, err := manager.Terminate(sessionID)
+		require.NoError(t, err)
+		assert.False(t, isNotAllowed)
+
+		isTerminated, err := manager.Validate(sessionID)
+		require.NoError(t, err)
+		assert.True(t, isTerminated)
+		isTerminated, err = manager.Validate(otherID)
+		require.NoError(t, err)
+		assert.False(t, isTerminated)
+	})
+
+	t.Run("keeps sessions across key rotations", func(t *testing.T) {
+		manager := newManager(t, SignedSessionIdConfig{})
+		oldID := manager.Generate()
+
+		require.NoError(t, manager.RotateKey(testSessionIdKey("k2")))
+		assert.Error(t, manager.RotateKey(testSessionIdKey("k2")))
+		newID := manager.Generate()
+		assert.True(t, strings.HasPrefix(newID, idPrefix+"k2."))
+		for _, sessionID := range []string{oldID, newID} {
+			_, err := manager.Validate(sessionID)
+			assert.NoError(t, err)
+		}
+
+		assert.Error(t, manager.RemoveKey("k2"))
+		assert.Error(t, manager.RemoveKey("k3"))
+		require.NoError(t, manager.RemoveKey("k1"))
+		_, err := manager.Validate(oldID)
+		assert.Error(t, err)
+		_, err = manager.Validate(newID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestMemorySessionRevocationList(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	list := NewMemorySessionRevocationList()
+	list.now = func() time.Time { return now }
+
+	require.NoError(t, list.Revoke("expiring", now.Add(time.Hour)))
+	require.NoError(t, list.Revoke("forever", time.Time{}))
+	for _, id := range []string{"expiring", "forever"} {
+		revoked, err := list.IsRevoked(id)
+		require.NoError(t, err)
+		assert.True(t, revoked, id)
+	}
+	revoked, err := list.IsRevoked("other")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// expired sessions are dropped when revoking others
+	now = now.Add(time.Hour)
+	require.NoError(t, list.Revoke("other", time.Time{}))
+	assert.NotContains(t, list.revoked, "expiring")
+	assert.Contains(t, list.revoked, "forever")
+}
+
+func TestStreamableHTTP_SignedSessionIdManager(t *testing.T) {
+	manager, err := NewSignedSessionIdManager(SignedSessionIdConfig{
+		Keys:          []SessionIdKey{testSessionIdKey("k1")},
+		BindPrincipal: true,
+	})
+	require.NoError(t, err)
+	mcpServer := NewMCPServer("test-server", "1.0.0", WithPrincipalExtractor(func(ctx context.Context) *Principal {
+		if id, _ := ctx.Value(testPrincipalKey{}).(string); id != "" {
+			return &Principal{ID: id}
+		}
+		return nil
+	}))
+	var contextFuncCalls atomic.Int32
+	server := NewTestStreamableHTTPServer(mcpServer,
+		WithSessionIdManager(manager),
+		WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+			contextFuncCalls.Add(1)
+			return context.WithValue(ctx, testPrincipalKey{}, r.Header.Get("X-User"))
+		}),
+	)
+	defer server.Close()
+
+	send := func(method, user, sessionID string, body any) *http.Response {
+		var content []byte
+		if body != nil {
+			content, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, server.URL, bytes.NewReader(content))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", user)
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+	ping := map[string]any{"jsonrpc": "2.0", "id": 2, "method": "ping"}
+
+	resp := send(http.MethodPost, "alice", "", initRequest)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get(headerKeySessionID)
+
+	contextFuncCalls.Store(0)
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, "alice", sessionID, ping).StatusCode)
+	assert.Equal(t, int32(1), contextFuncCalls.Load(), "the context of a request is computed once")
+	// other principals can't use the session
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "bob", sessionID, ping).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodGet, "bob", sessionID, nil).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "alice", sessionID+"x", ping).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodDelete, "bob", sessionID, nil).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodDelete, "alice", sessionID+"x", nil).StatusCode)
+
+	// terminated sessions are gone
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, "alice", sessionID, nil).StatusCode)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "alice", sessionID, ping).StatusCode)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "alice", sessionID, nil).StatusCode)
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SessionData is the state of a streamable HTTP session kept in a SessionStore
+type SessionData struct {
+	// Initialized is set once the client initialized the session
+	Initialized bool `json:"initialized"`
+	// ClientInfo is the client that initialized the session
+	ClientInfo mcp.Implementation `json:"clientInfo"`
+	// ClientCapabilities are the capabilities the client advertised
+	ClientCapabilities mcp.ClientCapabilities `json:"clientCapabilities"`
+	// ProtocolVersion is the protocol version negotiated during initialization
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	// LogLevel is the level set by the client with logging/setLevel
+	LogLevel mcp.LoggingLevel `json:"logLevel,omitempty"`
+	// ToolNames are the names of the session-specific tools. As handlers
+	// can't be stored, the tools are restored from the catalog of the server
+	// (see WithSessionToolCatalog).
+	ToolNames []string `json:"toolNames,omitempty"`
+	// Subject is the subject of the access token that initialized the session,
+	// if the server is a resource server (see WithStreamableHTTPAuth)
+	Subject string `json:"subject,omitempty"`
+	// Revision is incremented each time the session is saved. Replicas only
+	// reload the sessions whose revision is newer than the one they know, so
+	// that the changes they didn't save yet aren't overwritten, and a save
+	// based on an outdated revision is a conflict (see SessionStore).
+	Revision uint64 `json:"revision,omitempty"`
+}
+
+// SessionStore keeps the state of the sessions of a StreamableHTTPServer, so
+// that they survive restarts and can be served by any replica of a server (see
+// WithSessionStore). Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// LoadSession returns the state of a session, or an error wrapping
+	// ErrSessionNotFound if the session isn't stored
+	LoadSession(ctx context.Context, sessionID string) (*SessionData, error)
+	// SaveSession stores the state of a session. If data.Revision isn't 0
+	// and the session is stored with another revision than data.Revision-1,
+	// the session was saved by another replica in the meantime: it must not
+	// be overwritten, and an error wrapping ErrSessionConflict is returned.
+	SaveSession(ctx context.Context, sessionID string, data *SessionData) error
+	// DeleteSession removes the state of a session, if stored
+	DeleteSession(ctx context.Context, sessionID string) error
+}
+
+// MemorySessionStore is a SessionStore keeping the state of the sessions in
+// memory. It's the store of servers without WithSessionStore.
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]SessionData
+}
+
+var _ SessionStore = (*MemorySessionStore)(nil)
+
+// NewMemorySessionStore creates an empty in-memory session store
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]SessionData)}
+}
+
+// LoadSession implements SessionStore
+func (m *MemorySessionStore) LoadSession(_ context.Context, sessionID string) (*SessionData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	data.ToolNames = slices.Clone(data.ToolNames)
+	return &data, nil
+}
+
+// SaveSession implements SessionStore
+func (m *MemorySessionStore) SaveSession(_ context.Context, sessionID string, data *SessionData) error {
+	stored := *data
+	stored.ToolNames = slices.Clone(data.ToolNames)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if previous, ok := m.sessions[sessionID]; ok {
+		if err := checkSessionRevision(&previous, data); err != nil {
+			return err
+		}
+	}
+	m.sessions[sessionID] = stored
+	return nil
+}
+
+// checkSessionRevision returns an error wrapping ErrSessionConflict if data
+// isn't the revision following the stored one
+func checkSessionRevision(stored, data *SessionData) error {
+	if data.Revision != 0 && stored.Revision != data.Revision-1 {
+		return fmt.Errorf("%w: saving revision %d over revision %d", ErrSessionConflict, data.Revision, stored.Revision)
+	}
+	return nil
+}
+
+// DeleteSession implements SessionStore
+func (m *MemorySessionStore) DeleteSession(_ context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, sessionID)
+	return nil
+}
+
+// FileSessionStore is a SessionStore keeping the state of each session in a
+// JSON file of a directory. It lets sessions survive restarts, and can be
+// shared by the servers of a process, e.g. in tests. Servers of different
+// processes sharing the directory may overwrite the sessions saved
+// concurrently, as their conflicts aren't detected.
+type FileSessionStore struct {
+	dir string
+	// mu makes the check of the revision and the write of a session atomic,
+	// for the servers sharing the store
+	mu sync.Mutex
+}
+
+var _ SessionStore = (*FileSessionStore)(nil)
+
+// NewFileSessionStore creates a session store keeping its files in dir, which
+// is created if needed
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session store directory: %w", err)
+	}
+	return &FileSessionStore{dir: dir}, nil
+}
+
+// path returns the path of the file of a session. Session IDs are hashed, as
+// they are chosen by the clients.
+func (f *FileSessionStore) path(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// LoadSession implements SessionStore
+func (f *FileSessionStore) LoadSession(_ context.Context, sessionID string) (*SessionData, error) {
+	content, err := os.ReadFile(f.path(sessionID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	var data SessionData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+	return &data, nil
+}
+
+// SaveSession implements SessionStore
+func (f *FileSessionStore) SaveSession(ctx context.Context, sessionID string, data *SessionData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	previous, err := f.LoadSession(ctx, sessionID)
+	if err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+	if previous != nil {
+		if err := checkSessionRevision(previous, data); err != nil {
+			return err
+		}
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+	// write a temporary file renamed over the previous one, so that readers
+	// never see a partial file
+	tmp, err := os.CreateTemp(f.dir, "session-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path(sessionID)); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// DeleteSession implements SessionStore
+func (f *FileSessionStore) DeleteSession(_ context.Context, sessionID string) error {
+	if err := os.Remove(f.path(sessionID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
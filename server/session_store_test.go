@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSessionStores(t *testing.T) {
+	fileStore, err := NewFileSessionStore(t.TempDir())
+	require.NoError(t, err)
+	stores := map[string]SessionStore{
+		"memory": NewMemorySessionStore(),
+		"file":   fileStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			_, err := store.LoadSession(ctx, "session-1")
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+
+			data := &SessionData{
+				Initialized:     true,
+				ClientInfo:      mcp.Implementation{Name: "client", Version: "1.0.0"},
+				ProtocolVersion: "2025-06-18",
+				LogLevel:        mcp.LoggingLevelDebug,
+				ToolNames:       []string{"a", "b"},
+			}
+			require.NoError(t, store.SaveSession(ctx, "session-1", data))
+			data.ToolNames[0] = "changed"
+
+			loaded, err := store.LoadSession(ctx, "session-1")
+			require.NoError(t, err)
+			assert.Equal(t, &SessionData{
+				Initialized:     true,
+				ClientInfo:      mcp.Implementation{Name: "client", Version: "1.0.0"},
+				ProtocolVersion: "2025-06-18",
+				LogLevel:        mcp.LoggingLevelDebug,
+				ToolNames:       []string{"a", "b"},
+			}, loaded)
+
+			// saves must follow the stored revision
+			require.NoError(t, store.SaveSession(ctx, "session-2", &SessionData{Revision: 1}))
+			require.NoError(t, store.SaveSession(ctx, "session-2", &SessionData{Revision: 2}))
+			assert.ErrorIs(t, store.SaveSession(ctx, "session-2", &SessionData{Revision: 2}), ErrSessionConflict)
+			assert.ErrorIs(t, store.SaveSession(ctx, "session-2", &SessionData{Revision: 4}), ErrSessionConflict)
+			loaded, err = store.LoadSession(ctx, "session-2")
+			require.NoError(t, err)
+			assert.Equal(t, uint64(2), loaded.Revision)
+
+			require.NoError(t, store.DeleteSession(ctx, "session-1"))
+			_, err = store.LoadSession(ctx, "session-1")
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+			assert.NoError(t, store.DeleteSession(ctx, "session-1"), "deleting a missing session is not an error")
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -1126,3 +1129,339 @@ func TestSessionWithClientInfo_Integration(t *testing.T) {
 	assert.Equal(t, clientInfo.Name, storedClientInfo.Name, "Client name should match")
 	assert.Equal(t, clientInfo.Version, storedClientInfo.Version, "Client version should match")
 }
+
+// sessionTestClientWithItems implements SessionWithPrompts and SessionWithResources for testing
+type sessionTestClientWithItems struct {
+	sessionTestClient
+	prompts           sessionItems[ServerPrompt]
+	resources         sessionItems[ServerResource]
+	resourceTemplates sessionItems[ServerResourceTemplate]
+}
+
+func (f *sessionTestClientWithItems) GetSessionPrompts() map[string]ServerPrompt {
+	return f.prompts.get()
+}
+
+func (f *sessionTestClientWithItems) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	f.prompts.set(prompts)
+}
+
+func (f *sessionTestClientWithItems) GetSessionResources() map[string]ServerResource {
+	return f.resources.get()
+}
+
+func (f *sessionTestClientWithItems) SetSessionResources(resources map[string]ServerResource) {
+	f.resources.set(resources)
+}
+
+func (f *sessionTestClientWithItems) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	return f.resourceTemplates.get()
+}
+
+func (f *sessionTestClientWithItems) SetSessionResourceTemplates(templates map[string]ServerResourceTemplate) {
+	f.resourceTemplates.set(templates)
+}
+
+var (
+	_ SessionWithPrompts   = (*sessionTestClientWithItems)(nil)
+	_ SessionWithResources = (*sessionTestClientWithItems)(nil)
+)
+
+func newSessionTestClientWithItems(sessionID string) *sessionTestClientWithItems {
+	return &sessionTestClientWithItems{sessionTestClient: sessionTestClient{
+		sessionID:           sessionID,
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}}
+}
+
+func textPromptHandler(text string) PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult(text, nil), nil
+	}
+}
+
+func textResourceHandler(text string) ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: text}}, nil
+	}
+}
+
+func TestMCPServer_SessionPrompts(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithPromptCapabilities(true))
+	server.AddPrompt(mcp.NewPrompt("global"), textPromptHandler("global"))
+	server.AddPrompt(mcp.NewPrompt("shared"), textPromptHandler("global shared"))
+
+	session := newSessionTestClientWithItems("session-1")
+	other := newSessionTestClientWithItems("session-2")
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	require.NoError(t, server.RegisterSession(context.Background(), other))
+
+	require.NoError(t, server.AddSessionPrompts(session.SessionID(),
+		ServerPrompt{Prompt: mcp.NewPrompt("session"), Handler: textPromptHandler("session")},
+		ServerPrompt{Prompt: mcp.NewPrompt("shared"), Handler: textPromptHandler("session shared")},
+	))
+
+	// only the session the prompts were added to is notified
+	select {
+	case notification := <-session.notificationChannel:
+		assert.Equal(t, mcp.MethodNotificationPromptsListChanged, notification.Method)
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Expected notification not received")
+	}
+	select {
+	case notification := <-other.notificationChannel:
+		t.Errorf("Unexpected notification for another session: %v", notification)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	listPrompts := func(session ClientSession) []string {
+		ctx := server.WithContext(context.Background(), session)
+		response := server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "prompts/list"}`))
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.ListPromptsResult)
+		var names []string
+		for _, prompt := range result.Prompts {
+			names = append(names, prompt.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"global", "session", "shared"}, listPrompts(session))
+	assert.Equal(t, []string{"global", "shared"}, listPrompts(other))
+
+	getPrompt := func(session ClientSession, name string) string {
+		ctx := server.WithContext(context.Background(), session)
+		response := server.HandleMessage(ctx, []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "prompts/get", "params": {"name": "`+name+`"}}`,
+		))
+		if errResponse, ok := response.(mcp.JSONRPCError); ok {
+			return errResponse.Error.Message
+		}
+		return response.(mcp.JSONRPCResponse).Result.(mcp.GetPromptResult).Description
+	}
+	assert.Equal(t, "session shared", getPrompt(session, "shared"))
+	assert.Equal(t, "global shared", getPrompt(other, "shared"))
+	assert.Equal(t, "global", getPrompt(session, "global"))
+	assert.Contains(t, getPrompt(other, "session"), "not found")
+
+	require.NoError(t, server.DeleteSessionPrompts(session.SessionID(), "shared"))
+	assert.Equal(t, "global shared", getPrompt(session, "shared"))
+	<-session.notificationChannel
+
+	// sessions that don't support per-session prompts are rejected
+	plain := &sessionTestClient{sessionID: "session-3", notificationChannel: make(chan mcp.JSONRPCNotification, 1)}
+	require.NoError(t, server.RegisterSession(context.Background(), plain))
+	err := server.AddSessionPrompt(plain.SessionID(), mcp.NewPrompt("session"), textPromptHandler("session"))
+	assert.ErrorIs(t, err, ErrSessionDoesNotSupportPrompts)
+	err = server.AddSessionPrompt("missing", mcp.NewPrompt("session"), textPromptHandler("session"))
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestMCPServer_SessionResources(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddResource(mcp.NewResource("test://global", "global"), textResourceHandler("global"))
+	server.AddResource(mcp.NewResource("test://shared", "shared"), textResourceHandler("global shared"))
+
+	session := newSessionTestClientWithItems("session-1")
+	other := newSessionTestClientWithItems("session-2")
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	require.NoError(t, server.RegisterSession(context.Background(), other))
+
+	require.NoError(t, server.AddSessionResource(session.SessionID(),
+		mcp.NewResource("test://shared", "shared"), textResourceHandler("session shared"),
+	))
+	require.NoError(t, server.AddSessionResourceTemplate(session.SessionID(),
+		mcp.NewResourceTemplate("test://users/{id}", "user"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{
+				URI:  request.Params.URI,
+				Text: "user " + request.Params.Arguments["id"].([]string)[0],
+			}}, nil
+		},
+	))
+
+	// list_changed is not enabled, so no notifications are sent
+	select {
+	case notification := <-session.notificationChannel:
+		t.Errorf("Unexpected notification: %v", notification)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	readResource := func(session ClientSession, uri string) string {
+		ctx := server.WithContext(context.Background(), session)
+		response := server.HandleMessage(ctx, []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": "`+uri+`"}}`,
+		))
+		if errResponse, ok := response.(mcp.JSONRPCError); ok {
+			return errResponse.Error.Message
+		}
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.ReadResourceResult)
+		return result.Contents[0].(mcp.TextResourceContents).Text
+	}
+	assert.Equal(t, "session shared", readResource(session, "test://shared"))
+	assert.Equal(t, "global shared", readResource(other, "test://shared"))
+	assert.Equal(t, "global", readResource(session, "test://global"))
+	assert.Equal(t, "user 42", readResource(session, "test://users/42"))
+	assert.Contains(t, readResource(other, "test://users/42"), "handler not found")
+
+	ctx := server.WithContext(context.Background(), session)
+	response := server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/list"}`))
+	resources := response.(mcp.JSONRPCResponse).Result.(mcp.ListResourcesResult).Resources
+	require.Len(t, resources, 2)
+
+	response = server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/templates/list"}`))
+	templates := response.(mcp.JSONRPCResponse).Result.(mcp.ListResourceTemplatesResult).ResourceTemplates
+	require.Len(t, templates, 1)
+	assert.Equal(t, "user", templates[0].Name)
+
+	require.NoError(t, server.DeleteSessionResources(session.SessionID(), "test://shared"))
+	require.NoError(t, server.DeleteSessionResourceTemplates(session.SessionID(), "test://users/{id}"))
+	assert.Equal(t, "global shared", readResource(session, "test://shared"))
+	assert.Contains(t, readResource(session, "test://users/42"), "handler not found")
+}
+
+// slowSessionTestClientWithItems widens the window between reading and writing the
+// session items, to surface lost updates
+type slowSessionTestClientWithItems struct {
+	*sessionTestClientWithItems
+}
+
+func (f *slowSessionTestClientWithItems) GetSessionPrompts() map[string]ServerPrompt {
+	defer time.Sleep(time.Millisecond)
+	return f.sessionTestClientWithItems.GetSessionPrompts()
+}
+
+func (f *slowSessionTestClientWithItems) GetSessionResources() map[string]ServerResource {
+	defer time.Sleep(time.Millisecond)
+	return f.sessionTestClientWithItems.GetSessionResources()
+}
+
+func (f *slowSessionTestClientWithItems) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	defer time.Sleep(time.Millisecond)
+	return f.sessionTestClientWithItems.GetSessionResourceTemplates()
+}
+
+func TestMCPServer_SessionItemsConcurrentUpdates(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	session := &slowSessionTestClientWithItems{newSessionTestClientWithItems("session-1")}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	// concurrent additions don't overwrite each other
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("item-%d", i)
+			assert.NoError(t, server.AddSessionPrompt(session.SessionID(), mcp.NewPrompt(name), textPromptHandler(name)))
+			assert.NoError(t, server.AddSessionResource(session.SessionID(),
+				mcp.NewResource("test://"+name, name), textResourceHandler(name),
+			))
+			assert.NoError(t, server.AddSessionResourceTemplate(session.SessionID(),
+				mcp.NewResourceTemplate("test://"+name+"/{id}", name), nil,
+			))
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, session.GetSessionPrompts(), 50)
+	assert.Len(t, session.GetSessionResources(), 50)
+	assert.Len(t, session.GetSessionResourceTemplates(), 50)
+}
+
+// liveSessionTestClientWithItems returns the maps of its items rather than copies
+type liveSessionTestClientWithItems struct {
+	*sessionTestClientWithItems
+}
+
+func (f *liveSessionTestClientWithItems) GetSessionPrompts() map[string]ServerPrompt {
+	f.prompts.mu.RLock()
+	defer f.prompts.mu.RUnlock()
+	return f.prompts.items
+}
+
+func (f *liveSessionTestClientWithItems) GetSessionResources() map[string]ServerResource {
+	f.resources.mu.RLock()
+	defer f.resources.mu.RUnlock()
+	return f.resources.items
+}
+
+func (f *liveSessionTestClientWithItems) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	f.resourceTemplates.mu.RLock()
+	defer f.resourceTemplates.mu.RUnlock()
+	return f.resourceTemplates.items
+}
+
+func TestMCPServer_SessionItemsAreCopied(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	session := &liveSessionTestClientWithItems{newSessionTestClientWithItems("session-1")}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	require.NoError(t, server.AddSessionPrompt(session.SessionID(), mcp.NewPrompt("a"), textPromptHandler("a")))
+	require.NoError(t, server.AddSessionResource(session.SessionID(), mcp.NewResource("test://a", "a"), textResourceHandler("a")))
+	require.NoError(t, server.AddSessionResourceTemplate(session.SessionID(), mcp.NewResourceTemplate("test://a/{id}", "a"), nil))
+
+	// the maps of the session may be read concurrently, so they are replaced
+	// rather than modified
+	prompts, resources, templates := session.prompts.items, session.resources.items, session.resourceTemplates.items
+	require.NoError(t, server.AddSessionPrompt(session.SessionID(), mcp.NewPrompt("b"), textPromptHandler("b")))
+	require.NoError(t, server.AddSessionResource(session.SessionID(), mcp.NewResource("test://b", "b"), textResourceHandler("b")))
+	require.NoError(t, server.AddSessionResourceTemplate(session.SessionID(), mcp.NewResourceTemplate("test://b/{id}", "b"), nil))
+	assert.Len(t, prompts, 1)
+	assert.Len(t, resources, 1)
+	assert.Len(t, templates, 1)
+
+	prompts, resources, templates = session.prompts.items, session.resources.items, session.resourceTemplates.items
+	require.NoError(t, server.DeleteSessionPrompts(session.SessionID(), "a"))
+	require.NoError(t, server.DeleteSessionResources(session.SessionID(), "test://a"))
+	require.NoError(t, server.DeleteSessionResourceTemplates(session.SessionID(), "test://a/{id}"))
+	assert.Len(t, prompts, 2)
+	assert.Len(t, resources, 2)
+	assert.Len(t, templates, 2)
+	assert.Equal(t, []string{"b"}, slices.Collect(maps.Keys(session.GetSessionPrompts())))
+}
+
+func TestSessionResourceTemplates_Index(t *testing.T) {
+	var templates sessionResourceTemplates
+	templates.set(map[string]ServerResourceTemplate{
+		"test://users/{id}": {Template: mcp.NewResourceTemplate("test://users/{id}", "user")},
+	})
+
+	// the index is built once
+	index := templates.templateIndex()
+	assert.Same(t, index, templates.templateIndex())
+	_, matched := index.match("test://users/42")
+	assert.True(t, matched)
+
+	// and rebuilt when the templates change
+	templates.set(map[string]ServerResourceTemplate{
+		"test://posts/{id}": {Template: mcp.NewResourceTemplate("test://posts/{id}", "post")},
+	})
+	assert.NotSame(t, index, templates.templateIndex())
+	_, matched = templates.templateIndex().match("test://users/42")
+	assert.False(t, matched)
+	_, matched = templates.templateIndex().match("test://posts/42")
+	assert.True(t, matched)
+}
+
+func TestMCPServer_SessionResourcesListChanged(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(false, true))
+	session := newSessionTestClientWithItems("session-1")
+	session.initialized = false
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	// uninitialized sessions aren't notified
+	require.NoError(t, server.AddSessionResource(session.SessionID(),
+		mcp.NewResource("test://a", "a"), textResourceHandler("a"),
+	))
+	assert.Len(t, session.notificationChannel, 0)
+
+	session.Initialize()
+	require.NoError(t, server.AddSessionResource(session.SessionID(),
+		mcp.NewResource("test://b", "b"), textResourceHandler("b"),
+	))
+	select {
+	case notification := <-session.notificationChannel:
+		assert.Equal(t, mcp.MethodNotificationResourcesListChanged, notification.Method)
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Expected notification not received")
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SessionTimeouts limits how long the sessions of the SSE and streamable HTTP
+// servers live, so that sessions abandoned by their clients don't stay in
+// memory for ever (see WithSSESessionTimeouts and
+// WithStreamableHTTPSessionTimeouts). Expired sessions are ended by a reaper
+// running in the background until the server is shut down.
+type SessionTimeouts struct {
+	// Idle ends the sessions that received no message for this long, or
+	// never if 0. Sessions handling a request or with an open listening
+	// stream aren't idle.
+	Idle time.Duration
+	// MaxLifetime ends the sessions this long after they were created, even
+	// if they are active, or never if 0
+	MaxLifetime time.Duration
+	// ReapInterval is how often the reaper looks for expired sessions. It
+	// defaults to a tenth of the shortest timeout.
+	ReapInterval time.Duration
+}
+
+// enabled reports whether sessions can expire
+func (t SessionTimeouts) enabled() bool {
+	return t.Idle > 0 || t.MaxLifetime > 0
+}
+
+// reapInterval returns how often the reaper runs
+func (t SessionTimeouts) reapInterval() time.Duration {
+	if t.ReapInterval > 0 {
+		return t.ReapInterval
+	}
+	shortest := t.Idle
+	if shortest <= 0 || (t.MaxLifetime > 0 && t.MaxLifetime < shortest) {
+		shortest = t.MaxLifetime
+	}
+	if shortest < 10 {
+		return shortest
+	}
+	return shortest / 10
+}
+
+// expired reports whether a session has expired at now
+func (t SessionTimeouts) expired(activity *sessionActivity, now time.Time) bool {
+	if t.MaxLifetime > 0 && now.Sub(activity.createdAt) >= t.MaxLifetime {
+		return true
+	}
+	return t.Idle > 0 && activity.busy.Load() == 0 && now.Sub(activity.lastActiveAt()) >= t.Idle
+}
+
+// sessionActivity tracks when a session was created and last active
+type sessionActivity struct {
+	createdAt  time.Time
+	lastActive atomic.Int64 // unix nanoseconds
+	busy       atomic.Int32 // requests and streams being served
+}
+
+func newSessionActivity(now time.Time) *sessionActivity {
+	activity := &sessionActivity{createdAt: now}
+	activity.lastActive.Store(now.UnixNano())
+	return activity
+}
+
+// touch records activity of the session
+func (a *sessionActivity) touch() {
+	a.lastActive.Store(time.Now().UnixNano())
+}
+
+// begin marks the session busy until the returned function is called
+func (a *sessionActivity) begin() (end func()) {
+	a.touch()
+	a.busy.Add(1)
+	return func() {
+		a.busy.Add(-1)
+		a.touch()
+	}
+}
+
+func (a *sessionActivity) lastActiveAt() time.Time {
+	return time.Unix(0, a.lastActive.Load())
+}
+
+// sessionReaper periodically calls a function ending the expired sessions
+type sessionReaper struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// startSessionReaper starts calling reap every interval of the timeouts, or
+// returns nil if sessions can't expire
+func startSessionReaper(timeouts SessionTimeouts, reap func(now time.Time)) *sessionReaper {
+	if !timeouts.enabled() {
+		return nil
+	}
+	r := &sessionReaper{stop: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(timeouts.reapInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				reap(now)
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// close stops the reaper. It can be called on nil reapers.
+func (r *sessionReaper) close() {
+	if r == nil {
+		return
+	}
+	r.stopOnce.Do(func() { close(r.stop) })
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionTimeouts(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	activity := newSessionActivity(now)
+
+	t.Run("reap interval", func(t *testing.T) {
+		assert.Equal(t, time.Minute, SessionTimeouts{Idle: 10 * time.Minute, MaxLifetime: time.Hour}.reapInterval())
+		assert.Equal(t, 6*time.Minute, SessionTimeouts{MaxLifetime: time.Hour}.reapInterval())
+		assert.Equal(t, time.Second, SessionTimeouts{Idle: time.Minute, ReapInterval: time.Second}.reapInterval())
+		assert.False(t, SessionTimeouts{ReapInterval: time.Second}.enabled())
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		timeouts := SessionTimeouts{Idle: time.Minute}
+		assert.False(t, timeouts.expired(activity, now.Add(59*time.Second)))
+		assert.True(t, timeouts.expired(activity, now.Add(time.Minute)))
+
+		// busy sessions aren't idle
+		end := activity.begin()
+		assert.False(t, timeouts.expired(activity, time.Now().Add(time.Hour)))
+		end()
+		assert.True(t, timeouts.expired(activity, time.Now().Add(time.Hour)))
+	})
+
+	t.Run("max lifetime", func(t *testing.T) {
+		timeouts := SessionTimeouts{MaxLifetime: time.Hour}
+		end := activity.begin()
+		defer end()
+		assert.False(t, timeouts.expired(activity, now.Add(59*time.Minute)))
+		assert.True(t, timeouts.expired(activity, now.Add(time.Hour)))
+	})
+}
+
+func TestSSEServer_SessionTimeouts(t *testing.T) {
+	unregistered := make(chan string, 1)
+	hooks := &Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session ClientSession) {
+		unregistered <- session.SessionID()
+	})
+	mcpServer := NewMCPServer("test", "1.0.0", WithHooks(hooks))
+	sseServer := NewSSEServer(mcpServer, WithSSESessionTimeouts(SessionTimeouts{
+		Idle:         200 * time.Millisecond,
+		ReapInterval: 10 * time.Millisecond,
+	}))
+	testServer := httptest.NewServer(sseServer)
+	sseServer.baseURL = testServer.URL
+	defer testServer.Close()
+	defer sseServer.Shutdown(context.Background())
+
+	sseResp, err := http.Get(testServer.URL + "/sse")
+	require.NoError(t, err)
+	defer sseResp.Body.Close()
+	endpointEvent, err := readSSEEvent(sseResp)
+	require.NoError(t, err)
+	messageURL := strings.TrimSpace(strings.Split(strings.Split(endpointEvent, "data: ")[1], "\n")[0])
+
+	// messages keep the session alive
+	for range 3 {
+		time.Sleep(100 * time.Millisecond)
+		resp, err := http.Post(messageURL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	}
+
+	// the idle session is unregistered and its connection closed
+	select {
+	case sessionID := <-unregistered:
+		assert.Contains(t, messageURL, sessionID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the idle session to be unregistered")
+	}
+	_, err = io.ReadAll(sseResp.Body)
+	assert.NoError(t, err)
+
+	resp, err := http.Post(messageURL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), "Invalid session ID")
+}
+
+func TestStreamableHTTP_SessionTimeouts(t *testing.T) {
+	newServer := func(t *testing.T, timeouts SessionTimeouts, hooks *Hooks) (*StreamableHTTPServer, string) {
+		manager, err := NewSignedSessionIdManager(SignedSessionIdConfig{Keys: []SessionIdKey{testSessionIdKey("k1")}})
+		require.NoError(t, err)
+		mcpServer := NewMCPServer("test", "1.0.0", WithHooks(hooks))
+		timeouts.ReapInterval = 10 * time.Millisecond
+		server := NewStreamableHTTPServer(mcpServer,
+			WithSessionIdManager(manager),
+			WithStreamableHTTPSessionTimeouts(timeouts),
+		)
+		testServer := httptest.NewServer(server)
+		t.Cleanup(func() {
+			testServer.Close()
+			_ = server.Shutdown(context.Background())
+		})
+		return server, testServer.URL
+	}
+	send := func(t *testing.T, url, sessionID string) int {
+		req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	initialize := func(t *testing.T, url string) string {
+		resp, err := postJSON(url, initRequest)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return resp.Header.Get(headerKeySessionID)
+	}
+
+	t.Run("idle", func(t *testing.T) {
+		server, url := newServer(t, SessionTimeouts{Idle: 200 * time.Millisecond}, &Hooks{})
+		sessionID := initialize(t, url)
+		server.sessionTools.set(sessionID, map[string]ServerTool{})
+
+		// requests keep the session alive
+		for range 3 {
+			time.Sleep(100 * time.Millisecond)
+			assert.Equal(t, http.StatusOK, send(t, url, sessionID))
+		}
+
+		// the idle session is terminated and its state freed
+		assert.Eventually(t, func() bool {
+			_, ok := server.sessionStates.Load(sessionID)
+			return !ok
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.Nil(t, server.sessionTools.get(sessionID))
+		_, err := server.sessionStore.LoadSession(context.Background(), sessionID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+		assert.Equal(t, http.StatusNotFound, send(t, url, sessionID))
+	})
+
+	t.Run("session ids bound to a principal", func(t *testing.T) {
+		manager, err := NewSignedSessionIdManager(SignedSessionIdConfig{
+			Keys:          []SessionIdKey{testSessionIdKey("k1")},
+			BindPrincipal: true,
+		})
+		require.NoError(t, err)
+		mcpServer := NewMCPServer("test", "1.0.0", WithPrincipalExtractor(func(ctx context.Context) *Principal {
+			if id, _ := ctx.Value(testPrincipalKey{}).(string); id != "" {
+				return &Principal{ID: id}
+			}
+			return nil
+		}))
+		server := NewStreamableHTTPServer(mcpServer,
+			WithSessionIdManager(manager),
+			WithStreamableHTTPSessionTimeouts(SessionTimeouts{Idle: 100 * time.Millisecond, ReapInterval: 10 * time.Millisecond}),
+			WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+				return context.WithValue(ctx, testPrincipalKey{}, r.Header.Get("X-User"))
+			}),
+		)
+		testServer := httptest.NewServer(server)
+		t.Cleanup(func() {
+			testServer.Close()
+			_ = server.Shutdown(context.Background())
+		})
+
+		req, _ := http.NewRequest(http.MethodPost, testServer.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"}}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", "alice")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		sessionID := resp.Header.Get(headerKeySessionID)
+
+		// the expired session id is terminated with the principal it is bound to
+		assert.Eventually(t, func() bool {
+			_, ok := server.sessionStates.Load(sessionID)
+			return !ok
+		}, 2*time.Second, 10*time.Millisecond)
+		isTerminated, err := manager.ValidateFor(sessionID, &Principal{ID: "alice"})
+		require.NoError(t, err)
+		assert.True(t, isTerminated)
+
+		req, _ = http.NewRequest(http.MethodPost, testServer.URL, strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", "alice")
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("max lifetime", func(t *testing.T) {
+		unregistered := make(chan string, 1)
+		hooks := &Hooks{}
+		hooks.AddOnUnregisterSession(func(ctx context.Context, session ClientSession) {
+			unregistered <- session.SessionID()
+		})
+		_, url := newServer(t, SessionTimeouts{Idle: time.Minute, MaxLifetime: 300 * time.Millisecond}, hooks)
+		sessionID := initialize(t, url)
+
+		// the listening stream is closed when the session expires
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		closed := make(chan error, 1)
+		go func() {
+			_, err := io.Copy(io.Discard, bufio.NewReader(resp.Body))
+			closed <- err
+		}()
+		select {
+		case err := <-closed:
+			assert.NoError(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the listening stream to be closed")
+		}
+		select {
+		case id := <-unregistered:
+			assert.Equal(t, sessionID, id)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected session %s to be unregistered", sessionID)
+		}
+		assert.Equal(t, http.StatusNotFound, send(t, url, sessionID))
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -17,6 +16,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // sseSession represents an active SSE connection.
@@ -28,8 +28,20 @@ type sseSession struct {
 	notificationChannel chan mcp.JSONRPCNotification
 	initialized         atomic.Bool
 	loggingLevel        atomic.Value
-	tools               sync.Map     // stores session-specific tools
-	clientInfo          atomic.Value // stores session-specific client info
+	tools               sync.Map                     // stores session-specific tools
+	prompts             sessionItems[ServerPrompt]   // stores session-specific prompts
+	resources           sessionItems[ServerResource] // stores session-specific resources
+	resourceTemplates   sessionResourceTemplates     // stores session-specific resource templates
+	clientInfo          atomic.Value                 // stores session-specific client info
+	clientCapabilities  atomic.Value                 // stores session-specific client capabilities
+	protocolVersion     atomic.Value                 // stores the negotiated protocol version
+	roots               rootsCache
+	pendingRequests     pendingRequests
+	activity            *sessionActivity
+	closeOnce           sync.Once
+	// subject of the access token that opened the session, whose messages
+	// must carry tokens of the same subject
+	subject string
 }
 
 // SSEContextFunc is a function that takes an existing context and the current
@@ -44,6 +56,11 @@ type SSEContextFunc func(ctx context.Context, r *http.Request) context.Context
 // function should return the base path (e.g., "/mcp/tenant123").
 type DynamicBasePathFunc func(r *http.Request, sessionID string) string
 
+// close ends the SSE connection of the session
+func (s *sseSession) close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
 func (s *sseSession) SessionID() string {
 	return s.sessionID
 }
@@ -95,6 +112,34 @@ func (s *sseSession) SetSessionTools(tools map[string]ServerTool) {
 	}
 }
 
+func (s *sseSession) GetSessionPrompts() map[string]ServerPrompt {
+	return s.prompts.get()
+}
+
+func (s *sseSession) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	s.prompts.set(prompts)
+}
+
+func (s *sseSession) GetSessionResources() map[string]ServerResource {
+	return s.resources.get()
+}
+
+func (s *sseSession) SetSessionResources(resources map[string]ServerResource) {
+	s.resources.set(resources)
+}
+
+func (s *sseSession) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	return s.resourceTemplates.get()
+}
+
+func (s *sseSession) SetSessionResourceTemplates(templates map[string]ServerResourceTemplate) {
+	s.resourceTemplates.set(templates)
+}
+
+func (s *sseSession) resourceTemplateIndex() *templateIndex {
+	return s.resourceTemplates.templateIndex()
+}
+
 func (s *sseSession) GetClientInfo() mcp.Implementation {
 	if value := s.clientInfo.Load(); value != nil {
 		if clientInfo, ok := value.(mcp.Implementation); ok {
@@ -108,11 +153,79 @@ func (s *sseSession) SetClientInfo(clientInfo mcp.Implementation) {
 	s.clientInfo.Store(clientInfo)
 }
 
+func (s *sseSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if value := s.clientCapabilities.Load(); value != nil {
+		if capabilities, ok := value.(mcp.ClientCapabilities); ok {
+			return capabilities
+		}
+	}
+	return mcp.ClientCapabilities{}
+}
+
+func (s *sseSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.clientCapabilities.Store(capabilities)
+}
+
+func (s *sseSession) GetProtocolVersion() string {
+	if version, ok := s.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return ""
+}
+
+func (s *sseSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
+func (s *sseSession) GetRoots() ([]mcp.Root, bool) {
+	return s.roots.get()
+}
+
+func (s *sseSession) SetRoots(roots []mcp.Root) {
+	s.roots.set(roots)
+}
+
+func (s *sseSession) rootsCache() *rootsCache {
+	return &s.roots
+}
+
+func (s *sseSession) SendRequest(
+	ctx context.Context,
+	method mcp.MCPMethod,
+	params any,
+) (json.RawMessage, error) {
+	request := newClientRequest(s.requestID.Add(1), method, params)
+	return s.pendingRequests.send(ctx, request, func(request mcp.JSONRPCRequest) error {
+		eventData, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		select {
+		case s.eventQueue <- fmt.Sprintf("event: message\ndata: %s\n\n", eventData):
+			return nil
+		case <-s.done:
+			return ErrSessionClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+func (s *sseSession) HandleResponse(id mcp.RequestId, result json.RawMessage, err error) bool {
+	return s.pendingRequests.deliver(id, result, err)
+}
+
 var (
-	_ ClientSession         = (*sseSession)(nil)
-	_ SessionWithTools      = (*sseSession)(nil)
-	_ SessionWithLogging    = (*sseSession)(nil)
-	_ SessionWithClientInfo = (*sseSession)(nil)
+	_ ClientSession                 = (*sseSession)(nil)
+	_ SessionWithTools              = (*sseSession)(nil)
+	_ SessionWithLogging            = (*sseSession)(nil)
+	_ SessionWithClientInfo         = (*sseSession)(nil)
+	_ SessionWithClientCapabilities = (*sseSession)(nil)
+	_ SessionWithProtocolVersion    = (*sseSession)(nil)
+	_ SessionWithRequests           = (*sseSession)(nil)
+	_ SessionWithRoots              = (*sseSession)(nil)
+	_ SessionWithPrompts            = (*sseSession)(nil)
+	_ SessionWithResources          = (*sseSession)(nil)
 )
 
 // SSEServer implements a Server-Sent Events (SSE) based MCP server.
@@ -129,6 +242,10 @@ type SSEServer struct {
 	srv                          *http.Server
 	contextFunc                  SSEContextFunc
 	dynamicBasePathFunc          DynamicBasePathFunc
+	logger                       util.Logger
+	auth                         *resourceServer
+	timeouts                     SessionTimeouts
+	reaper                       *sessionReaper
 
 	keepAlive         bool
 	keepAliveInterval time.Duration
@@ -139,6 +256,25 @@ type SSEServer struct {
 // SSEOption defines a function type for configuring SSEServer
 type SSEOption func(*SSEServer)
 
+// WithSSELogger sets the logger the server reports its errors to
+func WithSSELogger(logger util.Logger) SSEOption {
+	return func(s *SSEServer) {
+		s.logger = logger
+	}
+}
+
+// WithSSEAuth makes the server an OAuth 2.1 resource server: requests to the SSE
+// and message endpoints must carry a bearer access token accepted by the verifier
+// of the config, whose claims are available to handlers with
+// TokenClaimsFromContext, and the protected resource metadata is served at
+// /.well-known/oauth-protected-resource (see NewProtectedResourceMetadataHandler
+// for servers with a dynamic base path).
+func WithSSEAuth(config ResourceServerConfig) SSEOption {
+	return func(s *SSEServer) {
+		s.auth = &resourceServer{config: config}
+	}
+}
+
 // WithBaseURL sets the base URL for the SSE server
 func WithBaseURL(baseURL string) SSEOption {
 	return func(s *SSEServer) {
@@ -249,6 +385,15 @@ func WithKeepAlive(keepAlive bool) SSEOption {
 	}
 }
 
+// WithSSESessionTimeouts ends the sessions that are idle or too old, closing
+// their SSE connection. Sessions are idle when the client sends no message,
+// so clients answering the pings of WithKeepAlive are never idle.
+func WithSSESessionTimeouts(timeouts SessionTimeouts) SSEOption {
+	return func(s *SSEServer) {
+		s.timeouts = timeouts
+	}
+}
+
 // WithSSEContextFunc sets a function that will be called to customise the context
 // to the server using the incoming request.
 func WithSSEContextFunc(fn SSEContextFunc) SSEOption {
@@ -266,12 +411,17 @@ func NewSSEServer(server *MCPServer, opts ...SSEOption) *SSEServer {
 		useFullURLForMessageEndpoint: true,
 		keepAlive:                    false,
 		keepAliveInterval:            10 * time.Second,
+		logger:                       util.DefaultLogger(),
 	}
 
 	// Apply all options
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.auth != nil {
+		s.auth.logger = s.logger
+	}
+	s.reaper = startSessionReaper(s.timeouts, s.reapSessions)
 
 	return s
 }
@@ -310,6 +460,8 @@ func (s *SSEServer) Start(addr string) error {
 // Shutdown gracefully stops the SSE server, closing all active sessions
 // and shutting down the HTTP server.
 func (s *SSEServer) Shutdown(ctx context.Context) error {
+	s.reaper.close()
+
 	s.mu.RLock()
 	srv := s.srv
 	s.mu.RUnlock()
@@ -317,7 +469,7 @@ func (s *SSEServer) Shutdown(ctx context.Context) error {
 	if srv != nil {
 		s.sessions.Range(func(key, value any) bool {
 			if session, ok := value.(*sseSession); ok {
-				close(session.done)
+				session.close()
 			}
 			s.sessions.Delete(key)
 			return true
@@ -335,6 +487,12 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	if s.auth != nil {
+		var ok bool
+		if r, ok = s.auth.authenticate(w, r); !ok {
+			return
+		}
+	}
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -353,6 +511,10 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		eventQueue:          make(chan string, 100), // Buffer for events
 		sessionID:           sessionID,
 		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
+		activity:            newSessionActivity(time.Now()),
+	}
+	if claims, ok := TokenClaimsFromContext(r.Context()); ok {
+		session.subject = claims.Subject
 	}
 
 	s.sessions.Store(sessionID, session)
@@ -367,6 +529,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer s.server.UnregisterSession(r.Context(), sessionID)
+	defer session.pendingRequests.cancelAll(ErrSessionClosed)
 
 	// Start notification handler for this session
 	go func() {
@@ -438,7 +601,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprint(w, event)
 			flusher.Flush()
 		case <-r.Context().Done():
-			close(session.done)
+			session.close()
 			return
 		case <-session.done:
 			return
@@ -446,6 +609,17 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// reapSessions ends the sessions that expired at now
+func (s *SSEServer) reapSessions(now time.Time) {
+	s.sessions.Range(func(key, value any) bool {
+		if session, ok := value.(*sseSession); ok && s.timeouts.expired(session.activity, now) {
+			s.logger.Info("session expired", "sessionID", session.sessionID)
+			session.close()
+		}
+		return true
+	})
+}
+
 // GetMessageEndpointForClient returns the appropriate message endpoint URL with session ID
 // for the given request. This is the canonical way to compute the message endpoint for a client.
 // It handles both dynamic and static path modes, and honors the WithUseFullURLForMessageEndpoint flag.
@@ -470,6 +644,12 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		s.writeJSONRPCError(w, nil, mcp.INVALID_REQUEST, "Method not allowed")
 		return
 	}
+	if s.auth != nil {
+		var ok bool
+		if r, ok = s.auth.authenticate(w, r); !ok {
+			return
+		}
+	}
 
 	sessionID := r.URL.Query().Get("sessionId")
 	if sessionID == "" {
@@ -482,6 +662,14 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	session := sessionI.(*sseSession)
+	if s.auth != nil {
+		// the token must be issued to the subject that opened the session, so
+		// that session IDs leaked to other users are of no use to them
+		if claims, _ := TokenClaimsFromContext(r.Context()); claims == nil || claims.Subject != session.subject {
+			http.Error(w, "Session belongs to another subject", http.StatusForbidden)
+			return
+		}
+	}
 
 	// Set the client context before handling the message
 	ctx := s.server.WithContext(r.Context(), session)
@@ -505,9 +693,11 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 
 	// Create a new context for handling the message that will be canceled when the message handling is done
 	messageCtx, cancel := context.WithCancel(detachedCtx)
+	endActivity := session.activity.begin()
 
 	go func(ctx context.Context) {
 		defer cancel()
+		defer endActivity()
 		// Use the context that will be canceled when session is done
 		// Process message through MCPServer
 		response := s.server.HandleMessage(ctx, rawMessage)
@@ -516,7 +706,7 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 			var message string
 			if eventData, err := json.Marshal(response); err != nil {
 				// If there is an error marshalling the response, send a generic error response
-				log.Printf("failed to marshal response: %v", err)
+				s.logger.Error("failed to marshal response", "sessionID", sessionID, "err", err)
 				message = "event: message\ndata: {\"error\": \"internal error\",\"jsonrpc\": \"2.0\", \"id\": null}\n\n"
 			} else {
 				message = fmt.Sprintf("event: message\ndata: %s\n\n", eventData)
@@ -530,7 +720,7 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 				// Session is closed, don't try to queue
 			default:
 				// Queue is full, log this situation
-				log.Printf("Event queue full for session %s", sessionID)
+				s.logger.Warn("event queue full, dropping response", "sessionID", sessionID)
 			}
 		}
 	}(messageCtx)
@@ -702,6 +892,9 @@ func (s *SSEServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	path := r.URL.Path
+	if s.auth != nil && s.auth.serveMetadata(w, r) {
+		return
+	}
 	// Use exact path matching rather than Contains
 	ssePath := s.CompleteSsePath()
 	if ssePath != "" && path == ssePath {
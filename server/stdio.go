@@ -9,10 +9,12 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"sync/atomic"
 	"syscall"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // StdioContextFunc is a function that takes an existing context and returns
@@ -26,20 +28,29 @@ type StdioContextFunc func(ctx context.Context) context.Context
 // communicate via standard input/output streams using JSON-RPC messages.
 type StdioServer struct {
 	server      *MCPServer
-	errLogger   *log.Logger
+	logger      util.Logger
 	contextFunc StdioContextFunc
+
+	writeMu sync.Mutex // serializes writes to the output stream
 }
 
 // StdioOption defines a function type for configuring StdioServer
 type StdioOption func(*StdioServer)
 
-// WithErrorLogger sets the error logger for the server
-func WithErrorLogger(logger *log.Logger) StdioOption {
+// WithStdioLogger sets the logger the server reports its errors to
+func WithStdioLogger(logger util.Logger) StdioOption {
 	return func(s *StdioServer) {
-		s.errLogger = logger
+		s.logger = logger
 	}
 }
 
+// WithErrorLogger sets the error logger for the server
+//
+// Deprecated: Use WithStdioLogger instead.
+func WithErrorLogger(logger *log.Logger) StdioOption {
+	return WithStdioLogger(util.NewStdLogger(logger))
+}
+
 // WithStdioContextFunc sets a function that will be called to customise the context
 // to the server. Note that the stdio server uses the same context for all requests,
 // so this function will only be called once per server instance.
@@ -51,10 +62,19 @@ func WithStdioContextFunc(fn StdioContextFunc) StdioOption {
 
 // stdioSession is a static client session, since stdio has only one client.
 type stdioSession struct {
-	notifications chan mcp.JSONRPCNotification
-	initialized   atomic.Bool
-	loggingLevel  atomic.Value
-	clientInfo    atomic.Value // stores session-specific client info
+	notifications      chan mcp.JSONRPCNotification
+	requests           chan mcp.JSONRPCRequest // server -> client requests
+	requestID          atomic.Int64
+	pendingRequests    pendingRequests
+	initialized        atomic.Bool
+	loggingLevel       atomic.Value
+	clientInfo         atomic.Value // stores session-specific client info
+	clientCapabilities atomic.Value // stores session-specific client capabilities
+	protocolVersion    atomic.Value // stores the negotiated protocol version
+	roots              rootsCache
+	prompts            sessionItems[ServerPrompt]   // session-specific prompts
+	resources          sessionItems[ServerResource] // session-specific resources
+	resourceTemplates  sessionResourceTemplates     // session-specific resource templates
 }
 
 func (s *stdioSession) SessionID() string {
@@ -88,6 +108,90 @@ func (s *stdioSession) SetClientInfo(clientInfo mcp.Implementation) {
 	s.clientInfo.Store(clientInfo)
 }
 
+func (s *stdioSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if value := s.clientCapabilities.Load(); value != nil {
+		if capabilities, ok := value.(mcp.ClientCapabilities); ok {
+			return capabilities
+		}
+	}
+	return mcp.ClientCapabilities{}
+}
+
+func (s *stdioSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.clientCapabilities.Store(capabilities)
+}
+
+func (s *stdioSession) GetProtocolVersion() string {
+	if version, ok := s.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return ""
+}
+
+func (s *stdioSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
+func (s *stdioSession) GetSessionPrompts() map[string]ServerPrompt {
+	return s.prompts.get()
+}
+
+func (s *stdioSession) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	s.prompts.set(prompts)
+}
+
+func (s *stdioSession) GetSessionResources() map[string]ServerResource {
+	return s.resources.get()
+}
+
+func (s *stdioSession) SetSessionResources(resources map[string]ServerResource) {
+	s.resources.set(resources)
+}
+
+func (s *stdioSession) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	return s.resourceTemplates.get()
+}
+
+func (s *stdioSession) SetSessionResourceTemplates(templates map[string]ServerResourceTemplate) {
+	s.resourceTemplates.set(templates)
+}
+
+func (s *stdioSession) resourceTemplateIndex() *templateIndex {
+	return s.resourceTemplates.templateIndex()
+}
+
+func (s *stdioSession) GetRoots() ([]mcp.Root, bool) {
+	return s.roots.get()
+}
+
+func (s *stdioSession) SetRoots(roots []mcp.Root) {
+	s.roots.set(roots)
+}
+
+func (s *stdioSession) rootsCache() *rootsCache {
+	return &s.roots
+}
+
+func (s *stdioSession) SendRequest(
+	ctx context.Context,
+	method mcp.MCPMethod,
+	params any,
+) (json.RawMessage, error) {
+	request := newClientRequest(s.requestID.Add(1), method, params)
+	return s.pendingRequests.send(ctx, request, func(request mcp.JSONRPCRequest) error {
+		select {
+		case s.requests <- request:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+func (s *stdioSession) HandleResponse(id mcp.RequestId, result json.RawMessage, err error) bool {
+	return s.pendingRequests.deliver(id, result, err)
+}
+
 func (s *stdioSession) SetLogLevel(level mcp.LoggingLevel) {
 	s.loggingLevel.Store(level)
 }
@@ -101,32 +205,43 @@ func (s *stdioSession) GetLogLevel() mcp.LoggingLevel {
 }
 
 var (
-	_ ClientSession         = (*stdioSession)(nil)
-	_ SessionWithLogging    = (*stdioSession)(nil)
-	_ SessionWithClientInfo = (*stdioSession)(nil)
+	_ ClientSession                 = (*stdioSession)(nil)
+	_ SessionWithLogging            = (*stdioSession)(nil)
+	_ SessionWithClientInfo         = (*stdioSession)(nil)
+	_ SessionWithClientCapabilities = (*stdioSession)(nil)
+	_ SessionWithProtocolVersion    = (*stdioSession)(nil)
+	_ SessionWithRequests           = (*stdioSession)(nil)
+	_ SessionWithRoots              = (*stdioSession)(nil)
+	_ SessionWithPrompts            = (*stdioSession)(nil)
+	_ SessionWithResources          = (*stdioSession)(nil)
 )
 
 var stdioSessionInstance = stdioSession{
 	notifications: make(chan mcp.JSONRPCNotification, 100),
+	requests:      make(chan mcp.JSONRPCRequest, 100),
 }
 
 // NewStdioServer creates a new stdio server wrapper around an MCPServer.
-// It initializes the server with a default error logger that discards all output.
+// It initializes the server with util.DefaultLogger, which logs to stderr unless
+// the default slog logger was changed.
 func NewStdioServer(server *MCPServer) *StdioServer {
 	return &StdioServer{
 		server: server,
-		errLogger: log.New(
-			os.Stderr,
-			"",
-			log.LstdFlags,
-		), // Default to discarding logs
+		logger: util.DefaultLogger(),
 	}
 }
 
-// SetErrorLogger configures where error messages from the StdioServer are logged.
+// SetLogger configures where error messages from the StdioServer are logged.
 // The provided logger will receive all error messages generated during server operation.
+func (s *StdioServer) SetLogger(logger util.Logger) {
+	s.logger = logger
+}
+
+// SetErrorLogger configures where error messages from the StdioServer are logged.
+//
+// Deprecated: Use SetLogger instead.
 func (s *StdioServer) SetErrorLogger(logger *log.Logger) {
-	s.errLogger = logger
+	s.SetLogger(util.NewStdLogger(logger))
 }
 
 // SetContextFunc sets a function that will be called to customise the context
@@ -136,15 +251,20 @@ func (s *StdioServer) SetContextFunc(fn StdioContextFunc) {
 	s.contextFunc = fn
 }
 
-// handleNotifications continuously processes notifications from the session's notification channel
-// and writes them to the provided output. It runs until the context is cancelled.
+// handleNotifications continuously processes notifications and server-to-client requests
+// from the session's channels and writes them to the provided output. It runs until the
+// context is cancelled.
 // Any errors encountered while writing notifications are logged but do not stop the handler.
 func (s *StdioServer) handleNotifications(ctx context.Context, stdout io.Writer) {
 	for {
 		select {
 		case notification := <-stdioSessionInstance.notifications:
 			if err := s.writeResponse(notification, stdout); err != nil {
-				s.errLogger.Printf("Error writing notification: %v", err)
+				s.logger.Error("failed to write notification", "err", err)
+			}
+		case request := <-stdioSessionInstance.requests:
+			if err := s.writeResponse(request, stdout); err != nil {
+				s.logger.Error("failed to write request", "err", err)
 			}
 		case <-ctx.Done():
 			return
@@ -169,7 +289,7 @@ func (s *StdioServer) processInputStream(ctx context.Context, reader *bufio.Read
 			if err == io.EOF {
 				return nil
 			}
-			s.errLogger.Printf("Error reading input: %v", err)
+			s.logger.Error("failed to read input", "err", err)
 			return err
 		}
 
@@ -177,7 +297,7 @@ func (s *StdioServer) processInputStream(ctx context.Context, reader *bufio.Read
 			if err == io.EOF {
 				return nil
 			}
-			s.errLogger.Printf("Error handling message: %v", err)
+			s.logger.Error("failed to handle message", "err", err)
 			return err
 		}
 	}
@@ -222,6 +342,7 @@ func (s *StdioServer) Listen(
 		return fmt.Errorf("register session: %w", err)
 	}
 	defer s.server.UnregisterSession(ctx, stdioSessionInstance.SessionID())
+	defer stdioSessionInstance.pendingRequests.cancelAll(ErrSessionClosed)
 	ctx = s.server.WithContext(ctx, &stdioSessionInstance)
 
 	// Add in any custom context.
@@ -256,6 +377,33 @@ func (s *StdioServer) processMessage(
 		return s.writeResponse(response, writer)
 	}
 
+	// Requests are handled in their own goroutine, so that a handler waiting for the
+	// client (e.g. for sampling) doesn't stop us from reading the client's response,
+	// or a notifications/cancelled for the request.
+	// Initialize is handled inline to keep it ordered before anything that follows.
+	// Batches holding requests are handled like requests, as they can't hold initialize.
+	var baseMessage struct {
+		ID     any           `json:"id,omitempty"`
+		Method mcp.MCPMethod `json:"method"`
+	}
+	isRequest := false
+	if isBatch(rawMessage) {
+		messages, errResponse := parseBatch(rawMessage)
+		isRequest = errResponse == nil && batchHasRequests(messages)
+	} else if err := json.Unmarshal(rawMessage, &baseMessage); err == nil {
+		isRequest = baseMessage.ID != nil && baseMessage.Method != "" && baseMessage.Method != mcp.MethodInitialize
+	}
+	if isRequest {
+		go func() {
+			if response := s.server.HandleMessage(ctx, rawMessage); response != nil {
+				if err := s.writeResponse(response, writer); err != nil {
+					s.logger.Error("failed to write response", "err", err)
+				}
+			}
+		}()
+		return nil
+	}
+
 	// Handle the message using the wrapped server
 	response := s.server.HandleMessage(ctx, rawMessage)
 
@@ -280,6 +428,9 @@ func (s *StdioServer) writeResponse(
 		return err
 	}
 
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
 	// Write response followed by newline
 	if _, err := fmt.Fprintf(writer, "%s\n", responseBytes); err != nil {
 		return err
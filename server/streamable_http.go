@@ -3,10 +3,14 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -45,8 +49,8 @@ func WithStateLess(stateLess bool) StreamableHTTPOption {
 }
 
 // WithSessionIdManager sets a custom session id generator for the server.
-// By default, the server will use SimpleStatefulSessionIdGenerator, which generates
-// session ids with uuid, and it's insecure.
+// By default, the server will use InsecureStatefulSessionIdManager, which generates
+// session ids with uuid, and it's insecure. Use SignedSessionIdManager in production.
 // Notice: it will override the WithStateLess option.
 func WithSessionIdManager(manager SessionIdManager) StreamableHTTPOption {
 	return func(s *StreamableHTTPServer) {
@@ -83,13 +87,74 @@ func WithStreamableHTTPServer(srv *http.Server) StreamableHTTPOption {
 	}
 }
 
-// WithLogger sets the logger for the server
+// WithLogger sets the logger the server reports its errors to
 func WithLogger(logger util.Logger) StreamableHTTPOption {
 	return func(s *StreamableHTTPServer) {
 		s.logger = logger
 	}
 }
 
+// WithSessionStore sets the store keeping the state of the sessions, instead of
+// the memory of the server. With a shared store, any replica of a server can
+// serve any session: the state of a session is loaded at the start of each of
+// its requests, and saved when it changes.
+func WithSessionStore(store SessionStore) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.sessionStore = store
+	}
+}
+
+// WithSessionToolCatalog sets the tools that can be added to sessions. The
+// session store only keeps the names of the tools of a session, which are
+// restored from the catalog when the session is served by another replica or
+// after a restart. Session tools missing from the catalog are only available
+// on the replica that added them.
+func WithSessionToolCatalog(tools ...ServerTool) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.toolCatalog = make(map[string]ServerTool, len(tools))
+		for _, tool := range tools {
+			s.toolCatalog[tool.Tool.Name] = withCompiledSchemas(tool)
+		}
+	}
+}
+
+// WithEventStore makes the SSE streams resumable: the messages sent on them are
+// stored in the store with an event ID, and clients reconnecting with the
+// Last-Event-ID header get the messages they missed, as allowed by
+// https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#resumability-and-redelivery.
+// It has no effect on stateless servers.
+func WithEventStore(store EventStore) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.eventStore = store
+	}
+}
+
+// WithStreamableHTTPSessionTimeouts ends the sessions that are idle or too
+// old, as if the client terminated them: their state is freed, their listening
+// stream is closed and their session ID is terminated, so that clients using
+// it get 404 Not Found if the SessionIdManager supports it. The timeouts are
+// tracked by each replica of a server.
+func WithStreamableHTTPSessionTimeouts(timeouts SessionTimeouts) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.timeouts = timeouts
+	}
+}
+
+// WithStreamableHTTPAuth makes the server an OAuth 2.1 resource server: requests
+// must carry a bearer access token accepted by the verifier of the config, whose
+// claims are available to handlers with TokenClaimsFromContext, and the protected
+// resource metadata is served at /.well-known/oauth-protected-resource.
+// Like SSE sessions, streamable HTTP sessions are bound to the subject of the
+// token that initialized them: requests carrying tokens of other subjects get
+// 403 Forbidden. The subject is kept with the state of the session in the
+// session store, and sessions whose state was lost are bound to the subject of
+// the next request that uses them.
+func WithStreamableHTTPAuth(config ResourceServerConfig) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.auth = &resourceServer{config: config}
+	}
+}
+
 // StreamableHTTPServer implements a Streamable-http based MCP server.
 // It communicates with clients over HTTP protocol, supporting both direct HTTP responses, and SSE streams.
 // https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#streamable-http
@@ -111,13 +176,11 @@ func WithLogger(logger util.Logger) StreamableHTTPOption {
 // not trigger the session registration. So the methods like `SendNotificationToSpecificClient`
 // or `hooks.onRegisterSession` will not be triggered for POST messages.
 //
-// The current implementation does not support the following features from the specification:
-//   - Batching of requests/notifications/responses in arrays.
-//   - Stream Resumability
+// Streams are only resumable if the server has an event store (see WithEventStore).
 type StreamableHTTPServer struct {
-	server            *MCPServer
-	sessionTools      *sessionToolsStore
-	sessionRequestIDs sync.Map // sessionId --> last requestID(*atomic.Int64)
+	server        *MCPServer
+	sessionTools  *sessionToolsStore
+	sessionStates sync.Map // sessionId --> *streamableSessionState
 
 	httpServer *http.Server
 	mu         sync.RWMutex
@@ -127,6 +190,12 @@ type StreamableHTTPServer struct {
 	sessionIdManager        SessionIdManager
 	listenHeartbeatInterval time.Duration
 	logger                  util.Logger
+	auth                    *resourceServer
+	eventStore              EventStore
+	sessionStore            SessionStore
+	toolCatalog             map[string]ServerTool
+	timeouts                SessionTimeouts
+	reaper                  *sessionReaper
 }
 
 // NewStreamableHTTPServer creates a new streamable-http server instance
@@ -137,17 +206,32 @@ func NewStreamableHTTPServer(server *MCPServer, opts ...StreamableHTTPOption) *S
 		endpointPath:     "/mcp",
 		sessionIdManager: &InsecureStatefulSessionIdManager{},
 		logger:           util.DefaultLogger(),
+		sessionStore:     NewMemorySessionStore(),
 	}
 
 	// Apply all options
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.auth != nil {
+		s.auth.logger = s.logger
+	}
+	s.reaper = startSessionReaper(s.timeouts, s.reapSessions)
 	return s
 }
 
 // ServeHTTP implements the http.Handler interface.
 func (s *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.auth != nil {
+		if s.auth.serveMetadata(w, r) {
+			return
+		}
+		var ok bool
+		if r, ok = s.auth.authenticate(w, r); !ok {
+			return
+		}
+	}
+
 	switch r.Method {
 	case http.MethodPost:
 		s.handlePost(w, r)
@@ -169,6 +253,11 @@ func (s *StreamableHTTPServer) Start(addr string) error {
 	if s.httpServer == nil {
 		mux := http.NewServeMux()
 		mux.Handle(s.endpointPath, s)
+		if s.auth != nil {
+			for _, path := range s.auth.metadataPaths() {
+				mux.Handle(path, s)
+			}
+		}
 		s.httpServer = &http.Server{
 			Addr:    addr,
 			Handler: mux,
@@ -189,6 +278,7 @@ func (s *StreamableHTTPServer) Start(addr string) error {
 // Shutdown gracefully stops the server, closing all active sessions
 // and shutting down the HTTP server.
 func (s *StreamableHTTPServer) Shutdown(ctx context.Context) error {
+	s.reaper.close()
 
 	// shutdown the server if needed (may use as a http.Handler)
 	s.mu.RLock()
@@ -203,9 +293,31 @@ func (s *StreamableHTTPServer) Shutdown(ctx context.Context) error {
 // --- internal methods ---
 
 const (
-	headerKeySessionID = "Mcp-Session-Id"
+	headerKeySessionID       = "Mcp-Session-Id"
+	headerKeyProtocolVersion = "Mcp-Protocol-Version"
+	headerKeyLastEventID     = "Last-Event-ID"
 )
 
+// defaultStreamableProtocolVersion is the protocol version assumed for requests
+// that carry no protocol version header when none was negotiated, as required by
+// https://modelcontextprotocol.io/specification/2025-06-18/basic/transports#protocol-version-header
+const defaultStreamableProtocolVersion = "2025-03-26"
+
+// acceptsEventStream reports whether the client accepts SSE responses
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// requestProtocolVersion returns the protocol version header of the request, and
+// false if the client sent a version the server doesn't support.
+func requestProtocolVersion(r *http.Request) (string, bool) {
+	version := r.Header.Get(headerKeyProtocolVersion)
+	if version != "" && !slices.Contains(mcp.ValidProtocolVersions, version) {
+		return "", false
+	}
+	return version, true
+}
+
 func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request) {
 	// post request carry request/notification message
 
@@ -223,96 +335,186 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 		return
 	}
 	var baseMessage struct {
+		ID     any           `json:"id,omitempty"`
 		Method mcp.MCPMethod `json:"method"`
 	}
-	if err := json.Unmarshal(rawData, &baseMessage); err != nil {
-		s.writeJSONRPCError(w, nil, mcp.PARSE_ERROR, "request body is not valid json")
-		return
+	var batch []json.RawMessage
+	hasRequests := false
+	if isBatch(rawData) {
+		var errResponse mcp.JSONRPCMessage
+		if batch, errResponse = parseBatch(rawData); errResponse != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(errResponse)
+			return
+		}
+		hasRequests = batchHasRequests(batch)
+	} else {
+		if err := json.Unmarshal(rawData, &baseMessage); err != nil {
+			s.writeJSONRPCError(w, nil, mcp.PARSE_ERROR, "request body is not valid json")
+			return
+		}
+		hasRequests = baseMessage.ID != nil && baseMessage.Method != ""
 	}
+	// batches can't hold initialize requests
 	isInitializeRequest := baseMessage.Method == mcp.MethodInitialize
 
 	// Prepare the session for the mcp server
 	// The session is ephemeral. Its life is the same as the request. It's only created
 	// for interaction with the mcp server.
-	var sessionID string
+	// the context is computed once, as the session ID is bound to its principal
+	requestCtx := s.requestContext(r)
+	principal := s.sessionIdPrincipal(requestCtx)
+	var sessionID, protocolVersion string
 	if isInitializeRequest {
 		// generate a new one for initialize request
-		sessionID = s.sessionIdManager.Generate()
+		sessionID = s.generateSessionID(principal)
 	} else {
 		// Get session ID from header.
 		// Stateful servers need the client to carry the session ID.
 		sessionID = r.Header.Get(headerKeySessionID)
-		isTerminated, err := s.sessionIdManager.Validate(sessionID)
-		if err != nil {
-			http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		if !s.validateSessionID(w, principal, sessionID) {
 			return
 		}
-		if isTerminated {
-			http.Error(w, "Session terminated", http.StatusNotFound)
+
+		var ok bool
+		if protocolVersion, ok = requestProtocolVersion(r); !ok {
+			http.Error(w, "Unsupported protocol version", http.StatusBadRequest)
 			return
 		}
 	}
 
-	session := newStreamableHttpSession(sessionID, s.sessionTools)
+	var state *streamableSessionState
+	if sessionID == "" {
+		// the requests of stateless servers can come from any client, so they
+		// don't share any state
+		state = newStatelessSessionState()
+	} else {
+		state = s.sessionState(sessionID)
+		state.principal.Store(principal)
+	}
+	defer state.activity.begin()()
+	if !isInitializeRequest {
+		if err := s.loadSession(r.Context(), sessionID, state); err != nil {
+			s.logger.Error("failed to load session", "sessionID", sessionID, "err", err)
+			http.Error(w, "Failed to load session", http.StatusInternalServerError)
+			return
+		}
+	}
+	if !s.checkSessionSubject(w, r, state) {
+		return
+	}
+	session := s.newSession(sessionID, state)
+	session.protocolVersion = protocolVersion
+	if !hasRequests {
+		// notifications and responses are answered with 202 Accepted right away,
+		// so requests to the client have to go through the listening stream
+		session.streamClosed.Store(true)
+	}
 
 	// Set the client context before handling the message
-	ctx := s.server.WithContext(r.Context(), session)
-	if s.contextFunc != nil {
-		ctx = s.contextFunc(ctx, r)
+	ctx := s.server.WithContext(requestCtx, session)
+
+	// the events of the SSE response are stored in a stream of their own, that
+	// stays open until the response is stored
+	var streamID string
+	if s.eventStore != nil && sessionID != "" {
+		streamID = sessionID + "/" + uuid.NewString()
+		ended := make(chan struct{})
+		state.openStreams.Store(streamID, ended)
+		defer func() {
+			state.openStreams.Delete(streamID)
+			close(ended)
+		}()
 	}
 
-	// handle potential notifications
+	// handle potential notifications and requests to the client
 	mu := sync.Mutex{}
 	upgradedHeader := false
 	done := make(chan struct{})
 
+	writeEvent := func(data any) {
+		mu.Lock()
+		defer mu.Unlock()
+		// if the done chan is closed, as the request is terminated, just return
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if ctx.Err() != nil {
+			// the client is gone, but can get the event by resuming the stream
+			s.storeEvent(ctx, streamID, data)
+			return
+		}
+		defer func() {
+			flusher, ok := w.(http.Flusher)
+			if ok {
+				flusher.Flush()
+			}
+		}()
+
+		// if there's notifications, upgradedHeader to SSE response
+		if !upgradedHeader {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusAccepted)
+			upgradedHeader = true
+		}
+		err := s.writeEvent(ctx, w, streamID, data)
+		if err != nil {
+			s.logger.Error("failed to write SSE event", "sessionID", sessionID, "err", err)
+			return
+		}
+	}
+
+	ctxDone := ctx.Done()
+	if streamID != "" {
+		// keep storing the events of resumable streams after a disconnection
+		ctxDone = nil
+	}
 	go func() {
 		for {
 			select {
 			case nt := <-session.notificationChannel:
-				func() {
-					mu.Lock()
-					defer mu.Unlock()
-					// if the done chan is closed, as the request is terminated, just return
-					select {
-					case <-done:
-						return
-					default:
-					}
-					defer func() {
-						flusher, ok := w.(http.Flusher)
-						if ok {
-							flusher.Flush()
-						}
-					}()
-
-					// if there's notifications, upgradedHeader to SSE response
-					if !upgradedHeader {
-						w.Header().Set("Content-Type", "text/event-stream")
-						w.Header().Set("Connection", "keep-alive")
-						w.Header().Set("Cache-Control", "no-cache")
-						w.WriteHeader(http.StatusAccepted)
-						upgradedHeader = true
-					}
-					err := writeSSEEvent(w, nt)
-					if err != nil {
-						s.logger.Errorf("Failed to write SSE event: %v", err)
-						return
-					}
-				}()
+				writeEvent(nt)
+			case req := <-session.requestChannel:
+				writeEvent(req)
 			case <-done:
 				return
-			case <-ctx.Done():
+			case <-ctxDone:
 				return
 			}
 		}
 	}()
 
 	// Process message through MCPServer
-	response := s.server.HandleMessage(ctx, rawData)
+	var response mcp.JSONRPCMessage
+	if hasRequests && batch != nil && acceptsEventStream(r) {
+		// stream the responses to the requests of the batch as they are ready
+		s.server.handleBatchMessages(ctx, batch, func(_ int, response mcp.JSONRPCMessage) {
+			if response != nil {
+				writeEvent(response)
+			}
+		})
+	} else {
+		response = s.server.HandleMessage(ctx, rawData)
+	}
+	if state.changed.Swap(false) {
+		s.saveSession(ctx, sessionID, state)
+	}
 	if response == nil {
-		// For notifications, just send 202 Accepted with no body
-		w.WriteHeader(http.StatusAccepted)
+		// Notifications, cancelled requests and streamed batches have no response:
+		// send 202 Accepted with no body, or just end the stream if it was already
+		// upgraded to SSE
+		mu.Lock()
+		defer mu.Unlock()
+		defer close(done)
+		session.streamClosed.Store(true)
+		if !upgradedHeader {
+			w.WriteHeader(http.StatusAccepted)
+		}
 		return
 	}
 
@@ -321,7 +523,13 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	defer mu.Unlock()
 	// close the done chan before unlock
 	defer close(done)
+	// no more requests to the client on this stream after the response
+	defer session.streamClosed.Store(true)
 	if ctx.Err() != nil {
+		if upgradedHeader {
+			// the client can still get the response by resuming the stream
+			s.storeEvent(ctx, streamID, response)
+		}
 		return
 	}
 	// If client-server communication already upgraded to SSE stream
@@ -333,8 +541,8 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 			w.WriteHeader(http.StatusAccepted)
 			upgradedHeader = true
 		}
-		if err := writeSSEEvent(w, response); err != nil {
-			s.logger.Errorf("Failed to write final SSE response event: %v", err)
+		if err := s.writeEvent(ctx, w, streamID, response); err != nil {
+			s.logger.Error("failed to write final SSE response event", "sessionID", sessionID, "err", err)
 		}
 	} else {
 		w.Header().Set("Content-Type", "application/json")
@@ -345,7 +553,7 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 		w.WriteHeader(http.StatusOK)
 		err := json.NewEncoder(w).Encode(response)
 		if err != nil {
-			s.logger.Errorf("Failed to write response: %v", err)
+			s.logger.Error("failed to write response", "sessionID", sessionID, "err", err)
 		}
 	}
 }
@@ -355,20 +563,82 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	// https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#listening-for-messages-from-the-server
 
 	sessionID := r.Header.Get(headerKeySessionID)
-	// the specification didn't say we should validate the session id
+	// the specification doesn't require validating the session ID, but servers
+	// with signed session IDs mustn't let clients listen to forged sessions
+	principal := s.sessionIdPrincipal(s.requestContext(r))
+	if sessionID != "" && !s.validateSessionID(w, principal, sessionID) {
+		return
+	}
 
-	if sessionID == "" {
+	stateless := sessionID == ""
+	if stateless {
 		// It's a stateless server,
 		// but the MCP server requires a unique ID for registering, so we use a random one
 		sessionID = uuid.New().String()
 	}
 
-	session := newStreamableHttpSession(sessionID, s.sessionTools)
+	protocolVersion, ok := requestProtocolVersion(r)
+	if !ok {
+		http.Error(w, "Unsupported protocol version", http.StatusBadRequest)
+		return
+	}
+
+	// the events of the listening stream are stored in a stream with the ID of
+	// the session, that of stateless servers being random
+	var streamID, lastEventID string
+	if s.eventStore != nil && !stateless {
+		streamID = sessionID
+		lastEventID = r.Header.Get(headerKeyLastEventID)
+	}
+	var state *streamableSessionState
+	if stateless {
+		// the random session ID can't be used by other requests
+		state = newStatelessSessionState()
+	} else {
+		state = s.sessionState(sessionID)
+		state.principal.Store(principal)
+	}
+	defer state.activity.begin()()
+	if lastEventID != "" {
+		resumedStreamID, err := s.eventStore.StreamIDForEventID(r.Context(), lastEventID)
+		if err != nil || !strings.HasPrefix(resumedStreamID+"/", sessionID+"/") {
+			if err != nil && !errors.Is(err, ErrUnknownEventID) {
+				s.logger.Error("failed to look up the event to resume from", "sessionID", sessionID, "err", err)
+			}
+			http.Error(w, "Unknown Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		if resumedStreamID != streamID {
+			s.resumePostStream(w, r, state, resumedStreamID, lastEventID)
+			return
+		}
+	}
+
+	if stateless {
+		// like the sessions of stateless POST requests
+		state.initialized.Store(true)
+	} else {
+		if err := s.loadSession(r.Context(), sessionID, state); err != nil {
+			s.logger.Error("failed to load session", "sessionID", sessionID, "err", err)
+			http.Error(w, "Failed to load session", http.StatusInternalServerError)
+			return
+		}
+		if !s.checkSessionSubject(w, r, state) {
+			return
+		}
+	}
+	session := s.newSession(sessionID, state)
+	session.protocolVersion = protocolVersion
 	if err := s.server.RegisterSession(r.Context(), session); err != nil {
 		http.Error(w, fmt.Sprintf("Session registration failed: %v", err), http.StatusBadRequest)
 		return
 	}
-	defer s.server.UnregisterSession(r.Context(), sessionID)
+	// the subscriptions of the session outlive its listening stream
+	defer s.server.unregisterSession(r.Context(), sessionID)
+
+	// requests to the client that can't be sent on a POST stream go through this one
+	state.setListeningStream(session.requestChannel)
+	defer state.setListeningStream(nil)
 
 	// Set the client context before handling the message
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -383,6 +653,14 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	}
 	flusher.Flush()
 
+	if lastEventID != "" {
+		if _, err := s.replayEvents(r.Context(), w, lastEventID); err != nil {
+			s.logger.Error("failed to replay SSE events", "sessionID", sessionID, "err", err)
+			return
+		}
+		flusher.Flush()
+	}
+
 	// Start notification handler for this session
 	done := make(chan struct{})
 	defer close(done)
@@ -397,6 +675,12 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 				case <-done:
 					return
 				}
+			case req := <-session.requestChannel:
+				select {
+				case writeChan <- req:
+				case <-done:
+					return
+				}
 			case <-done:
 				return
 			}
@@ -413,7 +697,7 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 				case <-ticker.C:
 					message := mcp.JSONRPCRequest{
 						JSONRPC: "2.0",
-						ID:      mcp.NewRequestId(s.nextRequestID(sessionID)),
+						ID:      mcp.NewRequestId(state.requestID.Add(1)),
 						Request: mcp.Request{
 							Method: "ping",
 						},
@@ -440,13 +724,16 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 			if data == nil {
 				continue
 			}
-			if err := writeSSEEvent(w, data); err != nil {
-				s.logger.Errorf("Failed to write SSE event: %v", err)
+			if err := s.writeEvent(r.Context(), w, streamID, data); err != nil {
+				s.logger.Error("failed to write SSE event", "sessionID", sessionID, "err", err)
 				return
 			}
 			flusher.Flush()
 		case <-r.Context().Done():
 			return
+		case <-state.closed:
+			// the session ended
+			return
 		}
 	}
 }
@@ -454,9 +741,27 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 func (s *StreamableHTTPServer) handleDelete(w http.ResponseWriter, r *http.Request) {
 	// delete request terminate the session
 	sessionID := r.Header.Get(headerKeySessionID)
-	notAllowed, err := s.sessionIdManager.Terminate(sessionID)
+	if s.auth != nil && sessionID != "" {
+		// the state of unknown sessions is loaded in a state of its own, so
+		// that forged session IDs don't get one
+		state, ok := s.sessionStates.Load(sessionID)
+		if !ok {
+			state = newStreamableSessionState()
+		}
+		if err := s.loadSession(r.Context(), sessionID, state.(*streamableSessionState)); err != nil {
+			s.logger.Error("failed to load session", "sessionID", sessionID, "err", err)
+			http.Error(w, "Failed to load session", http.StatusInternalServerError)
+			return
+		}
+		if !s.checkSessionSubject(w, r, state.(*streamableSessionState)) {
+			return
+		}
+	}
+	notAllowed, err := s.terminateSessionID(s.sessionIdPrincipal(s.requestContext(r)), sessionID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Session termination failed: %v", err), http.StatusInternalServerError)
+		// like the IDs of other requests, forged IDs and the IDs of other
+		// principals are invalid
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
 		return
 	}
 	if notAllowed {
@@ -464,21 +769,147 @@ func (s *StreamableHTTPServer) handleDelete(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	s.endSession(r.Context(), sessionID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// endSession frees the state of a terminated session, and closes its
+// listening stream
+func (s *StreamableHTTPServer) endSession(ctx context.Context, sessionID string) {
 	// remove the session relateddata from the sessionToolsStore
-	s.sessionTools.set(sessionID, nil)
+	s.sessionTools.delete(sessionID)
+	if err := s.sessionStore.DeleteSession(ctx, sessionID); err != nil {
+		s.logger.Error("failed to delete session", "sessionID", sessionID, "err", err)
+	}
 
-	// remove current session's requstID information
-	s.sessionRequestIDs.Delete(sessionID)
+	// remove the resource subscriptions of the session
+	s.server.subscriptions.removeSession(sessionID)
 
-	w.WriteHeader(http.StatusOK)
+	// remove current session's requstID information and fail requests still waiting for the client
+	if state, ok := s.sessionStates.LoadAndDelete(sessionID); ok {
+		state := state.(*streamableSessionState)
+		state.pendingRequests.cancelAll(ErrSessionClosed)
+		state.close()
+	}
+}
+
+// reapSessions terminates the sessions that expired at now
+func (s *StreamableHTTPServer) reapSessions(now time.Time) {
+	s.sessionStates.Range(func(key, value any) bool {
+		sessionID := key.(string)
+		if !s.timeouts.expired(value.(*streamableSessionState).activity, now) {
+			return true
+		}
+		s.logger.Info("session expired", "sessionID", sessionID)
+		// session IDs bound to a principal are only terminated with it
+		if _, err := s.terminateSessionID(value.(*streamableSessionState).principal.Load(), sessionID); err != nil {
+			s.logger.Error("failed to terminate expired session", "sessionID", sessionID, "err", err)
+		}
+		s.endSession(context.Background(), sessionID)
+		return true
+	})
 }
 
-func writeSSEEvent(w io.Writer, data any) error {
+// resumePostStream answers a GET request resuming the SSE response of a POST
+// request with the events the client missed. If the request is still being
+// handled, the rest of its events are sent once it's done.
+func (s *StreamableHTTPServer) resumePostStream(
+	w http.ResponseWriter,
+	r *http.Request,
+	state *streamableSessionState,
+	streamID string,
+	lastEventID string,
+) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusAccepted)
+
+	// the stream must be looked up before replaying its events: if the response
+	// is stored after the replay, the stream is still open when looked up and
+	// the response is replayed once it ends
+	ended, open := state.openStreams.Load(streamID)
+	lastEventID, err := s.replayEvents(r.Context(), w, lastEventID)
+	flusher.Flush()
+	if err != nil {
+		s.logger.Error("failed to replay SSE events", "streamID", streamID, "err", err)
+		return
+	}
+	if !open {
+		return
+	}
+	select {
+	case <-ended.(chan struct{}):
+	case <-r.Context().Done():
+		return
+	}
+	if _, err := s.replayEvents(r.Context(), w, lastEventID); err != nil {
+		s.logger.Error("failed to replay SSE events", "streamID", streamID, "err", err)
+	}
+	flusher.Flush()
+}
+
+// replayEvents writes the events stored after lastEventID, and returns the ID of
+// the last event written
+func (s *StreamableHTTPServer) replayEvents(ctx context.Context, w io.Writer, lastEventID string) (string, error) {
+	err := s.eventStore.ReplayEventsAfter(ctx, lastEventID, func(eventID string, message json.RawMessage) error {
+		if err := writeSSEEvent(w, eventID, message); err != nil {
+			return err
+		}
+		lastEventID = eventID
+		return nil
+	})
+	return lastEventID, err
+}
+
+// writeEvent writes a message as an SSE event of the stream, storing it first if
+// the server has an event store, so that the client can resume the stream
+func (s *StreamableHTTPServer) writeEvent(ctx context.Context, w io.Writer, streamID string, data any) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal data: %w", err)
 	}
-	_, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", jsonData)
+	return writeSSEEvent(w, s.storeMessage(ctx, streamID, jsonData), jsonData)
+}
+
+// storeEvent stores a message sent on a stream the client is disconnected from
+func (s *StreamableHTTPServer) storeEvent(ctx context.Context, streamID string, data any) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		s.logger.Error("failed to marshal SSE event", "streamID", streamID, "err", err)
+		return
+	}
+	s.storeMessage(ctx, streamID, jsonData)
+}
+
+// storeMessage stores a message in the event store, and returns the ID of its
+// event, or an empty ID if the stream isn't resumable
+func (s *StreamableHTTPServer) storeMessage(ctx context.Context, streamID string, message json.RawMessage) string {
+	if s.eventStore == nil || streamID == "" {
+		return ""
+	}
+	// the message must be stored even if the client is gone
+	eventID, err := s.eventStore.StoreEvent(context.WithoutCancel(ctx), streamID, message)
+	if err != nil {
+		s.logger.Error("failed to store SSE event", "streamID", streamID, "err", err)
+		return ""
+	}
+	return eventID
+}
+
+// writeSSEEvent writes an SSE event holding a JSON message, with an ID if not empty
+func writeSSEEvent(w io.Writer, eventID string, message []byte) error {
+	var err error
+	if eventID != "" {
+		_, err = fmt.Fprintf(w, "id: %s\nevent: message\ndata: %s\n\n", eventID, message)
+	} else {
+		_, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", message)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to write SSE event: %w", err)
 	}
@@ -497,15 +928,184 @@ func (s *StreamableHTTPServer) writeJSONRPCError(
 	w.WriteHeader(http.StatusBadRequest)
 	err := json.NewEncoder(w).Encode(response)
 	if err != nil {
-		s.logger.Errorf("Failed to write JSONRPCError: %v", err)
+		s.logger.Error("failed to write JSON-RPC error", "err", err)
+	}
+}
+
+// sessionState returns the state shared by all the ephemeral sessions of a session id
+func (s *StreamableHTTPServer) sessionState(sessionID string) *streamableSessionState {
+	if state, ok := s.sessionStates.Load(sessionID); ok {
+		return state.(*streamableSessionState)
+	}
+	actual, _ := s.sessionStates.LoadOrStore(sessionID, newStreamableSessionState())
+	return actual.(*streamableSessionState)
+}
+
+// newSession creates an ephemeral session of a session id, saving the session
+// tools it sets to the session store
+func (s *StreamableHTTPServer) newSession(sessionID string, state *streamableSessionState) *streamableHttpSession {
+	session := newStreamableHttpSession(sessionID, s.sessionTools, state)
+	session.toolsChanged = func() {
+		s.saveSession(context.Background(), sessionID, state)
+	}
+	return session
+}
+
+// loadSession updates the state of a session from the session store, if it was
+// changed by another replica since the state was last loaded or saved. Sessions
+// missing from the store keep their state, and are initialized if their state
+// is unknown, like the sessions initialized before a restart.
+func (s *StreamableHTTPServer) loadSession(ctx context.Context, sessionID string, state *streamableSessionState) error {
+	if sessionID == "" {
+		return nil
+	}
+	state.storeMu.Lock()
+	defer state.storeMu.Unlock()
+	data, err := s.sessionStore.LoadSession(ctx, sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		if state.synced == nil {
+			state.initialized.Store(true)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if state.synced != nil && data.Revision <= state.synced.Revision {
+		return nil
+	}
+	s.applySessionData(sessionID, state, data)
+	state.synced = data
+	return nil
+}
+
+// maxSessionSaveAttempts is the number of times the state of a session is
+// saved before giving up, when other replicas keep changing it
+const maxSessionSaveAttempts = 5
+
+// saveSession saves the state of a session to the session store. If another
+// replica saved the session since it was last synced, the changes of both are
+// merged and saved again.
+func (s *StreamableHTTPServer) saveSession(ctx context.Context, sessionID string, state *streamableSessionState) {
+	if sessionID == "" {
+		return
+	}
+	// the state must be saved even if the client is gone
+	ctx = context.WithoutCancel(ctx)
+	state.storeMu.Lock()
+	defer state.storeMu.Unlock()
+
+	data := s.sessionData(sessionID, state)
+	for range maxSessionSaveAttempts {
+		data.Revision = 1
+		if state.synced != nil {
+			data.Revision = state.synced.Revision + 1
+		}
+		err := s.sessionStore.SaveSession(ctx, sessionID, data)
+		if err == nil {
+			state.synced = data
+			return
+		}
+		if !errors.Is(err, ErrSessionConflict) {
+			s.logger.Error("failed to save session", "sessionID", sessionID, "err", err)
+			return
+		}
+
+		stored, err := s.sessionStore.LoadSession(ctx, sessionID)
+		if errors.Is(err, ErrSessionNotFound) {
+			continue
+		}
+		if err != nil {
+			s.logger.Error("failed to load session", "sessionID", sessionID, "err", err)
+			return
+		}
+		data = mergeSessionData(state.synced, data, stored)
+		s.applySessionData(sessionID, state, data)
+		state.synced = stored
+	}
+	s.logger.Error("failed to save session", "sessionID", sessionID, "err", ErrSessionConflict)
+}
+
+// sessionData returns the data of the state of a session to store
+func (s *StreamableHTTPServer) sessionData(sessionID string, state *streamableSessionState) *SessionData {
+	data := &SessionData{
+		Initialized: state.initialized.Load(),
+		ToolNames:   slices.Sorted(maps.Keys(s.sessionTools.get(sessionID))),
+	}
+	data.ClientInfo, _ = state.clientInfo.Load().(mcp.Implementation)
+	data.ClientCapabilities, _ = state.clientCapabilities.Load().(mcp.ClientCapabilities)
+	data.ProtocolVersion, _ = state.protocolVersion.Load().(string)
+	data.LogLevel, _ = state.loggingLevel.Load().(mcp.LoggingLevel)
+	data.Subject, _ = state.subject.Load().(string)
+	return data
+}
+
+// applySessionData updates the state of a session with stored data
+func (s *StreamableHTTPServer) applySessionData(sessionID string, state *streamableSessionState, data *SessionData) {
+	state.initialized.Store(data.Initialized)
+	state.clientInfo.Store(data.ClientInfo)
+	state.clientCapabilities.Store(data.ClientCapabilities)
+	if data.ProtocolVersion != "" {
+		state.protocolVersion.Store(data.ProtocolVersion)
+	}
+	if data.LogLevel != "" {
+		state.loggingLevel.Store(data.LogLevel)
+	}
+	if s.auth != nil && data.Initialized {
+		state.subject.Store(data.Subject)
+	}
+
+	tools := s.sessionTools.get(sessionID)
+	if len(data.ToolNames) == len(tools) && !slices.ContainsFunc(data.ToolNames, func(name string) bool {
+		_, ok := tools[name]
+		return !ok
+	}) {
+		return
+	}
+	restored := make(map[string]ServerTool, len(data.ToolNames))
+	for _, name := range data.ToolNames {
+		if tool, ok := tools[name]; ok {
+			restored[name] = tool
+		} else if tool, ok := s.toolCatalog[name]; ok {
+			restored[name] = tool
+		} else {
+			s.logger.Warn("session tool missing from the catalog", "sessionID", sessionID, "tool", name)
+		}
 	}
+	s.sessionTools.set(sessionID, restored)
 }
 
-// nextRequestID gets the next incrementing requestID for the current session
-func (s *StreamableHTTPServer) nextRequestID(sessionID string) int64 {
-	actual, _ := s.sessionRequestIDs.LoadOrStore(sessionID, new(atomic.Int64))
-	counter := actual.(*atomic.Int64)
-	return counter.Add(1)
+// mergeSessionData merges the local data of a session with the data stored by
+// another replica: the fields changed locally since base, the data last synced
+// with the store, are kept, and the others are taken from stored
+func mergeSessionData(base, local, stored *SessionData) *SessionData {
+	if base == nil {
+		merged := *local
+		return &merged
+	}
+	merged := *stored
+	if local.Initialized != base.Initialized {
+		merged.Initialized = local.Initialized
+	}
+	if !reflect.DeepEqual(local.ClientInfo, base.ClientInfo) {
+		merged.ClientInfo = local.ClientInfo
+	}
+	if !reflect.DeepEqual(local.ClientCapabilities, base.ClientCapabilities) {
+		merged.ClientCapabilities = local.ClientCapabilities
+	}
+	if local.ProtocolVersion != base.ProtocolVersion {
+		merged.ProtocolVersion = local.ProtocolVersion
+	}
+	if local.LogLevel != base.LogLevel {
+		merged.LogLevel = local.LogLevel
+	}
+	if local.Subject != base.Subject {
+		merged.Subject = local.Subject
+	}
+	if !slices.Equal(local.ToolNames, base.ToolNames) {
+		merged.ToolNames = local.ToolNames
+	}
+	return &merged
 }
 
 // --- session ---
@@ -533,21 +1133,106 @@ func (s *sessionToolsStore) set(sessionID string, tools map[string]ServerTool) {
 	s.tools[sessionID] = tools
 }
 
+func (s *sessionToolsStore) delete(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tools, sessionID)
+}
+
+// streamableSessionState is the state of a session id that outlives the ephemeral
+// sessions created for each POST request.
+type streamableSessionState struct {
+	initialized        atomic.Bool
+	changed            atomic.Bool // set when the state to save to the session store changes
+	requestID          atomic.Int64
+	pendingRequests    pendingRequests // server -> client requests waiting for a response
+	clientInfo         atomic.Value
+	clientCapabilities atomic.Value
+	protocolVersion    atomic.Value // negotiated during initialization
+	loggingLevel       atomic.Value // set by the client with logging/setLevel
+	roots              rootsCache
+	prompts            sessionItems[ServerPrompt]
+	resources          sessionItems[ServerResource]
+	resourceTemplates  sessionResourceTemplates
+
+	// requests channel of the GET (listening) stream, if any
+	listeningStream atomic.Pointer[chan mcp.JSONRPCRequest]
+	// resumable SSE responses of POST requests still being handled:
+	// stream ID -> channel closed once the response is stored
+	openStreams sync.Map
+
+	// principal the session ID is bound to, if the SessionIdManager binds them
+	principal atomic.Pointer[Principal]
+	// subject of the access token that initialized the session, if the server
+	// is a resource server
+	subject atomic.Value
+
+	// storeMu serializes the loads and saves of the state. synced is the data
+	// last loaded from or saved to the session store, nil if none was.
+	storeMu sync.Mutex
+	synced  *SessionData
+
+	activity  *sessionActivity
+	closed    chan struct{} // closed when the session ends
+	closeOnce sync.Once
+
+	// set for the requests of stateless servers, whose state lives as long as
+	// the request: the responses of the client can't be routed back to them
+	stateless bool
+}
+
+func newStreamableSessionState() *streamableSessionState {
+	return &streamableSessionState{
+		activity: newSessionActivity(time.Now()),
+		closed:   make(chan struct{}),
+	}
+}
+
+// newStatelessSessionState creates the state of a request of a stateless server
+func newStatelessSessionState() *streamableSessionState {
+	state := newStreamableSessionState()
+	state.stateless = true
+	return state
+}
+
+func (s *streamableSessionState) close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+}
+
+func (s *streamableSessionState) setListeningStream(requests chan mcp.JSONRPCRequest) {
+	if requests == nil {
+		s.listeningStream.Store(nil)
+		return
+	}
+	s.listeningStream.Store(&requests)
+}
+
 // streamableHttpSession is a session for streamable-http transport
 // When in POST handlers(request/notification), it's ephemeral, and only exists in the life of the request handler.
 // When in GET handlers(listening), it's a real session, and will be registered in the MCP server.
 type streamableHttpSession struct {
 	sessionID           string
 	notificationChannel chan mcp.JSONRPCNotification // server -> client notifications
+	requestChannel      chan mcp.JSONRPCRequest      // server -> client requests
 	tools               *sessionToolsStore
+	state               *streamableSessionState
+	protocolVersion     string // from the protocol version header of the request
 	upgradeToSSE        atomic.Bool
+	streamClosed        atomic.Bool // the POST stream can't carry requests to the client anymore
+	toolsChanged        func()      // called when the session tools are set
 }
 
-func newStreamableHttpSession(sessionID string, toolStore *sessionToolsStore) *streamableHttpSession {
+func newStreamableHttpSession(
+	sessionID string,
+	toolStore *sessionToolsStore,
+	state *streamableSessionState,
+) *streamableHttpSession {
 	return &streamableHttpSession{
 		sessionID:           sessionID,
 		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
+		requestChannel:      make(chan mcp.JSONRPCRequest, 16),
 		tools:               toolStore,
+		state:               state,
 	}
 }
 
@@ -560,13 +1245,14 @@ func (s *streamableHttpSession) NotificationChannel() chan<- mcp.JSONRPCNotifica
 }
 
 func (s *streamableHttpSession) Initialize() {
-	// do nothing
-	// the session is ephemeral, no real initialized action needed
+	s.state.initialized.Store(true)
+	s.state.changed.Store(true)
 }
 
+// Initialized reports whether the session id was initialized. Sessions of
+// stateless servers have no id, and are always initialized.
 func (s *streamableHttpSession) Initialized() bool {
-	// the session is ephemeral, no real initialized action needed
-	return true
+	return s.sessionID == "" || s.state.initialized.Load()
 }
 
 var _ ClientSession = (*streamableHttpSession)(nil)
@@ -577,18 +1263,252 @@ func (s *streamableHttpSession) GetSessionTools() map[string]ServerTool {
 
 func (s *streamableHttpSession) SetSessionTools(tools map[string]ServerTool) {
 	s.tools.set(s.sessionID, tools)
+	if s.toolsChanged != nil {
+		s.toolsChanged()
+	}
 }
 
 var _ SessionWithTools = (*streamableHttpSession)(nil)
 
+func (s *streamableHttpSession) GetSessionPrompts() map[string]ServerPrompt {
+	return s.state.prompts.get()
+}
+
+func (s *streamableHttpSession) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	s.state.prompts.set(prompts)
+}
+
+func (s *streamableHttpSession) GetSessionResources() map[string]ServerResource {
+	return s.state.resources.get()
+}
+
+func (s *streamableHttpSession) SetSessionResources(resources map[string]ServerResource) {
+	s.state.resources.set(resources)
+}
+
+func (s *streamableHttpSession) GetSessionResourceTemplates() map[string]ServerResourceTemplate {
+	return s.state.resourceTemplates.get()
+}
+
+func (s *streamableHttpSession) SetSessionResourceTemplates(templates map[string]ServerResourceTemplate) {
+	s.state.resourceTemplates.set(templates)
+}
+
+func (s *streamableHttpSession) resourceTemplateIndex() *templateIndex {
+	return s.state.resourceTemplates.templateIndex()
+}
+
+var (
+	_ SessionWithPrompts   = (*streamableHttpSession)(nil)
+	_ SessionWithResources = (*streamableHttpSession)(nil)
+)
+
 func (s *streamableHttpSession) UpgradeToSSEWhenReceiveNotification() {
 	s.upgradeToSSE.Store(true)
 }
 
 var _ SessionWithStreamableHTTPConfig = (*streamableHttpSession)(nil)
 
+func (s *streamableHttpSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if value := s.state.clientCapabilities.Load(); value != nil {
+		if capabilities, ok := value.(mcp.ClientCapabilities); ok {
+			return capabilities
+		}
+	}
+	return mcp.ClientCapabilities{}
+}
+
+func (s *streamableHttpSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.state.clientCapabilities.Store(capabilities)
+	s.state.changed.Store(true)
+}
+
+var _ SessionWithClientCapabilities = (*streamableHttpSession)(nil)
+
+func (s *streamableHttpSession) GetClientInfo() mcp.Implementation {
+	if clientInfo, ok := s.state.clientInfo.Load().(mcp.Implementation); ok {
+		return clientInfo
+	}
+	return mcp.Implementation{}
+}
+
+func (s *streamableHttpSession) SetClientInfo(clientInfo mcp.Implementation) {
+	s.state.clientInfo.Store(clientInfo)
+	s.state.changed.Store(true)
+}
+
+var _ SessionWithClientInfo = (*streamableHttpSession)(nil)
+
+// GetProtocolVersion returns the protocol version of the current request: the
+// version header if the client sent one, the version negotiated during
+// initialization otherwise.
+func (s *streamableHttpSession) GetProtocolVersion() string {
+	if s.protocolVersion != "" {
+		return s.protocolVersion
+	}
+	if version, ok := s.state.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return defaultStreamableProtocolVersion
+}
+
+func (s *streamableHttpSession) SetProtocolVersion(version string) {
+	s.state.protocolVersion.Store(version)
+	s.state.changed.Store(true)
+}
+
+var _ SessionWithProtocolVersion = (*streamableHttpSession)(nil)
+
+func (s *streamableHttpSession) SetLogLevel(level mcp.LoggingLevel) {
+	s.state.loggingLevel.Store(level)
+	s.state.changed.Store(true)
+}
+
+func (s *streamableHttpSession) GetLogLevel() mcp.LoggingLevel {
+	if level, ok := s.state.loggingLevel.Load().(mcp.LoggingLevel); ok {
+		return level
+	}
+	return mcp.LoggingLevelError
+}
+
+var _ SessionWithLogging = (*streamableHttpSession)(nil)
+
+// SendRequest sends the request over the SSE stream of the current POST request,
+// upgrading its response to SSE if needed. Once that stream is gone (or for
+// notifications), the request is sent over the listening GET stream instead.
+// The client answers with a new POST carrying the same session id, which is
+// routed back here through the shared state.
+func (s *streamableHttpSession) SendRequest(
+	ctx context.Context,
+	method mcp.MCPMethod,
+	params any,
+) (json.RawMessage, error) {
+	if s.state.stateless {
+		return nil, ErrSessionDoesNotSupportRequests
+	}
+	request := newClientRequest(s.state.requestID.Add(1), method, params)
+	return s.state.pendingRequests.send(ctx, request, func(request mcp.JSONRPCRequest) error {
+		requests := s.requestChannel
+		if s.streamClosed.Load() {
+			listeningStream := s.state.listeningStream.Load()
+			if listeningStream == nil {
+				return ErrNoStreamForRequest
+			}
+			requests = *listeningStream
+		} else {
+			s.upgradeToSSE.Store(true)
+		}
+		select {
+		case requests <- request:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+func (s *streamableHttpSession) HandleResponse(id mcp.RequestId, result json.RawMessage, err error) bool {
+	return s.state.pendingRequests.deliver(id, result, err)
+}
+
+var _ SessionWithRequests = (*streamableHttpSession)(nil)
+
+func (s *streamableHttpSession) GetRoots() ([]mcp.Root, bool) {
+	return s.state.roots.get()
+}
+
+func (s *streamableHttpSession) SetRoots(roots []mcp.Root) {
+	s.state.roots.set(roots)
+}
+
+func (s *streamableHttpSession) rootsCache() *rootsCache {
+	return &s.state.roots
+}
+
+var _ SessionWithRoots = (*streamableHttpSession)(nil)
+
 // --- session id manager ---
 
+// requestContext returns the context of an HTTP request, as seen by the
+// handlers of its messages once their session is added to it
+func (s *StreamableHTTPServer) requestContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if s.contextFunc != nil {
+		ctx = s.contextFunc(ctx, r)
+	}
+	return ctx
+}
+
+// sessionIdPrincipal returns the principal the session IDs of a request are
+// bound to, or nil if the manager doesn't bind them to principals
+func (s *StreamableHTTPServer) sessionIdPrincipal(ctx context.Context) *Principal {
+	if _, ok := s.sessionIdManager.(SessionIdManagerWithPrincipal); !ok {
+		return nil
+	}
+	return s.server.principal(ctx)
+}
+
+// generateSessionID generates the session ID of an initialize request, bound
+// to its principal if the manager supports it
+func (s *StreamableHTTPServer) generateSessionID(principal *Principal) string {
+	if manager, ok := s.sessionIdManager.(SessionIdManagerWithPrincipal); ok {
+		return manager.GenerateFor(principal)
+	}
+	return s.sessionIdManager.Generate()
+}
+
+// validateSessionID validates the session ID of a request, answering it with
+// an error and returning false if it's invalid or terminated
+func (s *StreamableHTTPServer) validateSessionID(w http.ResponseWriter, principal *Principal, sessionID string) bool {
+	var isTerminated bool
+	var err error
+	if manager, ok := s.sessionIdManager.(SessionIdManagerWithPrincipal); ok {
+		isTerminated, err = manager.ValidateFor(sessionID, principal)
+	} else {
+		isTerminated, err = s.sessionIdManager.Validate(sessionID)
+	}
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return false
+	}
+	if isTerminated {
+		http.Error(w, "Session terminated", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// checkSessionSubject checks that the access token of a request was issued to
+// the subject that initialized the session, or binds the session to it if the
+// session has no subject yet, like new sessions and those whose state was lost.
+// Otherwise it responds with 403 Forbidden.
+func (s *StreamableHTTPServer) checkSessionSubject(w http.ResponseWriter, r *http.Request, state *streamableSessionState) bool {
+	if s.auth == nil || state.stateless {
+		return true
+	}
+	var subject string
+	if claims, ok := TokenClaimsFromContext(r.Context()); ok {
+		subject = claims.Subject
+	}
+	if state.subject.CompareAndSwap(nil, subject) {
+		state.changed.Store(true)
+		return true
+	}
+	if state.subject.Load() != subject {
+		http.Error(w, "Session belongs to another subject", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// terminateSessionID terminates a session ID bound to the principal
+func (s *StreamableHTTPServer) terminateSessionID(principal *Principal, sessionID string) (isNotAllowed bool, err error) {
+	if manager, ok := s.sessionIdManager.(SessionIdManagerWithPrincipal); ok {
+		return manager.TerminateFor(sessionID, principal)
+	}
+	return s.sessionIdManager.Terminate(sessionID)
+}
+
 type SessionIdManager interface {
 	Generate() string
 	// Validate checks if a session ID is valid and not terminated.
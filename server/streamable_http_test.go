@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -447,6 +448,46 @@ func TestStreamableHTTP_POST_SendAndReceive_stateless(t *testing.T) {
 	})
 }
 
+func TestStreamableHTTP_StatelessRequestsShareNoState(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	mcpServer.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := ClientSessionFromContext(ctx).(SessionWithClientInfo)
+		return mcp.NewToolResultText("client:" + session.GetClientInfo().Name), nil
+	})
+	httpServer := NewStreamableHTTPServer(mcpServer, WithStateLess(true))
+	server := httptest.NewServer(httpServer)
+	defer server.Close()
+
+	// a client initializing doesn't change what the server knows of the others
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	resp.Body.Close()
+	resp, err = postJSON(server.URL, map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": "whoami"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	defer resp.Body.Close()
+	var response jsonRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result := fmt.Sprint(response.Result); !strings.Contains(result, "client:") || strings.Contains(result, "test-client") {
+		t.Errorf("Expected no client info, got %v", result)
+	}
+
+	httpServer.sessionStates.Range(func(key, value any) bool {
+		t.Errorf("Expected no session state, got one for %q", key)
+		return true
+	})
+}
+
 func TestStreamableHTTP_GET(t *testing.T) {
 	mcpServer := NewMCPServer("test-mcp-server", "1.0")
 	addSSETool(mcpServer)
@@ -720,9 +761,592 @@ func TestStreamableHTTPServer_WithOptions(t *testing.T) {
 	})
 }
 
+func TestStreamableHTTP_ProtocolVersionHeader(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	mcpServer.AddTool(mcp.NewTool("weather", mcp.WithTitle("Weather")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("sunny"), nil
+		})
+	server := NewTestStreamableHTTPServer(mcpServer)
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "initialize",
+		"params":  map[string]any{"protocolVersion": "2025-06-18"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+
+	listTools := func(protocolVersion string) (int, string) {
+		body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 2, "method": "tools/list"})
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		if protocolVersion != "" {
+			req.Header.Set(headerKeyProtocolVersion, protocolVersion)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, ""
+		}
+		var response struct {
+			Result mcp.ListToolsResult `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp.StatusCode, response.Result.Tools[0].Title
+	}
+
+	tests := []struct {
+		name            string
+		protocolVersion string
+		expectedStatus  int
+		expectedTitle   string
+	}{
+		{name: "current version", protocolVersion: "2025-06-18", expectedStatus: http.StatusOK, expectedTitle: "Weather"},
+		{name: "older version", protocolVersion: "2025-03-26", expectedStatus: http.StatusOK, expectedTitle: ""},
+		{name: "no header uses the negotiated version", expectedStatus: http.StatusOK, expectedTitle: "Weather"},
+		{name: "unsupported version", protocolVersion: "1999-01-01", expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, title := listTools(tt.protocolVersion)
+			if status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, status)
+			}
+			if title != tt.expectedTitle {
+				t.Errorf("Expected title %q, got %q", tt.expectedTitle, title)
+			}
+		})
+	}
+}
+
 func postJSON(url string, bodyObject any) (*http.Response, error) {
 	jsonBody, _ := json.Marshal(bodyObject)
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	return http.DefaultClient.Do(req)
 }
+
+// sseEvent is an event read from an SSE stream
+type sseEvent struct {
+	id   string
+	data string
+}
+
+// readStreamEvent reads the next event of an SSE stream
+func readStreamEvent(reader *bufio.Reader) (sseEvent, error) {
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return event, err
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return event, nil
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStreamableHTTP_Resumability(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	addSSETool(mcpServer)
+	server := NewTestStreamableHTTPServer(mcpServer, WithEventStore(NewInMemoryEventStore(0)))
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+
+	resume := func(sessionID, lastEventID string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		req.Header.Set(headerKeySessionID, sessionID)
+		req.Header.Set(headerKeyLastEventID, lastEventID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to resume stream: %v", err)
+		}
+		return resp
+	}
+
+	var requestEventID string
+	t.Run("resume the response of a request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		body, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      2,
+			"method":  "tools/call",
+			"params":  map[string]any{"name": "sseTool"},
+		})
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to call tool: %v", err)
+		}
+		event, err := readStreamEvent(bufio.NewReader(resp.Body))
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		if event.id == "" || !strings.Contains(event.data, `{"value":0}`) {
+			t.Fatalf("Expected the first notification with an event ID, got %+v", event)
+		}
+		requestEventID = event.id
+		// drop the connection while the tool is running
+		cancel()
+		resp.Body.Close()
+
+		resp = resume(sessionID, event.id)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d", resp.StatusCode)
+		}
+		replayed, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read replayed events: %v", err)
+		}
+		if strings.Contains(string(replayed), `{"value":0}`) {
+			t.Errorf("Expected the events after the last one only, got %s", replayed)
+		}
+		for i := 1; i < 10; i++ {
+			if !strings.Contains(string(replayed), fmt.Sprintf(`{"value":%d}`, i)) {
+				t.Errorf("Expected notification %d to be replayed, got %s", i, replayed)
+			}
+		}
+		if !strings.Contains(string(replayed), `"id":2`) || !strings.Contains(string(replayed), "done") {
+			t.Errorf("Expected the response to be replayed, got %s", replayed)
+		}
+	})
+
+	t.Run("resume the listening stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			mcpServer.SendNotificationToAllClients("test/notification", map[string]any{"value": "first"})
+			mcpServer.SendNotificationToAllClients("test/notification", map[string]any{"value": "second"})
+		}()
+		reader := bufio.NewReader(resp.Body)
+		first, err := readStreamEvent(reader)
+		if err != nil || !strings.Contains(first.data, "first") {
+			t.Fatalf("Expected the first notification, got %+v (%v)", first, err)
+		}
+		// the second notification is lost with the connection
+		if _, err := readStreamEvent(reader); err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		cancel()
+		resp.Body.Close()
+
+		resp = resume(sessionID, first.id)
+		defer resp.Body.Close()
+		replayed, err := readStreamEvent(bufio.NewReader(resp.Body))
+		if err != nil {
+			t.Fatalf("Failed to read replayed event: %v", err)
+		}
+		if !strings.Contains(replayed.data, "second") || replayed.id == "" {
+			t.Errorf("Expected the second notification to be replayed, got %+v", replayed)
+		}
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		resp := resume(sessionID, "unknown")
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("event of another session", func(t *testing.T) {
+		resp := resume("other-session", requestEventID)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// racingEventStore calls onReplay once, after the first replay of events
+type racingEventStore struct {
+	EventStore
+	once     sync.Once
+	onReplay func()
+}
+
+func (e *racingEventStore) ReplayEventsAfter(ctx context.Context, lastEventID string, send func(eventID string, message json.RawMessage) error) error {
+	err := e.EventStore.ReplayEventsAfter(ctx, lastEventID, send)
+	e.once.Do(e.onReplay)
+	return err
+}
+
+func TestStreamableHTTP_ResumeStreamEndingDuringReplay(t *testing.T) {
+	release := make(chan struct{})
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	mcpServer.AddTool(mcp.NewTool("slowTool"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		_ = ServerFromContext(ctx).SendNotificationToClient(ctx, "test/notification", map[string]any{"value": 0})
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+	eventStore := &racingEventStore{EventStore: NewInMemoryEventStore(0)}
+	server := NewStreamableHTTPServer(mcpServer, WithEventStore(eventStore))
+	testServer := httptest.NewServer(server)
+	defer testServer.Close()
+
+	resp, err := postJSON(testServer.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+
+	// the response is stored right after the events are replayed, before the
+	// resumed stream waits for it
+	eventStore.onReplay = func() {
+		close(release)
+		value, _ := server.sessionStates.Load(sessionID)
+		state := value.(*streamableSessionState)
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			open := false
+			state.openStreams.Range(func(key, value any) bool {
+				open = true
+				return false
+			})
+			if !open {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Error("Expected the stream of the request to end")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	body := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slowTool"}}`
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, testServer.URL, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerKeySessionID, sessionID)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	event, err := readStreamEvent(bufio.NewReader(resp.Body))
+	if err != nil {
+		t.Fatalf("Failed to read event: %v", err)
+	}
+	cancel()
+	resp.Body.Close()
+
+	req, _ = http.NewRequest(http.MethodGet, testServer.URL, nil)
+	req.Header.Set(headerKeySessionID, sessionID)
+	req.Header.Set(headerKeyLastEventID, event.id)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to resume stream: %v", err)
+	}
+	defer resp.Body.Close()
+	replayed, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read replayed events: %v", err)
+	}
+	if !strings.Contains(string(replayed), `"id":2`) || !strings.Contains(string(replayed), "done") {
+		t.Errorf("Expected the response to be replayed, got %q", replayed)
+	}
+}
+
+func TestStreamableHTTP_SessionStore(t *testing.T) {
+	store, err := NewFileSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create session store: %v", err)
+	}
+	extraTool := ServerTool{
+		Tool: mcp.NewTool("extra"),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("extra"), nil
+		},
+	}
+	// replicas of a server sharing the session store
+	newReplica := func() *httptest.Server {
+		mcpServer := NewMCPServer("test-mcp-server", "1.0", WithLogging())
+		mcpServer.AddTool(mcp.NewTool("enable_extra"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			session := ClientSessionFromContext(ctx).(SessionWithTools)
+			session.SetSessionTools(map[string]ServerTool{"extra": extraTool})
+			return mcp.NewToolResultText("enabled"), nil
+		})
+		mcpServer.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			session := ClientSessionFromContext(ctx).(SessionWithClientInfo)
+			return mcp.NewToolResultText(session.GetClientInfo().Name), nil
+		})
+		return NewTestStreamableHTTPServer(mcpServer, WithSessionStore(store), WithSessionToolCatalog(extraTool))
+	}
+	replica1, replica2 := newReplica(), newReplica()
+	defer replica1.Close()
+	defer replica2.Close()
+
+	resp, err := postJSON(replica1.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+
+	send := func(url string, method string, params map[string]any) jsonRPCResponse {
+		body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send %s: %v", method, err)
+		}
+		defer resp.Body.Close()
+		var response jsonRPCResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode the response to %s: %v", method, err)
+		}
+		return response
+	}
+
+	// the session initialized by the first replica is known to the second one
+	if response := send(replica2.URL, "logging/setLevel", map[string]any{"level": "debug"}); response.Error != nil {
+		t.Fatalf("Expected the level to be set, got %+v", response.Error)
+	}
+	if response := send(replica2.URL, "tools/call", map[string]any{"name": "whoami"}); !strings.Contains(fmt.Sprint(response.Result), "test-client") {
+		t.Errorf("Expected the client info to be restored, got %v", response.Result)
+	}
+	data, err := store.LoadSession(context.Background(), sessionID)
+	if err != nil {
+		t.Fatalf("Failed to load session: %v", err)
+	}
+	if !data.Initialized || data.LogLevel != mcp.LoggingLevelDebug || data.ProtocolVersion != "2025-03-26" {
+		t.Errorf("Unexpected stored session: %+v", data)
+	}
+
+	// the session tools added by a replica are restored from the catalog by the other one
+	send(replica1.URL, "tools/call", map[string]any{"name": "enable_extra"})
+	if response := send(replica2.URL, "tools/call", map[string]any{"name": "extra"}); !strings.Contains(fmt.Sprint(response.Result), "extra") {
+		t.Errorf("Expected the session tool to be called, got %+v", response)
+	}
+
+	// terminating the session removes it from the store
+	req, _ := http.NewRequest(http.MethodDelete, replica2.URL, nil)
+	req.Header.Set(headerKeySessionID, sessionID)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to terminate session: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := store.LoadSession(context.Background(), sessionID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected the session to be deleted, got %v", err)
+	}
+}
+
+func TestStreamableHTTP_SessionStoreRevisions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stale sessions don't overwrite local changes", func(t *testing.T) {
+		store := NewMemorySessionStore()
+		replica1 := NewStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0"), WithSessionStore(store))
+		replica2 := NewStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0"), WithSessionStore(store))
+		state := newStreamableSessionState()
+		state.initialized.Store(true)
+		state.loggingLevel.Store(mcp.LoggingLevelInfo)
+		replica1.saveSession(ctx, "session", state)
+
+		// a change not saved yet is kept by the reloads of the same revision
+		state.loggingLevel.Store(mcp.LoggingLevelDebug)
+		if err := replica1.loadSession(ctx, "session", state); err != nil {
+			t.Fatalf("Failed to load session: %v", err)
+		}
+		if level := state.loggingLevel.Load(); level != mcp.LoggingLevelDebug {
+			t.Errorf("Expected the local level to be kept, got %v", level)
+		}
+
+		// changes saved by another replica are loaded
+		other := newStreamableSessionState()
+		if err := replica2.loadSession(ctx, "session", other); err != nil {
+			t.Fatalf("Failed to load session: %v", err)
+		}
+		other.loggingLevel.Store(mcp.LoggingLevelError)
+		replica2.saveSession(ctx, "session", other)
+		if err := replica1.loadSession(ctx, "session", state); err != nil {
+			t.Fatalf("Failed to load session: %v", err)
+		}
+		if level := state.loggingLevel.Load(); level != mcp.LoggingLevelError {
+			t.Errorf("Expected the level saved by the other replica, got %v", level)
+		}
+	})
+
+	t.Run("concurrent saves are merged", func(t *testing.T) {
+		store, err := NewFileSessionStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create session store: %v", err)
+		}
+		replica1 := NewStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0"), WithSessionStore(store))
+		replica2 := NewStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0"), WithSessionStore(store))
+		state1, state2 := newStreamableSessionState(), newStreamableSessionState()
+		state1.initialized.Store(true)
+		replica1.saveSession(ctx, "session", state1)
+		if err := replica2.loadSession(ctx, "session", state2); err != nil {
+			t.Fatalf("Failed to load session: %v", err)
+		}
+
+		// both replicas change the session from the same revision
+		state1.loggingLevel.Store(mcp.LoggingLevelDebug)
+		state2.clientInfo.Store(mcp.Implementation{Name: "client", Version: "2.0"})
+		replica1.saveSession(ctx, "session", state1)
+		replica2.saveSession(ctx, "session", state2)
+
+		data, err := store.LoadSession(ctx, "session")
+		if err != nil {
+			t.Fatalf("Failed to load session: %v", err)
+		}
+		if data.LogLevel != mcp.LoggingLevelDebug || data.ClientInfo.Version != "2.0" || data.Revision != 3 {
+			t.Errorf("Expected the changes of both replicas to be saved, got %+v", data)
+		}
+		if level := state2.loggingLevel.Load(); level != mcp.LoggingLevelDebug {
+			t.Errorf("Expected the replica to get the level of the other one, got %v", level)
+		}
+		if err := replica1.loadSession(ctx, "session", state1); err != nil {
+			t.Fatalf("Failed to load session: %v", err)
+		}
+		if info := state1.clientInfo.Load(); info.(mcp.Implementation).Version != "2.0" {
+			t.Errorf("Expected the replica to reload the client info of the other one, got %v", info)
+		}
+	})
+
+	t.Run("sessions missing from the store are initialized", func(t *testing.T) {
+		mcpServer := NewMCPServer("test-mcp-server", "1.0", WithLogging())
+		server := NewStreamableHTTPServer(mcpServer)
+		testServer := httptest.NewServer(server)
+		defer testServer.Close()
+
+		resp, err := postJSON(testServer.URL, initRequest)
+		if err != nil {
+			t.Fatalf("Failed to initialize: %v", err)
+		}
+		resp.Body.Close()
+		sessionID := resp.Header.Get(headerKeySessionID)
+
+		// like after a restart of a server without a shared session store
+		server.sessionStates.Delete(sessionID)
+		if err := server.sessionStore.DeleteSession(ctx, sessionID); err != nil {
+			t.Fatalf("Failed to delete session: %v", err)
+		}
+
+		body := `{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"debug"}}`
+		req, _ := http.NewRequest(http.MethodPost, testServer.URL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to set level: %v", err)
+		}
+		defer resp.Body.Close()
+		var response jsonRPCResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Error != nil {
+			t.Errorf("Expected the level to be set, got %+v", response.Error)
+		}
+	})
+}
+
+func TestStreamableHTTP_SubscriptionsOutliveListeningStreams(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0", WithResourceCapabilities(true, false))
+	server := NewTestStreamableHTTPServer(mcpServer)
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+
+	send := func(method string, body any) {
+		var reader io.Reader
+		if body != nil {
+			content, _ := json.Marshal(body)
+			reader = bytes.NewReader(content)
+		}
+		req, _ := http.NewRequest(method, server.URL, reader)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send %s request: %v", method, err)
+		}
+		resp.Body.Close()
+	}
+	send(http.MethodPost, map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "resources/subscribe",
+		"params":  map[string]any{"uri": "file:///a"},
+	})
+	subscribed := func() bool {
+		mcpServer.subscriptions.mu.RLock()
+		defer mcpServer.subscriptions.mu.RUnlock()
+		_, ok := mcpServer.subscriptions.sessions[sessionID]
+		return ok
+	}
+	if !subscribed() {
+		t.Fatal("Expected the session to be subscribed")
+	}
+
+	// the listening stream disconnecting doesn't end the session
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	req.Header.Set(headerKeySessionID, sessionID)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open the listening stream: %v", err)
+	}
+	cancel()
+	resp.Body.Close()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := mcpServer.sessions.Load(sessionID); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the listening stream to be closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !subscribed() {
+		t.Error("Expected the subscriptions to outlive the listening stream")
+	}
+
+	// terminating the session removes them
+	send(http.MethodDelete, nil)
+	if subscribed() {
+		t.Error("Expected the subscriptions to be removed with the session")
+	}
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// resourceSubscriptions keeps track of the resources each session subscribed to.
+// The zero value is ready to use.
+type resourceSubscriptions struct {
+	mu       sync.RWMutex
+	sessions map[string]map[string]struct{} // sessionID -> subscribed URIs
+}
+
+func (r *resourceSubscriptions) subscribe(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sessions == nil {
+		r.sessions = make(map[string]map[string]struct{})
+	}
+	if r.sessions[sessionID] == nil {
+		r.sessions[sessionID] = make(map[string]struct{})
+	}
+	r.sessions[sessionID][uri] = struct{}{}
+}
+
+func (r *resourceSubscriptions) unsubscribe(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions[sessionID], uri)
+	if len(r.sessions[sessionID]) == 0 {
+		delete(r.sessions, sessionID)
+	}
+}
+
+func (r *resourceSubscriptions) removeSession(sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, sessionID)
+}
+
+// subscribers returns the sessions with a subscription for which match returns true
+func (r *resourceSubscriptions) subscribers(match func(subscribedURI string) bool) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var sessionIDs []string
+	for sessionID, uris := range r.sessions {
+		for uri := range uris {
+			if match(uri) {
+				sessionIDs = append(sessionIDs, sessionID)
+				break
+			}
+		}
+	}
+	return sessionIDs
+}
+
+// NotifyResourceUpdated sends a notifications/resources/updated notification to
+// every session subscribed to the resource with the given URI. Subscriptions to a
+// resource template match all the URIs expanded from the template.
+// Subscribed sessions without a connection to send the notification on, like
+// streamable HTTP sessions without a listening stream, miss it.
+// It returns the errors of the sessions the notification could not be sent to.
+func (s *MCPServer) NotifyResourceUpdated(uri string) error {
+	s.resourcesMu.RLock()
+	templates := make(map[string]*mcp.URITemplate, len(s.resourceTemplates))
+	for uriTemplate, entry := range s.resourceTemplates {
+		templates[uriTemplate] = entry.template.URITemplate
+	}
+	s.resourcesMu.RUnlock()
+
+	sessionIDs := s.subscriptions.subscribers(func(subscribedURI string) bool {
+		if subscribedURI == uri {
+			return true
+		}
+		template, ok := templates[subscribedURI]
+		return ok && matchesTemplate(uri, template)
+	})
+
+	var errs []error
+	for _, sessionID := range sessionIDs {
+		err := s.SendNotificationToSpecificClient(
+			sessionID,
+			mcp.MethodNotificationResourceUpdated,
+			map[string]any{"uri": uri},
+		)
+		if err != nil && !errors.Is(err, ErrSessionNotFound) {
+			errs = append(errs, fmt.Errorf("session %s: %w", sessionID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// subscriptionSession returns the session of a subscription request, or the error to respond with
+func (s *MCPServer) subscriptionSession(ctx context.Context, id any, uri string) (ClientSession, *requestError) {
+	s.capabilitiesMu.RLock()
+	supported := s.capabilities.resources != nil && s.capabilities.resources.subscribe
+	s.capabilitiesMu.RUnlock()
+	if !supported {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.METHOD_NOT_FOUND,
+			err:  fmt.Errorf("resource subscriptions %w", ErrUnsupported),
+		}
+	}
+
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  ErrNoActiveSession,
+		}
+	}
+
+	if uri == "" {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("missing resource uri"),
+		}
+	}
+	return session, nil
+}
+
+func (s *MCPServer) handleSubscribe(
+	ctx context.Context,
+	id any,
+	request mcp.SubscribeRequest,
+) (*mcp.EmptyResult, *requestError) {
+	session, err := s.subscriptionSession(ctx, id, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	// updates are only sent to the sessions that may read the resource
+	if err := s.authorize(ctx, "resource", request.Params.URI, s.resourceScopes(ctx, request.Params.URI)); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.FORBIDDEN,
+			err:  err,
+		}
+	}
+	s.subscriptions.subscribe(session.SessionID(), request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
+
+func (s *MCPServer) handleUnsubscribe(
+	ctx context.Context,
+	id any,
+	request mcp.UnsubscribeRequest,
+) (*mcp.EmptyResult, *requestError) {
+	session, err := s.subscriptionSession(ctx, id, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	s.subscriptions.unsubscribe(session.SessionID(), request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func subscriptionMessage(method mcp.MCPMethod, uri string) []byte {
+	return []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": %q, "params": {"uri": %q}}`, method, uri))
+}
+
+func newSubscriptionTestSession(t *testing.T, server *MCPServer, sessionID string) (*sessionTestClient, context.Context) {
+	t.Helper()
+	session := &sessionTestClient{
+		sessionID:           sessionID,
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	return session, server.WithContext(context.Background(), session)
+}
+
+func receivedUpdates(session *sessionTestClient) []string {
+	var uris []string
+	for {
+		select {
+		case notification := <-session.notificationChannel:
+			if notification.Method == mcp.MethodNotificationResourceUpdated {
+				uris = append(uris, notification.Params.AdditionalFields["uri"].(string))
+			}
+		default:
+			return uris
+		}
+	}
+}
+
+func TestMCPServer_ResourceSubscriptions(t *testing.T) {
+	t.Run("not supported without subscribe capability", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(false, false))
+		_, ctx := newSubscriptionTestSession(t, server, "session-1")
+
+		response := server.HandleMessage(ctx, subscriptionMessage(mcp.MethodResourcesSubscribe, "file:///a"))
+		errorResponse, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok, "expected error response, got %T", response)
+		assert.Equal(t, mcp.METHOD_NOT_FOUND, errorResponse.Error.Code)
+	})
+
+	t.Run("requires a session", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(true, false))
+
+		response := server.HandleMessage(context.Background(), subscriptionMessage(mcp.MethodResourcesSubscribe, "file:///a"))
+		errorResponse, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok, "expected error response, got %T", response)
+		assert.Equal(t, mcp.INVALID_REQUEST, errorResponse.Error.Code)
+	})
+
+	t.Run("notifies only subscribed sessions", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(true, false))
+		server.AddResourceTemplate(
+			mcp.NewResourceTemplate("file:///logs/{name}", "logs"),
+			func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return nil, nil
+			},
+		)
+
+		session1, ctx1 := newSubscriptionTestSession(t, server, "session-1")
+		session2, ctx2 := newSubscriptionTestSession(t, server, "session-2")
+		session3, _ := newSubscriptionTestSession(t, server, "session-3")
+
+		response := server.HandleMessage(ctx1, subscriptionMessage(mcp.MethodResourcesSubscribe, "file:///config"))
+		require.IsType(t, mcp.JSONRPCResponse{}, response)
+		response = server.HandleMessage(ctx2, subscriptionMessage(mcp.MethodResourcesSubscribe, "file:///logs/{name}"))
+		require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+		require.NoError(t, server.NotifyResourceUpdated("file:///config"))
+		require.NoError(t, server.NotifyResourceUpdated("file:///logs/app"))
+		require.NoError(t, server.NotifyResourceUpdated("file:///other"))
+
+		assert.Equal(t, []string{"file:///config"}, receivedUpdates(session1))
+		assert.Equal(t, []string{"file:///logs/app"}, receivedUpdates(session2))
+		assert.Empty(t, receivedUpdates(session3))
+
+		// unsubscribe stops the notifications
+		response = server.HandleMessage(ctx1, subscriptionMessage(mcp.MethodResourcesUnsubscribe, "file:///config"))
+		require.IsType(t, mcp.JSONRPCResponse{}, response)
+		require.NoError(t, server.NotifyResourceUpdated("file:///config"))
+		assert.Empty(t, receivedUpdates(session1))
+	})
+
+	t.Run("subscriptions are removed when the session is unregistered", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(true, false))
+		_, ctx := newSubscriptionTestSession(t, server, "session-1")
+
+		server.HandleMessage(ctx, subscriptionMessage(mcp.MethodResourcesSubscribe, "file:///a"))
+		assert.Len(t, server.subscriptions.sessions, 1)
+
+		server.UnregisterSession(context.Background(), "session-1")
+		assert.Empty(t, server.subscriptions.sessions)
+		assert.NoError(t, server.NotifyResourceUpdated("file:///a"))
+	})
+}
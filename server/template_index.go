@@ -0,0 +1,178 @@
+package server
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// templateIndex is a precompiled index of resource templates, used to find the
+// template matching a resource URI without trying every template.
+//
+// Templates are indexed by their literal prefix (the text before their first
+// expression) in a trie, so only the templates whose prefix the URI starts with
+// are tried. When several templates match a URI, the one with the highest
+// precedence wins:
+//
+//  1. the template with the highest priority;
+//  2. reading the templates from left to right, the first template with literal
+//     text where the other has an expression, e.g. users://me/{field} before
+//     users://{id}/profile, or a simple expression where the other has one
+//     that can match several path segments ({+path}, {#fragment} and exploded
+//     variables);
+//  3. the template with the most literal characters;
+//  4. the template with the fewest variables;
+//  5. the template whose URI template sorts first, so that the match is always
+//     deterministic.
+type templateIndex struct {
+	root templateIndexNode
+}
+
+type templateIndexNode struct {
+	children map[byte]*templateIndexNode
+	// templates whose literal prefix ends at this node
+	templates []indexedTemplate
+}
+
+type indexedTemplate struct {
+	rank  int // position of the template in precedence order
+	entry ServerResourceTemplate
+}
+
+// Kinds of the parts of a URI template, from the most to the least specific
+const (
+	templatePartLiteral    byte = iota // a literal character
+	templatePartExpression             // an expression matching a single segment
+	templatePartWide                   // an expression that can match several segments
+)
+
+// templateSpecificity describes how specific a URI template is
+type templateSpecificity struct {
+	literalPrefix string
+	// kind of each literal character and expression of the template, in order
+	parts     []byte
+	literals  int
+	variables int
+}
+
+// compare returns a negative number if s is more specific than other, a positive
+// number if it is less specific, and 0 if they are equally specific
+func (s templateSpecificity) compare(other templateSpecificity) int {
+	n := min(len(s.parts), len(other.parts))
+	if c := bytes.Compare(s.parts[:n], other.parts[:n]); c != 0 {
+		return c
+	}
+	if s.literals != other.literals {
+		return other.literals - s.literals
+	}
+	return s.variables - other.variables
+}
+
+// newTemplateIndex builds the index of the given templates
+func newTemplateIndex(templates []ServerResourceTemplate) *templateIndex {
+	specificities := make([]templateSpecificity, len(templates))
+	order := make([]int, len(templates))
+	for i, template := range templates {
+		specificities[i] = uriTemplateSpecificity(template.Template.URITemplate.Raw())
+		order[i] = i
+		// compile the regexp of the template now rather than on the first read
+		template.Template.URITemplate.Regexp()
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := templates[order[i]], templates[order[j]]
+		sa, sb := specificities[order[i]], specificities[order[j]]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if c := sa.compare(sb); c != 0 {
+			return c < 0
+		}
+		return a.Template.URITemplate.Raw() < b.Template.URITemplate.Raw()
+	})
+
+	index := &templateIndex{}
+	for rank, i := range order {
+		node := &index.root
+		prefix := specificities[i].literalPrefix
+		for j := 0; j < len(prefix); j++ {
+			if node.children == nil {
+				node.children = make(map[byte]*templateIndexNode)
+			}
+			child, ok := node.children[prefix[j]]
+			if !ok {
+				child = &templateIndexNode{}
+				node.children[prefix[j]] = child
+			}
+			node = child
+		}
+		node.templates = append(node.templates, indexedTemplate{rank: rank, entry: templates[i]})
+	}
+	return index
+}
+
+// match returns the template with the highest precedence that matches the URI
+func (idx *templateIndex) match(uri string) (ServerResourceTemplate, bool) {
+	var candidates []indexedTemplate
+	node := &idx.root
+	for i := 0; node != nil; i++ {
+		candidates = append(candidates, node.templates...)
+		if i == len(uri) {
+			break
+		}
+		node = node.children[uri[i]]
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].rank < candidates[j].rank
+	})
+	for _, candidate := range candidates {
+		if matchesTemplate(uri, candidate.entry.Template.URITemplate) {
+			return candidate.entry, true
+		}
+	}
+	return ServerResourceTemplate{}, false
+}
+
+// uriTemplateSpecificity computes the specificity of a raw URI template (RFC 6570)
+func uriTemplateSpecificity(raw string) templateSpecificity {
+	var specificity templateSpecificity
+	prefixDone := false
+	for len(raw) > 0 {
+		start := strings.IndexByte(raw, '{')
+		if start < 0 {
+			start = len(raw)
+		}
+		specificity.literals += start
+		for range start {
+			specificity.parts = append(specificity.parts, templatePartLiteral)
+		}
+		if !prefixDone {
+			specificity.literalPrefix += raw[:start]
+		}
+		raw = raw[start:]
+		if raw == "" {
+			break
+		}
+		prefixDone = true
+
+		end := strings.IndexByte(raw, '}')
+		if end < 0 {
+			break
+		}
+		expression := raw[1:end]
+		raw = raw[end+1:]
+
+		part := templatePartExpression
+		if strings.HasPrefix(expression, "+") || strings.HasPrefix(expression, "#") {
+			part = templatePartWide
+		}
+		expression = strings.TrimLeft(expression, "+#./;?&")
+		for _, variable := range strings.Split(expression, ",") {
+			specificity.variables++
+			if strings.HasSuffix(variable, "*") {
+				part = templatePartWide
+			}
+		}
+		specificity.parts = append(specificity.parts, part)
+	}
+	return specificity
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestURITemplateSpecificity(t *testing.T) {
+	tests := []struct {
+		template  string
+		prefix    string
+		parts     string // l for literal characters, e for expressions, w for wide expressions
+		variables int
+	}{
+		{template: "users://me/profile", prefix: "users://me/profile", parts: "llllllllllllllllll"},
+		{template: "users://{id}/profile", prefix: "users://", parts: "llllllllellllllll", variables: 1},
+		{template: "files://{+path}", prefix: "files://", parts: "llllllllw", variables: 1},
+		{template: "search://items{?q,limit}", prefix: "search://items", parts: "lllllllllllllle", variables: 2},
+		{template: "tree://{/segments*}", prefix: "tree://", parts: "lllllllw", variables: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			specificity := uriTemplateSpecificity(tt.template)
+			assert.Equal(t, tt.prefix, specificity.literalPrefix)
+			assert.Equal(t, strings.Count(tt.parts, "l"), specificity.literals)
+			assert.Equal(t, tt.variables, specificity.variables)
+			parts := strings.NewReplacer("l", "\x00", "e", "\x01", "w", "\x02").Replace(tt.parts)
+			assert.Equal(t, []byte(parts), specificity.parts)
+		})
+	}
+}
+
+func TestTemplateIndex_Match(t *testing.T) {
+	template := func(uriTemplate string, priority int) ServerResourceTemplate {
+		return ServerResourceTemplate{
+			Template: mcp.NewResourceTemplate(uriTemplate, uriTemplate),
+			Priority: priority,
+		}
+	}
+	tests := []struct {
+		name      string
+		templates []ServerResourceTemplate
+		uri       string
+		expected  string
+	}{
+		{
+			name:      "literal text beats variables",
+			templates: []ServerResourceTemplate{template("users://{id}/{field}", 0), template("users://me/{field}", 0)},
+			uri:       "users://me/name",
+			expected:  "users://me/{field}",
+		},
+		{
+			name:      "the leftmost literal text wins",
+			templates: []ServerResourceTemplate{template("users://{id}/profile", 0), template("users://me/{field}", 0)},
+			uri:       "users://me/profile",
+			expected:  "users://me/{field}",
+		},
+		{
+			name:      "simple variables beat reserved expansion",
+			templates: []ServerResourceTemplate{template("files://{+path}", 0), template("files://{name}", 0)},
+			uri:       "files://readme",
+			expected:  "files://{name}",
+		},
+		{
+			name:      "reserved expansion matches several segments",
+			templates: []ServerResourceTemplate{template("files://{+path}", 0), template("files://{name}", 0)},
+			uri:       "files://docs/readme",
+			expected:  "files://{+path}",
+		},
+		{
+			name:      "priority overrides specificity",
+			templates: []ServerResourceTemplate{template("users://{id}/{field}", 1), template("users://me/{field}", 0)},
+			uri:       "users://me/name",
+			expected:  "users://{id}/{field}",
+		},
+		{
+			name:      "ties are broken by the URI template",
+			templates: []ServerResourceTemplate{template("items://{b}", 0), template("items://{a}", 0)},
+			uri:       "items://x",
+			expected:  "items://{a}",
+		},
+		{
+			name:      "templates with unrelated prefixes",
+			templates: []ServerResourceTemplate{template("users://{id}", 0), template("files://{name}", 0)},
+			uri:       "files://readme",
+			expected:  "files://{name}",
+		},
+		{
+			name:      "no match",
+			templates: []ServerResourceTemplate{template("users://{id}", 0)},
+			uri:       "files://readme",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the result must not depend on the order of the templates
+			for range 10 {
+				template, ok := newTemplateIndex(tt.templates).match(tt.uri)
+				if tt.expected == "" {
+					assert.False(t, ok)
+					continue
+				}
+				require.True(t, ok)
+				assert.Equal(t, tt.expected, template.Template.URITemplate.Raw())
+				tt.templates[0], tt.templates[len(tt.templates)-1] = tt.templates[len(tt.templates)-1], tt.templates[0]
+			}
+		})
+	}
+}
+
+func TestMCPServer_ReadResourceTemplatePrecedence(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	handler := func(name string) ResourceTemplateHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: name}}, nil
+		}
+	}
+	for i := range 1000 {
+		server.AddResourceTemplate(mcp.NewResourceTemplate(fmt.Sprintf("tenant%d://{id}/{field}", i), "tenant"), handler("tenant"))
+	}
+	server.AddResourceTemplate(mcp.NewResourceTemplate("users://{id}/{field}", "user"), handler("user"))
+	server.AddResourceTemplate(mcp.NewResourceTemplate("users://me/{field}", "me"), handler("me"))
+
+	read := func(uri string) string {
+		response := server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": "`+uri+`"}}`,
+		))
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.ReadResourceResult)
+		return result.Contents[0].(mcp.TextResourceContents).Text
+	}
+	for range 10 {
+		assert.Equal(t, "me", read("users://me/name"))
+		assert.Equal(t, "user", read("users://42/name"))
+		assert.Equal(t, "tenant", read("tenant999://42/name"))
+	}
+
+	// the index is rebuilt when templates are added
+	server.AddResourceTemplates(ServerResourceTemplate{
+		Template: mcp.NewResourceTemplate("users://{id}/name", "name"),
+		Handler:  handler("name"),
+		Priority: 1,
+	})
+	assert.Equal(t, "name", read("users://me/name"))
+	assert.Equal(t, "me", read("users://me/email"))
+}
+
+func BenchmarkTemplateIndex_Match(b *testing.B) {
+	templates := make([]ServerResourceTemplate, 0, 10000)
+	for i := range 10000 {
+		templates = append(templates, ServerResourceTemplate{
+			Template: mcp.NewResourceTemplate(fmt.Sprintf("tenant%d://{id}/{field}", i), "tenant"),
+		})
+	}
+	index := newTemplateIndex(templates)
+	b.ResetTimer()
+	for range b.N {
+		if _, ok := index.match("tenant9999://42/name"); !ok {
+			b.Fatal("expected a match")
+		}
+	}
+}
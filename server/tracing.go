@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/tracing"
+)
+
+// Attributes set on the spans started by the server
+const (
+	spanAttributeMethod      = "mcp.method.name"
+	spanAttributeRequestID   = "jsonrpc.request.id"
+	spanAttributeErrorCode   = "rpc.jsonrpc.error_code"
+	spanAttributeSessionID   = "mcp.session.id"
+	spanAttributeToolName    = "mcp.tool.name"
+	spanAttributeToolIsError = "mcp.tool.is_error"
+)
+
+// WithTracer sets the tracer starting spans around the dispatch of every request
+// and notification received from clients, the handling of tool calls and the
+// notifications sent to clients.
+//
+// Whether or not a tracer is set, the W3C trace context sent by clients in the
+// _meta of messages (traceparent and tracestate) is extracted into the context of
+// handlers, see tracing.SpanContextFromContext, and the trace context of the
+// handlers is propagated in the _meta of the notifications they send.
+func WithTracer(tracer tracing.Tracer) ServerOption {
+	return func(s *MCPServer) {
+		s.tracer = tracer
+	}
+}
+
+// startSpan starts a span with the tracer of the server, if any
+func (s *MCPServer) startSpan(ctx context.Context, name string, kind tracing.SpanKind) (context.Context, tracing.Span) {
+	if s.tracer == nil {
+		return tracing.NoopTracer().Start(ctx, name, kind)
+	}
+	ctx, span := s.tracer.Start(ctx, name, kind)
+	if session := ClientSessionFromContext(ctx); session != nil {
+		span.SetAttribute(spanAttributeSessionID, session.SessionID())
+	}
+	return ctx, span
+}
+
+// extractTraceContext returns a copy of ctx holding the trace context sent by the
+// client in the _meta of a message, if any
+func extractTraceContext(ctx context.Context, message json.RawMessage) context.Context {
+	if !bytes.Contains(message, []byte(tracing.TraceparentKey)) {
+		return ctx
+	}
+	var baseMessage struct {
+		Params struct {
+			Meta map[string]any `json:"_meta"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		return ctx
+	}
+	return tracing.Extract(ctx, baseMessage.Params.Meta)
+}
+
+// startRequestSpan starts the span of the dispatch of a request
+func (s *MCPServer) startRequestSpan(ctx context.Context, id any, method mcp.MCPMethod) (context.Context, tracing.Span) {
+	ctx, span := s.startSpan(ctx, string(method), tracing.SpanKindServer)
+	span.SetAttribute(spanAttributeMethod, string(method))
+	span.SetAttribute(spanAttributeRequestID, fmt.Sprint(id))
+	return ctx, span
+}
+
+// endRequestSpan ends the span of the dispatch of a request, recording the error
+// the request was answered with, if any
+func endRequestSpan(span tracing.Span, response mcp.JSONRPCMessage) {
+	if response, ok := response.(mcp.JSONRPCError); ok {
+		span.SetAttribute(spanAttributeErrorCode, response.Error.Code)
+		span.RecordError(fmt.Errorf("%s", response.Error.Message))
+	}
+	span.End()
+}
+
+// newNotification builds a notification sent to clients and starts its span. The
+// trace context of the span is propagated in the _meta of the notification.
+func (s *MCPServer) newNotification(
+	ctx context.Context,
+	method string,
+	params map[string]any,
+) (mcp.JSONRPCNotification, tracing.Span) {
+	ctx, span := s.startSpan(ctx, method, tracing.SpanKindProducer)
+	span.SetAttribute(spanAttributeMethod, method)
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: method,
+			Params: mcp.NotificationParams{
+				AdditionalFields: params,
+			},
+		},
+	}
+	if span.SpanContext().IsValid() {
+		notification.Params.Meta = make(map[string]any, 2)
+		tracing.Inject(ctx, notification.Params.Meta)
+	}
+	return notification, span
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/tracing"
+)
+
+func TestMCPServer_Tracing(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	exporter := tracing.NewInMemoryExporter()
+	server := NewMCPServer("test-server", "1.0.0",
+		WithLogging(),
+		WithTracer(tracing.NewTracer(exporter)),
+	)
+	server.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := ClientLoggerFromContext(ctx).Info("working"); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	server.AddTool(mcp.NewTool("fail"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+
+	t.Run("requests continue the trace of the client", func(t *testing.T) {
+		exporter.Reset()
+		ctx, notifications := newLoggingTestContext(server, mcp.LoggingLevelInfo)
+
+		response := server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {
+			"name": "work",
+			"_meta": {"traceparent": "`+traceparent+`", "tracestate": "vendor=value"}
+		}}`))
+		require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+		dispatch := exporter.SpansByName("tools/call")
+		require.Len(t, dispatch, 1)
+		assert.Equal(t, tracing.SpanKindServer, dispatch[0].Kind)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", dispatch[0].SpanContext.TraceID.String())
+		assert.Equal(t, "00f067aa0ba902b7", dispatch[0].Parent.SpanID.String())
+		assert.Equal(t, "vendor=value", dispatch[0].SpanContext.TraceState)
+		assert.Equal(t, map[string]any{
+			"mcp.method.name":    "tools/call",
+			"jsonrpc.request.id": "1",
+			"mcp.session.id":     "session-1",
+		}, dispatch[0].Attributes)
+
+		tool := exporter.SpansByName("execute_tool work")
+		require.Len(t, tool, 1)
+		assert.Equal(t, dispatch[0].SpanContext, tool[0].Parent)
+		assert.Equal(t, "work", tool[0].Attributes["mcp.tool.name"])
+		assert.Equal(t, false, tool[0].Attributes["mcp.tool.is_error"])
+
+		notification := exporter.SpansByName(string(mcp.MethodNotificationMessage))
+		require.Len(t, notification, 1)
+		assert.Equal(t, tracing.SpanKindProducer, notification[0].Kind)
+		assert.Equal(t, tool[0].SpanContext, notification[0].Parent)
+
+		// the trace context of the notification is propagated to the client
+		sent := <-notifications
+		assert.Equal(t, map[string]any{
+			"traceparent": notification[0].SpanContext.Traceparent(),
+			"tracestate":  "vendor=value",
+		}, sent.Params.Meta)
+	})
+
+	t.Run("errors are recorded", func(t *testing.T) {
+		exporter.Reset()
+		ctx, _ := newLoggingTestContext(server, mcp.LoggingLevelInfo)
+
+		response := server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "fail"}}`))
+		require.IsType(t, mcp.JSONRPCError{}, response)
+
+		dispatch := exporter.SpansByName("tools/call")
+		require.Len(t, dispatch, 1)
+		assert.False(t, dispatch[0].Parent.IsValid())
+		assert.Equal(t, mcp.INTERNAL_ERROR, dispatch[0].Attributes["rpc.jsonrpc.error_code"])
+		assert.Len(t, dispatch[0].Errors, 1)
+
+		tool := exporter.SpansByName("execute_tool fail")
+		require.Len(t, tool, 1)
+		assert.EqualError(t, errors.Join(tool[0].Errors...), "boom")
+	})
+
+	t.Run("notifications from the client are traced", func(t *testing.T) {
+		exporter.Reset()
+		ctx, _ := newLoggingTestContext(server, mcp.LoggingLevelInfo)
+
+		server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "method": "notifications/roots/list_changed", "params": {
+			"_meta": {"traceparent": "`+traceparent+`"}
+		}}`))
+
+		spans := exporter.SpansByName(string(mcp.MethodNotificationRootsListChanged))
+		require.Len(t, spans, 1)
+		assert.Equal(t, tracing.SpanKindConsumer, spans[0].Kind)
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID.String())
+	})
+}
+
+func TestMCPServer_TraceContextWithoutTracer(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	var sc tracing.SpanContext
+	server.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sc = tracing.SpanContextFromContext(ctx)
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {
+		"name": "work",
+		"_meta": {"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	}}`))
+
+	assert.True(t, sc.Remote)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+}
@@ -0,0 +1,176 @@
+package tracing
+
+import (
+	"context"
+	"maps"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// SpanData is a snapshot of an ended span
+type SpanData struct {
+	Name        string
+	Kind        SpanKind
+	SpanContext SpanContext
+	// Parent is the span context of the parent of the span, which is not valid
+	// for the root span of a trace
+	Parent     SpanContext
+	Attributes map[string]any
+	Errors     []error
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// SpanExporter receives the spans ended by a tracer created by NewTracer
+type SpanExporter interface {
+	ExportSpan(span SpanData)
+}
+
+// NewTracer returns a tracer sending the spans it starts to the exporter once
+// they end. Root spans are sampled, and child spans follow the sampling decision
+// of their parent: spans that aren't sampled are propagated but not exported.
+func NewTracer(exporter SpanExporter) Tracer {
+	return &tracer{exporter: exporter}
+}
+
+type tracer struct {
+	exporter SpanExporter
+}
+
+func (t *tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{TraceFlags: FlagsSampled}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.TraceFlags = parent.TraceFlags
+		sc.TraceState = parent.TraceState
+	} else {
+		for !sc.TraceID.IsValid() {
+			putRandom(sc.TraceID[:])
+		}
+	}
+	for !sc.SpanID.IsValid() {
+		putRandom(sc.SpanID[:])
+	}
+
+	s := &span{
+		tracer: t,
+		data: SpanData{
+			Name:        name,
+			Kind:        kind,
+			SpanContext: sc,
+			Parent:      parent,
+			Attributes:  make(map[string]any),
+			StartTime:   time.Now(),
+		},
+	}
+	return ContextWithSpan(ctx, s), s
+}
+
+// putRandom fills b with random bytes
+func putRandom(b []byte) {
+	for i := range b {
+		b[i] = byte(rand.Uint32())
+	}
+}
+
+type span struct {
+	tracer *tracer
+	mu     sync.Mutex
+	ended  bool
+	data   SpanData
+}
+
+func (s *span) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+func (s *span) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Attributes[key] = value
+	}
+}
+
+func (s *span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Errors = append(s.data.Errors, err)
+	}
+}
+
+func (s *span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.SpanContext.IsSampled() && s.tracer.exporter != nil {
+		s.tracer.exporter.ExportSpan(data)
+	}
+}
+
+// InMemoryExporter keeps the spans exported to it in memory, so that they can
+// be asserted in tests:
+//
+//	exporter := tracing.NewInMemoryExporter()
+//	s := server.NewMCPServer("test", "1.0.0", server.WithTracer(tracing.NewTracer(exporter)))
+//	...
+//	spans := exporter.Spans()
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+var _ SpanExporter = (*InMemoryExporter)(nil)
+
+// NewInMemoryExporter creates an empty in-memory exporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan keeps the span
+func (e *InMemoryExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	span.Attributes = maps.Clone(span.Attributes)
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the exported spans, in the order they ended
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	spans := make([]SpanData, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// SpansByName returns the exported spans with the given name, in the order they ended
+func (e *InMemoryExporter) SpansByName(name string) []SpanData {
+	var spans []SpanData
+	for _, span := range e.Spans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// Reset drops the exported spans
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// TraceparentKey is the _meta key holding the W3C traceparent
+	TraceparentKey = "traceparent"
+	// TracestateKey is the _meta key holding the W3C tracestate
+	TracestateKey = "tracestate"
+)
+
+// maxTraceStateLength is the length above which the trace state is dropped, as
+// allowed by the W3C Trace Context
+const maxTraceStateLength = 512
+
+// ErrInvalidTraceparent is returned when parsing a malformed traceparent
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// ParseTraceparent parses a W3C traceparent, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceparent(traceparent string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 {
+		return sc, fmt.Errorf("%w: %q", ErrInvalidTraceparent, traceparent)
+	}
+	version, err := decodeHex(parts[0], 1)
+	// version 00 has exactly four fields, later versions may add more
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return sc, fmt.Errorf("%w: unsupported version in %q", ErrInvalidTraceparent, traceparent)
+	}
+	traceID, err := decodeHex(parts[1], len(sc.TraceID))
+	if err != nil {
+		return sc, fmt.Errorf("%w: bad trace ID in %q", ErrInvalidTraceparent, traceparent)
+	}
+	spanID, err := decodeHex(parts[2], len(sc.SpanID))
+	if err != nil {
+		return sc, fmt.Errorf("%w: bad parent ID in %q", ErrInvalidTraceparent, traceparent)
+	}
+	flags, err := decodeHex(parts[3], 1)
+	if err != nil {
+		return sc, fmt.Errorf("%w: bad flags in %q", ErrInvalidTraceparent, traceparent)
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.TraceFlags = TraceFlags(flags[0])
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("%w: all zero ID in %q", ErrInvalidTraceparent, traceparent)
+	}
+	return sc, nil
+}
+
+// decodeHex decodes lowercase hex holding exactly n bytes
+func decodeHex(s string, n int) ([]byte, error) {
+	if len(s) != 2*n || strings.ToLower(s) != s {
+		return nil, errors.New("bad length or case")
+	}
+	return hex.DecodeString(s)
+}
+
+// Traceparent formats the span context as a version 00 W3C traceparent, or
+// returns an empty string if it isn't valid
+func (sc SpanContext) Traceparent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, byte(sc.TraceFlags))
+}
+
+// Inject adds the traceparent and tracestate of the span context in ctx to the
+// _meta of a message. Nothing is added if ctx has no valid span context.
+func Inject(ctx context.Context, meta map[string]any) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	meta[TraceparentKey] = sc.Traceparent()
+	if sc.TraceState != "" {
+		meta[TracestateKey] = sc.TraceState
+	}
+}
+
+// Extract returns a copy of ctx holding the span context propagated in the _meta
+// of a message, as a remote span context. ctx is returned as is if _meta has no
+// valid traceparent.
+func Extract(ctx context.Context, meta map[string]any) context.Context {
+	traceparent, _ := meta[TraceparentKey].(string)
+	if traceparent == "" {
+		return ctx
+	}
+	sc, err := ParseTraceparent(traceparent)
+	if err != nil {
+		return ctx
+	}
+	if traceState, ok := meta[TracestateKey].(string); ok && len(traceState) <= maxTraceStateLength {
+		sc.TraceState = strings.TrimSpace(traceState)
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
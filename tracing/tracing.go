@@ -0,0 +1,179 @@
+// Package tracing provides a vendor-neutral tracing abstraction for MCP servers
+// and clients, with the propagation of the W3C Trace Context
+// (https://www.w3.org/TR/trace-context/) through the _meta of messages.
+//
+// Servers and clients start spans through a Tracer, which can be implemented
+// on top of any tracing library, e.g. OpenTelemetry. NewTracer returns a
+// minimal Tracer sending ended spans to a SpanExporter, such as the
+// InMemoryExporter used to assert spans in tests.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// IsValid reports whether the trace ID is not all zeros
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// String returns the trace ID as 32 lowercase hex characters
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// IsValid reports whether the span ID is not all zeros
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// String returns the span ID as 16 lowercase hex characters
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// TraceFlags are the flags of the W3C Trace Context
+type TraceFlags byte
+
+// FlagsSampled is set when the caller may have recorded the trace
+const FlagsSampled TraceFlags = 0x01
+
+// IsSampled reports whether the sampled flag is set
+func (f TraceFlags) IsSampled() bool {
+	return f&FlagsSampled == FlagsSampled
+}
+
+// SpanContext is the part of a span propagated to other processes: the IDs of
+// the span and its trace, the trace flags and the vendor-specific trace state.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	TraceFlags TraceFlags
+	// TraceState is the value of the tracestate header, kept as is
+	TraceState string
+	// Remote is set when the span context was propagated from another process
+	Remote bool
+}
+
+// IsValid reports whether both the trace and span IDs are valid
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled reports whether the sampled flag is set
+func (sc SpanContext) IsSampled() bool {
+	return sc.TraceFlags.IsSampled()
+}
+
+// SpanKind describes the relationship of a span with its parent and children
+type SpanKind int
+
+const (
+	// SpanKindInternal is an operation internal to a process
+	SpanKindInternal SpanKind = iota
+	// SpanKindServer is the handling of a request received from a remote caller
+	SpanKindServer
+	// SpanKindClient is a request sent to a remote server
+	SpanKindClient
+	// SpanKindProducer is the sending of a message not awaiting a response
+	SpanKindProducer
+	// SpanKindConsumer is the handling of a message not awaiting a response
+	SpanKindConsumer
+)
+
+// String returns the name of the span kind
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	case SpanKindProducer:
+		return "producer"
+	case SpanKindConsumer:
+		return "consumer"
+	default:
+		return "internal"
+	}
+}
+
+// Span is an operation being traced
+type Span interface {
+	// SpanContext returns the span context of the span, to propagate it
+	SpanContext() SpanContext
+	// SetAttribute sets an attribute describing the operation
+	SetAttribute(key string, value any)
+	// RecordError records that the operation failed with the error
+	RecordError(err error)
+	// End ends the span. Calls made after the first one are ignored.
+	End()
+}
+
+// Tracer starts spans. The parent of a span is the span context found in ctx
+// by SpanContextFromContext, and the context returned by Start holds the new
+// span, so that it is the parent of the spans started with it.
+type Tracer interface {
+	Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span)
+}
+
+type spanKey struct{}
+
+type remoteSpanContextKey struct{}
+
+// ContextWithSpan returns a copy of ctx holding the span, for Tracer implementations
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span held by ctx, or a span doing nothing if there
+// is none. The span context of the latter is the remote span context in ctx, if any.
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	sc, _ := ctx.Value(remoteSpanContextKey{}).(SpanContext)
+	return noopSpan{sc: sc}
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx holding a span context
+// propagated from another process, as the parent of the spans started with it.
+// It replaces the span held by ctx, if any.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	ctx = context.WithValue(ctx, spanKey{}, nil)
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the span held by ctx, or
+// the remote span context held by ctx if there is no span.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	return SpanFromContext(ctx).SpanContext()
+}
+
+// NoopTracer returns a tracer starting spans doing nothing. The spans have the
+// span context of their parent, so that it's still propagated.
+func NoopTracer() Tracer {
+	return noopTracer{}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	return ctx, noopSpan{sc: SpanContextFromContext(ctx)}
+}
+
+type noopSpan struct {
+	sc SpanContext
+}
+
+func (s noopSpan) SpanContext() SpanContext { return s.sc }
+func (noopSpan) SetAttribute(string, any)   {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.IsSampled())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	// later versions may add fields
+	sc, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	require.NoError(t, err)
+	assert.False(t, sc.IsSampled())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", sc.Traceparent())
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+	}
+	for _, traceparent := range invalid {
+		_, err := ParseTraceparent(traceparent)
+		assert.ErrorIs(t, err, ErrInvalidTraceparent, traceparent)
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	meta := map[string]any{}
+	Inject(context.Background(), meta)
+	assert.Empty(t, meta, "nothing is injected without a span context")
+
+	ctx := Extract(context.Background(), map[string]any{
+		TraceparentKey: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		TracestateKey:  "vendor=value",
+	})
+	sc := SpanContextFromContext(ctx)
+	assert.True(t, sc.IsValid())
+	assert.True(t, sc.Remote)
+	assert.Equal(t, "vendor=value", sc.TraceState)
+
+	Inject(ctx, meta)
+	assert.Equal(t, map[string]any{
+		TraceparentKey: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		TracestateKey:  "vendor=value",
+	}, meta)
+
+	ctx = Extract(context.Background(), map[string]any{TraceparentKey: "garbage"})
+	assert.False(t, SpanContextFromContext(ctx).IsValid())
+}
+
+func TestTracer(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	ctx, root := tracer.Start(context.Background(), "root", SpanKindServer)
+	_, child := tracer.Start(ctx, "child", SpanKindInternal)
+	child.SetAttribute("key", "value")
+	child.RecordError(errors.New("boom"))
+	child.End()
+	child.End()
+	root.End()
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, root.SpanContext(), spans[0].Parent)
+	assert.Equal(t, root.SpanContext().TraceID, spans[0].SpanContext.TraceID)
+	assert.Equal(t, map[string]any{"key": "value"}, spans[0].Attributes)
+	assert.EqualError(t, errors.Join(spans[0].Errors...), "boom")
+	assert.Equal(t, "root", spans[1].Name)
+	assert.False(t, spans[1].Parent.IsValid())
+	assert.Equal(t, SpanKindServer, spans[1].Kind)
+
+	t.Run("remote parent", func(t *testing.T) {
+		exporter.Reset()
+		ctx := Extract(ctx, map[string]any{TraceparentKey: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+		_, span := tracer.Start(ctx, "handler", SpanKindServer)
+		span.End()
+
+		spans := exporter.SpansByName("handler")
+		require.Len(t, spans, 1)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID.String())
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID.String())
+		assert.True(t, spans[0].Parent.Remote)
+	})
+
+	t.Run("spans of unsampled traces are not exported", func(t *testing.T) {
+		exporter.Reset()
+		ctx := Extract(context.Background(), map[string]any{TraceparentKey: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"})
+		_, span := tracer.Start(ctx, "handler", SpanKindServer)
+		span.End()
+
+		assert.True(t, span.SpanContext().IsValid())
+		assert.Empty(t, exporter.Spans())
+	})
+}
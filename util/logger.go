@@ -1,33 +1,83 @@
 package util
 
 import (
+	"context"
+	"io"
 	"log"
+	"log/slog"
 )
 
-// Logger defines a minimal logging interface
+// Logger is the structured, leveled logging interface used by the servers and
+// client transports to report their diagnostics. Messages come with fields given
+// as alternating keys and values, like with log/slog: *slog.Logger implements
+// Logger, so the logs of the library can be routed to any slog.Handler.
 type Logger interface {
-	Infof(format string, v ...any)
-	Errorf(format string, v ...any)
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
 }
 
-// --- Standard Library Logger Wrapper ---
+var _ Logger = (*slog.Logger)(nil)
 
-// DefaultStdLogger implements Logger using the standard library's log.Logger.
+// DefaultLogger returns a logger writing to the default slog logger (see
+// slog.SetDefault) at the time messages are logged.
 func DefaultLogger() Logger {
-	return &stdLogger{
-		logger: log.Default(),
-	}
+	return defaultLogger{}
 }
 
-// stdLogger wraps the standard library's log.Logger.
-type stdLogger struct {
-	logger *log.Logger
+// NewLogger returns a logger sending its messages to the handler
+func NewLogger(handler slog.Handler) Logger {
+	return slog.New(handler)
+}
+
+// DiscardLogger returns a logger dropping every message
+func DiscardLogger() Logger {
+	return slog.New(discardHandler{})
 }
 
-func (l *stdLogger) Infof(format string, v ...any) {
-	l.logger.Printf("INFO: "+format, v...)
+// NewStdLogger returns a logger writing to a standard library logger, one line
+// per message in the logfmt style, e.g.
+// level=ERROR msg="failed to write response" err="broken pipe"
+func NewStdLogger(logger *log.Logger) Logger {
+	return slog.New(slog.NewTextHandler(stdLoggerWriter{logger}, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			// the standard library logger adds the time itself
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
 }
 
-func (l *stdLogger) Errorf(format string, v ...any) {
-	l.logger.Printf("ERROR: "+format, v...)
+// defaultLogger writes to slog.Default()
+type defaultLogger struct{}
+
+func (defaultLogger) Debug(msg string, args ...any) { slog.Default().Debug(msg, args...) }
+func (defaultLogger) Info(msg string, args ...any)  { slog.Default().Info(msg, args...) }
+func (defaultLogger) Warn(msg string, args ...any)  { slog.Default().Warn(msg, args...) }
+func (defaultLogger) Error(msg string, args ...any) { slog.Default().Error(msg, args...) }
+
+// discardHandler is a slog.Handler dropping every record
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// stdLoggerWriter writes each line written to it as a message of a standard library logger
+type stdLoggerWriter struct {
+	logger *log.Logger
+}
+
+var _ io.Writer = stdLoggerWriter{}
+
+func (w stdLoggerWriter) Write(p []byte) (int, error) {
+	if err := w.logger.Output(2, string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
 }
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "mcp: ", 0))
+
+	logger.Debug("starting")
+	logger.Error("failed to write response", "sessionID", "abc", "err", errors.New("broken pipe"))
+
+	assert.Equal(t,
+		"mcp: level=DEBUG msg=starting\n"+
+			"mcp: level=ERROR msg=\"failed to write response\" sessionID=abc err=\"broken pipe\"\n",
+		buf.String(),
+	)
+}
+
+func TestDefaultLogger(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	logger := DefaultLogger()
+	// the default slog logger is looked up when logging
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})))
+	logger.Warn("event queue full", "sessionID", "abc")
+	logger.Debug("dropped")
+
+	assert.Equal(t, "level=WARN msg=\"event queue full\" sessionID=abc\n", buf.String())
+}
+
+func TestDiscardLogger(t *testing.T) {
+	logger := DiscardLogger()
+	assert.NotPanics(t, func() {
+		logger.Error("dropped", "err", errors.New("boom"))
+	})
+}